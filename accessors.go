@@ -0,0 +1,448 @@
+package inrequest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// requestValues holds the parsed values of a request and gives every request type its path lookups.
+type requestValues struct {
+	result RequestValue
+	// redact holds the field paths hidden from the JSON methods and Dump, see WithRedactedKeys.
+	redact map[string]bool
+	// encoded memoizes the JSON encoding shared by ToBind and the JSON methods.
+	encoded *encodedJSON
+	// order holds the order the keys arrived in, encoded in that order, see WithPreserveOrder.
+	order *keyOrder
+}
+
+type encodedJSON struct {
+	mu       sync.Mutex
+	data     []byte
+	redacted []byte
+	// shared is set once ToMap handed the values out, they are encoded afresh from then on.
+	shared bool
+}
+
+// valuesOf wraps parsed values, redacting the keys of options, which may be nil, from the output.
+func valuesOf(result RequestValue, options *Options) requestValues {
+	values := requestValues{result: result, encoded: &encodedJSON{}}
+	if options != nil && len(options.RedactedKeys) > 0 {
+		values.redact = make(map[string]bool, len(options.RedactedKeys))
+		for _, key := range options.RedactedKeys {
+			values.redact[fieldPath(key)] = true
+		}
+	}
+	return values
+}
+
+// marshal encodes value, the values or a view of them, once per request. The bytes are shared, copy them before handing them out.
+func (v requestValues) marshal(value RequestValue) ([]byte, error) {
+	if v.encoded == nil {
+		return v.jsonOf(value)
+	}
+	v.encoded.mu.Lock()
+	defer v.encoded.mu.Unlock()
+	if v.encoded.shared {
+		return v.jsonOf(value)
+	}
+	if v.encoded.data == nil {
+		data, err := v.jsonOf(value)
+		if err != nil {
+			return nil, err
+		}
+		v.encoded.data = data
+	}
+	return v.encoded.data, nil
+}
+
+// encode is marshal for output, with the redacted keys replaced by "***".
+func (v requestValues) encode(value RequestValue) ([]byte, error) {
+	if len(v.redact) == 0 {
+		return v.marshal(value)
+	}
+	if v.encoded == nil {
+		return v.jsonOf(redactValue(value, "", v.redact))
+	}
+	v.encoded.mu.Lock()
+	defer v.encoded.mu.Unlock()
+	if v.encoded.shared {
+		return v.jsonOf(redactValue(value, "", v.redact))
+	}
+	if v.encoded.redacted == nil {
+		data, err := v.jsonOf(redactValue(value, "", v.redact))
+		if err != nil {
+			return nil, err
+		}
+		v.encoded.redacted = data
+	}
+	return v.encoded.redacted, nil
+}
+
+// jsonOf encodes value, keeping the order its keys arrived in when it was recorded.
+func (v requestValues) jsonOf(value interface{}) ([]byte, error) {
+	if v.order == nil {
+		return json.Marshal(value)
+	}
+	return json.Marshal(orderedValue(value, v.order))
+}
+
+// invalidate drops the memoized encoding and stops memoizing once the values are handed out by ToMap,
+// as callers may change them at any time.
+func (v requestValues) invalidate() {
+	if v.encoded == nil {
+		return
+	}
+	v.encoded.mu.Lock()
+	defer v.encoded.mu.Unlock()
+	v.encoded.data = nil
+	v.encoded.redacted = nil
+	v.encoded.shared = true
+}
+
+// redactedPaths lets Merge carry the redacted keys of both requests over.
+func (v requestValues) redactedPaths() map[string]bool {
+	return v.redact
+}
+
+/*
+Copying value with the values at the redacted field paths replaced by "***"
+e.g. {"user": {"password": "secret"}} redacting "user.password" gives {"user": {"password": "***"}},
+array indexes are ignored so "items.token" hides the token of every item
+*/
+func redactValue(value interface{}, path string, redact map[string]bool) interface{} {
+	if path != "" && redact[withoutIndexes(path)] {
+		return redactedValue
+	}
+	switch v := value.(type) {
+	case RequestValue:
+		result := make(RequestValue, len(v))
+		for key, item := range v {
+			result[key] = redactValue(item, joinPath(path, key), redact)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = redactValue(item, joinPath(path, strconv.Itoa(i)), redact)
+		}
+		return result
+	}
+	return value
+}
+
+// redactedValue stands in for redacted values.
+const redactedValue = "***"
+
+// Get returns the value at a dot or bracket path, e.g. "user.address.city" or "items[0].price".
+// Maps and slices are shared with the request like ToMap does.
+func (v requestValues) Get(path string) (interface{}, bool) {
+	value, ok := valueAt(v.result, path)
+	if mutable(value) {
+		v.invalidate()
+	}
+	return value, ok
+}
+
+// mutable reports whether callers can change value in place, a map or a slice of the parsed values.
+func mutable(value interface{}) bool {
+	switch value.(type) {
+	case RequestValue, []interface{}:
+		return true
+	}
+	return false
+}
+
+// ToMapCopy returns a deep copy of the parsed values, safe to modify without affecting the request.
+// Uploaded files are shared.
+func (v requestValues) ToMapCopy() RequestValue {
+	return deepCopy(v.result).(RequestValue)
+}
+
+// ToOrderedMap returns the parsed values in the order their keys arrived with WithPreserveOrder,
+// keys are sorted otherwise. Maps and slices are copies, changing them leaves the request alone.
+func (v requestValues) ToOrderedMap() *OrderedMap {
+	return orderedValue(v.result, v.order).(*OrderedMap)
+}
+
+// Has reports whether a value is present at path, null values included.
+func (v requestValues) Has(path string) bool {
+	_, ok := valueAt(v.result, path)
+	return ok
+}
+
+// IsEmpty reports whether the request holds no values.
+func (v requestValues) IsEmpty() bool {
+	return len(v.result) == 0
+}
+
+// Keys lists the top-level keys in sorted order.
+func (v requestValues) Keys() []string {
+	keys := make([]string, 0, len(v.result))
+	for key := range v.result {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Only returns a new map holding the values at paths, e.g. Only("name", "address.city")
+// gives {"name": ..., "address": {"city": ...}}. Missing paths are left out, nested values are shared with the request.
+func (v requestValues) Only(paths ...string) RequestValue {
+	v.invalidate()
+	result := make(RequestValue)
+	for _, path := range paths {
+		if value, ok := valueAt(v.result, path); ok {
+			setPath(result, strings.Split(replaceBracketKeyIntoDotKey(path), "."), value)
+		}
+	}
+	return result
+}
+
+// Except returns a new map holding every value but those at paths, e.g. Except("password", "user.token").
+// Nested values are shared with the request.
+func (v requestValues) Except(paths ...string) RequestValue {
+	v.invalidate()
+	result := withoutPath(v.result, nil)
+	for _, path := range paths {
+		result = withoutPath(result, strings.Split(replaceBracketKeyIntoDotKey(path), "."))
+	}
+	return result
+}
+
+// Filter returns a new map holding the top-level values keep accepts, shared with the request.
+func (v requestValues) Filter(keep func(key string, value interface{}) bool) RequestValue {
+	v.invalidate()
+	result := make(RequestValue)
+	for key, value := range v.result {
+		if keep(key, value) {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+/*
+Flattening the values into dot paths, the inverse of the bracket key expansion
+e.g. {"user": {"address": {"city": "NYC"}}, "tags": ["a"]} gives {"user.address.city": "NYC", "tags.0": "a"},
+empty maps and slices are kept as values
+*/
+func (v requestValues) Flatten() RequestValue {
+	result := make(RequestValue)
+	flattenInto(result, "", v.result)
+	return result
+}
+
+// MarshalJSON encodes the parsed values, so json.NewEncoder(w).Encode(req) writes them instead of an empty object.
+func (v requestValues) MarshalJSON() ([]byte, error) {
+	jsonData, err := v.encode(v.result)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), jsonData...), nil
+}
+
+// String returns the parsed values as JSON for debugging.
+func (v requestValues) String() string {
+	jsonData, err := v.encode(v.result)
+	if err != nil {
+		return fmt.Sprint(v.result)
+	}
+	return string(jsonData)
+}
+
+// GetString returns the value at path as a string, or the default, "" without one, when it is missing.
+func (v requestValues) GetString(path string, def ...string) string {
+	value, _ := valueAt(v.result, path)
+	if s, ok := scalarString(value); ok {
+		return s
+	}
+	return first(def)
+}
+
+// GetInt returns the value at path as an int, or the default when it is missing or not a whole number.
+func (v requestValues) GetInt(path string, def ...int) int {
+	if n, ok := v.int64At(path); ok && int64(int(n)) == n {
+		return int(n)
+	}
+	return first(def)
+}
+
+// GetInt64 returns the value at path as an int64, or the default when it is missing or not a whole number.
+func (v requestValues) GetInt64(path string, def ...int64) int64 {
+	if n, ok := v.int64At(path); ok {
+		return n
+	}
+	return first(def)
+}
+
+// GetFloat returns the value at path as a float64, or the default when it is missing or not a number.
+func (v requestValues) GetFloat(path string, def ...float64) float64 {
+	value, _ := valueAt(v.result, path)
+	switch n := value.(type) {
+	case float64:
+		return n
+	case int:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case string:
+		if f, err := strconv.ParseFloat(strings.TrimSpace(n), 64); err == nil {
+			return f
+		}
+	}
+	return first(def)
+}
+
+// GetBool returns the value at path as a bool, or the default when it is missing or not a boolean,
+// strings are read with strconv.ParseBool.
+func (v requestValues) GetBool(path string, def ...bool) bool {
+	value, _ := valueAt(v.result, path)
+	switch b := value.(type) {
+	case bool:
+		return b
+	case string:
+		if parsed, err := strconv.ParseBool(strings.TrimSpace(b)); err == nil {
+			return parsed
+		}
+	}
+	return first(def)
+}
+
+// GetTime returns the value at path as a time, parsed like time fields in ToBind, e.g. RFC 3339 or "2006-01-02",
+// or the default when it is missing or not a time.
+func (v requestValues) GetTime(path string, def ...time.Time) time.Time {
+	value, _ := valueAt(v.result, path)
+	switch t := value.(type) {
+	case time.Time:
+		return t
+	case string:
+		if parsed, err := convertTime(context.Background(), t); err == nil {
+			return parsed.(time.Time)
+		}
+	}
+	return first(def)
+}
+
+// GetStringSlice returns the values at path as strings, a single value gives a slice of one,
+// or the default when it is missing.
+func (v requestValues) GetStringSlice(path string, def ...string) []string {
+	value, _ := valueAt(v.result, path)
+	if items, ok := value.([]interface{}); ok {
+		values := make([]string, 0, len(items))
+		for _, item := range items {
+			if s, ok := scalarString(item); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	if s, ok := scalarString(value); ok {
+		return []string{s}
+	}
+	return def
+}
+
+func (v requestValues) int64At(path string) (int64, bool) {
+	value, _ := valueAt(v.result, path)
+	switch n := value.(type) {
+	case int:
+		return int64(n), true
+	case int64:
+		return n, true
+	case float64:
+		if n == float64(int64(n)) {
+			return int64(n), true
+		}
+	case string:
+		if parsed, err := strconv.ParseInt(strings.TrimSpace(n), 10, 64); err == nil {
+			return parsed, true
+		}
+	}
+	return 0, false
+}
+
+func flattenInto(result RequestValue, path string, value interface{}) {
+	switch v := value.(type) {
+	case RequestValue:
+		if len(v) > 0 {
+			for key, item := range v {
+				flattenInto(result, joinPath(path, key), item)
+			}
+			return
+		}
+	case []interface{}:
+		if len(v) > 0 {
+			for i, item := range v {
+				flattenInto(result, joinPath(path, strconv.Itoa(i)), item)
+			}
+			return
+		}
+	}
+	if path != "" {
+		result[path] = value
+	}
+}
+
+func deepCopy(value interface{}) interface{} {
+	switch v := value.(type) {
+	case RequestValue:
+		result := make(RequestValue, len(v))
+		for key, item := range v {
+			result[key] = deepCopy(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = deepCopy(item)
+		}
+		return result
+	}
+	return value
+}
+
+// setPath sets value at the keys, creating the maps on the way.
+func setPath(target RequestValue, keys []string, value interface{}) {
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := target[key].(RequestValue)
+		if !ok {
+			next = make(RequestValue)
+			target[key] = next
+		}
+		target = next
+	}
+	target[keys[len(keys)-1]] = value
+}
+
+// withoutPath copies values without the value at keys, copying only the maps along the path.
+func withoutPath(values RequestValue, keys []string) RequestValue {
+	result := make(RequestValue, len(values))
+	for key, value := range values {
+		result[key] = value
+	}
+	switch {
+	case len(keys) == 1:
+		delete(result, keys[0])
+	case len(keys) > 1:
+		if child, ok := result[keys[0]].(RequestValue); ok {
+			result[keys[0]] = withoutPath(child, keys[1:])
+		}
+	}
+	return result
+}
+
+// first is the optional default of an accessor, the zero value without one.
+func first[T any](def []T) T {
+	var zero T
+	if len(def) > 0 {
+		return def[0]
+	}
+	return zero
+}
@@ -0,0 +1,330 @@
+package inrequest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTypedAccessors(t *testing.T) {
+	body := `{"name":"John","age":31,"balance":12.5,"id":9007199254740,"active":true,"joined":"2024-01-02","tags":["a","b"]}`
+	req, err := Json(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := Query(httptest.NewRequest(http.MethodGet, "/?page=2&debug=true&sort=name", nil))
+
+	t.Run("should convert present values", func(t *testing.T) {
+		if v := req.GetString("name"); v != "John" {
+			t.Fatalf("expected John, got %q", v)
+		}
+		if v := req.GetInt("age"); v != 31 {
+			t.Fatalf("expected 31, got %d", v)
+		}
+		if v := req.GetInt64("id"); v != 9007199254740 {
+			t.Fatalf("expected 9007199254740, got %d", v)
+		}
+		if v := req.GetFloat("balance"); v != 12.5 {
+			t.Fatalf("expected 12.5, got %v", v)
+		}
+		if v := req.GetBool("active"); !v {
+			t.Fatal("expected active")
+		}
+		if v := req.GetTime("joined"); !v.Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)) {
+			t.Fatalf("expected 2024-01-02, got %v", v)
+		}
+		if v := req.GetStringSlice("tags"); !reflect.DeepEqual(v, []string{"a", "b"}) {
+			t.Fatalf("expected [a b], got %v", v)
+		}
+		if v := query.GetInt("page"); v != 2 {
+			t.Fatalf("expected page 2, got %d", v)
+		}
+		if v := query.GetStringSlice("sort"); !reflect.DeepEqual(v, []string{"name"}) {
+			t.Fatalf("expected [name], got %v", v)
+		}
+	})
+	t.Run("should fall back to the defaults", func(t *testing.T) {
+		if v := query.GetInt("limit", 20); v != 20 {
+			t.Fatalf("expected default 20, got %d", v)
+		}
+		if v := query.GetInt("sort", 5); v != 5 {
+			t.Fatalf("expected default 5 for a non number, got %d", v)
+		}
+		if v := query.GetString("missing"); v != "" {
+			t.Fatalf("expected empty string, got %q", v)
+		}
+		if v := req.GetStringSlice("missing", "x", "y"); !reflect.DeepEqual(v, []string{"x", "y"}) {
+			t.Fatalf("expected default slice, got %v", v)
+		}
+	})
+}
+
+func TestInspection(t *testing.T) {
+	query := Query(httptest.NewRequest(http.MethodGet, "/?sort=name&filter[status]=active&page=", nil))
+
+	t.Run("should report present paths", func(t *testing.T) {
+		if !query.Has("filter[status]") || !query.Has("page") || query.Has("filter[role]") {
+			t.Fatalf("unexpected presence in %v", query.ToMap())
+		}
+	})
+	t.Run("should list the sorted top-level keys", func(t *testing.T) {
+		if keys := query.Keys(); !reflect.DeepEqual(keys, []string{"filter", "page", "sort"}) {
+			t.Fatalf("expected [filter page sort], got %v", keys)
+		}
+	})
+	t.Run("should report an empty request", func(t *testing.T) {
+		if query.IsEmpty() || !Query(httptest.NewRequest(http.MethodGet, "/", nil)).IsEmpty() {
+			t.Fatal("expected only the request without values to be empty")
+		}
+	})
+}
+
+func TestOnlyExceptFilter(t *testing.T) {
+	body := `{"name":"John","email":"john@example.com","password":"secret","user":{"role":"admin","token":"abc"}}`
+	req, err := Json(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should keep only the given paths", func(t *testing.T) {
+		expected := RequestValue{"name": "John", "user": RequestValue{"role": "admin"}}
+		if result := req.Only("name", "user.role", "missing"); !reflect.DeepEqual(result, expected) {
+			t.Fatalf("expected %v, got %v", expected, result)
+		}
+	})
+	t.Run("should drop the given paths without touching the request", func(t *testing.T) {
+		expected := RequestValue{"name": "John", "email": "john@example.com", "user": RequestValue{"role": "admin"}}
+		if result := req.Except("password", "user[token]"); !reflect.DeepEqual(result, expected) {
+			t.Fatalf("expected %v, got %v", expected, result)
+		}
+		if !req.Has("user.token") || !req.Has("password") {
+			t.Fatalf("expected the request untouched, got %v", req.ToMap())
+		}
+	})
+	t.Run("should keep the values accepted by the filter", func(t *testing.T) {
+		result := req.Filter(func(key string, value interface{}) bool {
+			_, ok := value.(string)
+			return ok && key != "password"
+		})
+		if expected := (RequestValue{"name": "John", "email": "john@example.com"}); !reflect.DeepEqual(result, expected) {
+			t.Fatalf("expected %v, got %v", expected, result)
+		}
+	})
+}
+
+func TestFlatten(t *testing.T) {
+	body := `{"user":{"address":{"city":"NYC"}},"items":[{"price":10.5},{"price":3}],"tags":[],"note":null}`
+	req, err := Json(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := RequestValue{
+		"user.address.city": "NYC",
+		"items.0.price":     10.5,
+		"items.1.price":     float64(3),
+		"tags":              []interface{}{},
+		"note":              nil,
+	}
+	if result := req.Flatten(); !reflect.DeepEqual(result, expected) {
+		t.Fatalf("expected %v, got %v", expected, result)
+	}
+}
+
+func TestToMapCopy(t *testing.T) {
+	req, err := Json(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"user":{"name":"John"},"tags":["a"]}`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	copied := req.ToMapCopy()
+	copied["user"].(RequestValue)["name"] = "Jane"
+	copied["tags"].([]interface{})[0] = "b"
+
+	if req.GetString("user.name") != "John" || req.GetString("tags[0]") != "a" {
+		t.Fatalf("expected the request untouched, got %v", req.ToMap())
+	}
+}
+
+func TestMemoizedJson(t *testing.T) {
+	req, err := Json(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"John"}`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should reuse the encoding across calls", func(t *testing.T) {
+		var user struct {
+			Name string `json:"name"`
+		}
+		if err := req.ToBind(&user); err != nil {
+			t.Fatal(err)
+		}
+		data, _ := req.ToJsonByte()
+		data[2] = 'X'
+		if s, _ := req.ToJsonString(); s != `{"name":"John"}` {
+			t.Fatalf("expected the encoding unaffected by the returned bytes, got %s", s)
+		}
+	})
+	t.Run("should encode again after ToMap hands the values out", func(t *testing.T) {
+		req.ToMap()["name"] = "Jane"
+		if s, _ := req.ToJsonString(); s != `{"name":"Jane"}` {
+			t.Fatalf("expected the changed values, got %s", s)
+		}
+	})
+	t.Run("should bind changes made to the map after an encoding", func(t *testing.T) {
+		values := req.ToMap()
+		if _, err := req.ToJsonString(); err != nil {
+			t.Fatal(err)
+		}
+		values["name"] = "Joe"
+		var user struct {
+			Name string `json:"name"`
+		}
+		if err := req.ToBind(&user); err != nil {
+			t.Fatal(err)
+		}
+		if user.Name != "Joe" {
+			t.Fatalf("expected the changed name Joe, got %s", user.Name)
+		}
+		if s, _ := req.ToJsonString(); s != `{"name":"Joe"}` {
+			t.Fatalf("expected the changed values, got %s", s)
+		}
+	})
+}
+
+func TestMemoizedJsonAccessors(t *testing.T) {
+	newRequest := func(t *testing.T) JsonRequest {
+		req, err := Json(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"user":{"name":"John"}}`)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = req.ToJsonString(); err != nil {
+			t.Fatal(err)
+		}
+		return req
+	}
+	for name, mutate := range map[string]func(req JsonRequest){
+		"Get": func(req JsonRequest) {
+			user, _ := req.Get("user")
+			user.(map[string]interface{})["name"] = "Jane"
+		},
+		"Except": func(req JsonRequest) {
+			req.Except("id")["user"].(map[string]interface{})["name"] = "Jane"
+		},
+		"Only": func(req JsonRequest) {
+			req.Only("user")["user"].(map[string]interface{})["name"] = "Jane"
+		},
+		"Filter": func(req JsonRequest) {
+			req.Filter(func(string, interface{}) bool { return true })["user"].(map[string]interface{})["name"] = "Jane"
+		},
+	} {
+		t.Run("should encode changes made through "+name, func(t *testing.T) {
+			req := newRequest(t)
+			mutate(req)
+			if s, _ := req.ToJsonString(); s != `{"user":{"name":"Jane"}}` {
+				t.Fatalf("expected the changed values, got %s", s)
+			}
+		})
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	query := Query(httptest.NewRequest(http.MethodGet, "/?user[name]=John", nil))
+
+	t.Run("should encode the parsed values", func(t *testing.T) {
+		var buf strings.Builder
+		if err := json.NewEncoder(&buf).Encode(query); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != "{\"user\":{\"name\":\"John\"}}\n" {
+			t.Fatalf("expected the parsed values, got %s", buf.String())
+		}
+	})
+	t.Run("should print the parsed values", func(t *testing.T) {
+		if s := fmt.Sprint(query); s != `{"user":{"name":"John"}}` {
+			t.Fatalf("expected the parsed values, got %s", s)
+		}
+	})
+	t.Run("should encode every request type", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header = http.Header{"Name": {"John"}}
+		req.AddCookie(&http.Cookie{Name: "name", Value: "John"})
+		graphql, err := Graphql(httptest.NewRequest(http.MethodGet, `/?query={me}&variables={"name":"John"}`, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		xml, err := Xml(httptest.NewRequest(http.MethodPost, "/", strings.NewReader("<user><name>John</name></user>")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		merged, err := Merge(Query(httptest.NewRequest(http.MethodGet, "/?name=John", nil)), FromMap(nil), PreferFirst)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for name, request := range map[string]interface{}{
+			"header":  Headers(req),
+			"cookie":  Cookies(req),
+			"graphql": graphql,
+			"xml":     xml,
+			"merged":  merged,
+		} {
+			want := `{"name":"John"}`
+			if name == "header" {
+				want = `{"Cookie":"name=John","Name":"John"}`
+			}
+			data, err := json.Marshal(request)
+			if err != nil || string(data) != want || fmt.Sprint(request) != want {
+				t.Fatalf("expected %s to encode %s, got %s %s (%v)", name, want, data, fmt.Sprint(request), err)
+			}
+		}
+	})
+}
+
+func TestRedactedKeys(t *testing.T) {
+	body := `{"name":"John","password":"secret","cards":[{"number":"4242"}]}`
+	newRequest := func() *http.Request {
+		return httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	}
+
+	t.Run("should hide the keys from the json output", func(t *testing.T) {
+		req, err := Json(newRequest(), WithRedactedKeys("password", "cards[][number]"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := `{"cards":[{"number":"***"}],"name":"John","password":"***"}`
+		if s, _ := req.ToJsonString(); s != expected {
+			t.Fatalf("expected %s, got %s", expected, s)
+		}
+		if s := req.DebugString(); strings.Contains(s, "secret") || strings.Contains(s, "4242") {
+			t.Fatalf("expected the dump redacted, got\n%s", s)
+		}
+	})
+	t.Run("should bind the real values", func(t *testing.T) {
+		req, err := Json(newRequest(), WithRedactedKeys("password"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var user struct {
+			Password string `json:"password"`
+		}
+		if err := req.ToBind(&user); err != nil || user.Password != "secret" {
+			t.Fatalf("expected the real password, got %q (%v)", user.Password, err)
+		}
+		if req.GetString("password") != "secret" {
+			t.Fatalf("expected the real password from Get")
+		}
+	})
+	t.Run("should keep the keys redacted after a merge", func(t *testing.T) {
+		req, err := Json(newRequest(), WithRedactedKeys("password"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		merged := Query(httptest.NewRequest(http.MethodGet, "/?page=2", nil)).MergeWith(req)
+		if s, _ := merged.ToJsonString(); strings.Contains(s, "secret") {
+			t.Fatalf("expected the password redacted, got %s", s)
+		}
+	})
+}
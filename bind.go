@@ -0,0 +1,930 @@
+package inrequest
+
+import (
+	"context"
+	"database/sql"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"mime/multipart"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Converter turns a raw request value into a value of the type it is registered for.
+type Converter func(value string) (interface{}, error)
+
+// ConverterCtx is a Converter receiving the context of the request being bound,
+// e.g. to read the tenant timezone or resolve a slug through a ctx-scoped lookup.
+type ConverterCtx func(ctx context.Context, value string) (interface{}, error)
+
+var (
+	convertersMu sync.RWMutex
+	converters   = map[reflect.Type]ConverterCtx{
+		reflect.TypeOf(time.Time{}):      convertTime,
+		reflect.TypeOf(time.Duration(0)): withoutContext(convertDuration),
+	}
+)
+
+var (
+	tagNameMu sync.RWMutex
+	tagName   = "json"
+)
+
+var defaultNullTokens = []string{"null"}
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// RegisterConverter makes ToBind use fn for every field, slice element or map
+// value of the same type as sample.
+// e.g. RegisterConverter(decimal.Decimal{}, func(s string) (interface{}, error) { return decimal.NewFromString(s) })
+func RegisterConverter(sample interface{}, fn Converter) {
+	RegisterConverterCtx(sample, withoutContext(fn))
+}
+
+/*
+RegisterConverterCtx is RegisterConverter for converters needing the request context,
+e.g. parsing dates in the timezone of the tenant stored in the context:
+
+	inrequest.RegisterConverterCtx(time.Time{}, func(ctx context.Context, s string) (interface{}, error) {
+		return time.ParseInLocation("2006-01-02", s, tenant.From(ctx).Location)
+	})
+
+The context is the one of the *http.Request parsed, or the one given to WithContext
+*/
+func RegisterConverterCtx(sample interface{}, fn ConverterCtx) {
+	convertersMu.Lock()
+	defer convertersMu.Unlock()
+	converters[reflect.TypeOf(sample)] = fn
+}
+
+func withoutContext(fn Converter) ConverterCtx {
+	return func(_ context.Context, value string) (interface{}, error) {
+		return fn(value)
+	}
+}
+
+// SetTagName changes the struct tag read by every binder in place of `json`,
+// e.g. SetTagName("mapstructure"). WithTagName overrides it for a single call.
+func SetTagName(name string) {
+	tagNameMu.Lock()
+	defer tagNameMu.Unlock()
+	tagName = name
+}
+
+func defaultTagName() string {
+	tagNameMu.RLock()
+	defer tagNameMu.RUnlock()
+	return tagName
+}
+
+func converterFor(t reflect.Type) (ConverterCtx, bool) {
+	convertersMu.RLock()
+	defer convertersMu.RUnlock()
+	fn, ok := converters[t]
+	return fn, ok
+}
+
+// locationKey carries the location of times without zone to convertTime, see WithLocation.
+type locationKey struct{}
+
+// convertTime parses value in the location carried by ctx, UTC by default, unless value holds its own zone.
+func convertTime(ctx context.Context, value string) (interface{}, error) {
+	loc, ok := ctx.Value(locationKey{}).(*time.Location)
+	if !ok {
+		loc = time.UTC
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot parse %q as time", value)
+}
+
+// locations caches the zones of `tz` tags.
+var locations sync.Map
+
+func loadLocation(name string) (*time.Location, error) {
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locations.Store(name, loc)
+	return loc, nil
+}
+
+func convertDuration(value string) (interface{}, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		return d, nil
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse %q as duration", value)
+	}
+	return time.Duration(n), nil
+}
+
+// binder binds parsed values into models.
+type binder struct {
+	// tags lists the struct tags naming a field, the first one present on a field wins.
+	// Fields without any of them are matched by their Go name.
+	tags []string
+	// nullTokens lists the strings bound like null.
+	nullTokens []string
+	// strict reports keys matching no field, like json.Decoder.DisallowUnknownFields.
+	strict bool
+	// maxFileBytes caps the uploads read into []byte fields, zero means no cap.
+	maxFileBytes int64
+	// commaArrays splits strings bound into slices on commas, e.g. "1,2,3".
+	commaArrays bool
+	// snakeCase matches untagged fields to snake_case keys, e.g. first_name to FirstName.
+	snakeCase bool
+	// options receives the unknown keys dropped when not strict, may be nil.
+	options *Options
+	// ctx is handed to the converters registered with RegisterConverterCtx, may be nil.
+	ctx context.Context
+	// location interprets times without zone, nil means UTC. A `tz` tag changes it while its field is bound.
+	location *time.Location
+	// errs collects the fields that failed, binding carries on past them.
+	errs BindErrors
+}
+
+func newBinder(tags ...string) *binder {
+	return &binder{tags: tags, nullTokens: defaultNullTokens}
+}
+
+// binderOf returns a binder reading tags, then the configured tag name, honoring options which may be nil.
+func binderOf(options *Options, tags ...string) *binder {
+	return newBinder(append(tags, options.tagName())...).with(options)
+}
+
+// with applies the binding options of options, which may be nil, to b.
+func (b *binder) with(options *Options) *binder {
+	if options != nil {
+		b.options = options
+		b.ctx = options.ctx
+		b.location = options.Location
+		b.strict = options.DisallowUnknownFields
+		b.commaArrays = options.CommaSeparatedArrays
+		b.snakeCase = options.SnakeCaseFields
+		b.maxFileBytes = options.MaxBindFileSize
+		if options.NullTokens != nil {
+			b.nullTokens = options.NullTokens
+		}
+	}
+	return b
+}
+
+/*
+Binding the parsed values into model field by field
+e.g. {"dates": ["2024-01-01", "2024-02-01"]} fills a []time.Time field by running
+the time converter on every element
+*/
+func (b *binder) bind(source RequestValue, model interface{}) error {
+	dst := reflect.ValueOf(model)
+	if dst.Kind() != reflect.Ptr || dst.IsNil() {
+		return &BindError{Err: errors.New("model must be a non-nil pointer")}
+	}
+	return b.result(b.bindValue(dst.Elem(), source, ""))
+}
+
+// result is the outcome of a bind: err when binding stopped, otherwise the collected field failures.
+func (b *binder) result(err error) error {
+	if err != nil {
+		return err
+	}
+	if len(b.errs) > 0 {
+		return b.errs
+	}
+	return nil
+}
+
+func (b *binder) bindValue(dst reflect.Value, src interface{}, path string) error {
+	if src == nil || b.isNull(src) {
+		if dst.Kind() == reflect.Ptr || isNullable(dst.Type()) {
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+		if src == nil {
+			return nil
+		}
+	}
+	if isNullable(dst.Type()) {
+		if err := b.bindValue(dst.Field(0), src, path); err != nil {
+			return err
+		}
+		dst.Field(1).SetBool(true)
+		return nil
+	}
+	// Decoders such as msgpack and cbor already hand out typed values e.g. time.Time.
+	// Maps and slices are copied, the model must not share them with the request.
+	if reflect.TypeOf(src) == dst.Type() {
+		dst.Set(reflect.ValueOf(deepCopy(src)))
+		return nil
+	}
+	if fn, ok := converterFor(dst.Type()); ok {
+		return b.bindConverted(dst, src, path, fn)
+	}
+	if u, ok := unionFor(dst.Type()); ok {
+		return b.bindUnion(dst, src, path, u)
+	}
+	if reflect.TypeOf(src).AssignableTo(dst.Type()) {
+		dst.Set(reflect.ValueOf(deepCopy(src)))
+		return nil
+	}
+	if header, ok := src.(*multipart.FileHeader); ok {
+		if ok, err := b.bindFile(dst, header, path); ok {
+			return err
+		}
+	}
+	if dst.CanAddr() {
+		if ok, err := b.bindUnmarshaler(dst, src, path); ok {
+			return err
+		}
+		if _, ok := dst.Addr().Interface().(json.Unmarshaler); ok {
+			return b.bindJson(dst, src, path)
+		}
+	}
+
+	switch dst.Kind() {
+	case reflect.Ptr:
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return b.bindValue(dst.Elem(), src, path)
+	case reflect.Struct:
+		if values, ok := src.(RequestValue); ok {
+			return b.bindStruct(dst, values, path)
+		}
+	case reflect.Map:
+		if dst.Type().Key().Kind() != reflect.String {
+			break
+		}
+		if values, ok := src.(RequestValue); ok {
+			return b.bindMap(dst, values, path)
+		}
+		if items, ok := src.([]interface{}); ok {
+			values := make(RequestValue, len(items))
+			for i, item := range items {
+				values[strconv.Itoa(i)] = item
+			}
+			return b.bindMap(dst, values, path)
+		}
+	case reflect.Slice:
+		if value, ok := src.(string); ok && dst.Type().Elem().Kind() == reflect.Uint8 {
+			dst.SetBytes([]byte(value))
+			return nil
+		}
+		return b.bindSlice(dst, src, path)
+	case reflect.Array:
+		if values, ok := src.([]interface{}); ok {
+			for i := 0; i < dst.Len() && i < len(values); i++ {
+				if err := b.record(b.bindValue(dst.Index(i), values[i], joinPath(path, strconv.Itoa(i)))); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	case reflect.String:
+		if value, ok := scalarString(src); ok {
+			dst.SetString(value)
+			return nil
+		}
+		return typeError(dst, src, path)
+	case reflect.Bool:
+		return b.bindBool(dst, src, path)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return b.bindInt(dst, src, path)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return b.bindUint(dst, src, path)
+	case reflect.Float32, reflect.Float64:
+		return b.bindFloat(dst, src, path)
+	}
+	return b.bindJson(dst, src, path)
+}
+
+func (b *binder) bindConverted(dst reflect.Value, src interface{}, path string, fn ConverterCtx) error {
+	raw, ok := scalarString(src)
+	if !ok {
+		return typeError(dst, src, path)
+	}
+	value, err := fn(b.context(), raw)
+	if err != nil {
+		return &BindError{Field: path, Err: err}
+	}
+	converted := reflect.ValueOf(value)
+	if !converted.IsValid() || !converted.Type().AssignableTo(dst.Type()) {
+		return typeError(dst, src, path)
+	}
+	dst.Set(converted)
+	return nil
+}
+
+// context is the context handed to converters, the background context when none was given.
+func (b *binder) context() context.Context {
+	ctx := b.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if b.location != nil {
+		ctx = context.WithValue(ctx, locationKey{}, b.location)
+	}
+	return ctx
+}
+
+/*
+Switching the location of times without zone to the tz tag of a field, returning the location to restore
+e.g. `tz:"America/New_York"` reads "2024-03-01 09:30" at 9:30 in New York. An unknown zone is recorded for path
+and reported false
+*/
+func (b *binder) locate(tz string, path string) (*time.Location, bool) {
+	previous := b.location
+	if tz == "" {
+		return previous, true
+	}
+	loc, err := loadLocation(tz)
+	if err != nil {
+		b.errs = append(b.errs, FieldError{Field: path, Tag: "tz", Param: tz, Err: err})
+		return previous, false
+	}
+	b.location = loc
+	return previous, true
+}
+
+// isNull reports whether src is one of the strings standing for null.
+func (b *binder) isNull(src interface{}) bool {
+	value, ok := src.(string)
+	return ok && containsString(b.nullTokens, value)
+}
+
+// isNullable reports whether t is shaped like sql.NullString or sql.Null[T]:
+// a sql.Scanner struct holding the value followed by a Valid flag.
+func isNullable(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || t.NumField() != 2 || !reflect.PtrTo(t).Implements(scannerType) {
+		return false
+	}
+	valid := t.Field(1)
+	return valid.Name == "Valid" && valid.Type.Kind() == reflect.Bool && t.Field(0).PkgPath == ""
+}
+
+// bindUnmarshaler hands the raw value to encoding.BinaryUnmarshaler or encoding.TextUnmarshaler,
+// e.g. uuid.UUID or decimal.Decimal fields. It reports false when dst implements neither.
+func (b *binder) bindUnmarshaler(dst reflect.Value, src interface{}, path string) (bool, error) {
+	target := dst.Addr().Interface()
+	var err error
+	if data, ok := src.([]byte); ok {
+		if unmarshaler, ok := target.(encoding.BinaryUnmarshaler); ok {
+			err = unmarshaler.UnmarshalBinary(data)
+		} else if unmarshaler, ok := target.(encoding.TextUnmarshaler); ok {
+			err = unmarshaler.UnmarshalText(data)
+		} else {
+			return false, nil
+		}
+	} else if raw, ok := scalarString(src); ok {
+		if unmarshaler, ok := target.(encoding.TextUnmarshaler); ok {
+			err = unmarshaler.UnmarshalText([]byte(raw))
+		} else if unmarshaler, ok := target.(encoding.BinaryUnmarshaler); ok {
+			err = unmarshaler.UnmarshalBinary([]byte(raw))
+		} else {
+			return false, nil
+		}
+	} else {
+		return false, nil
+	}
+	if err != nil {
+		return true, &BindError{Field: path, Err: err}
+	}
+	return true, nil
+}
+
+func (b *binder) bindStruct(dst reflect.Value, values RequestValue, path string) error {
+	if err := b.bindFields(dst, values, path); err != nil {
+		return err
+	}
+	if b.strict {
+		b.checkUnknown(dst.Type(), values, path)
+	} else if b.options != nil && b.options.Logger != nil {
+		for _, key := range b.unknownKeys(dst.Type(), values) {
+			b.options.log(slog.LevelDebug, "inrequest: dropped unknown field", "field", joinPath(path, key))
+		}
+	}
+	return nil
+}
+
+func (b *binder) bindFields(dst reflect.Value, values RequestValue, path string) error {
+	for _, field := range b.plan(dst.Type()) {
+		target := dst.Field(field.index)
+		switch {
+		case field.prefix != "":
+			prefixed := valuesWithPrefix(values, field.prefix)
+			if len(prefixed) == 0 {
+				continue
+			}
+			if err := b.record(b.bindValue(target, prefixed, joinPath(path, field.name))); err != nil {
+				return err
+			}
+		case field.embedded:
+			if target.Kind() == reflect.Ptr {
+				if target.IsNil() {
+					target.Set(reflect.New(target.Type().Elem()))
+				}
+				target = target.Elem()
+			}
+			if err := b.bindFields(target, values, path); err != nil {
+				return err
+			}
+		default:
+			value, ok := b.lookup(values, field)
+			if field.checkbox {
+				value, ok = checkboxValue(value, ok), true
+			}
+			if !ok {
+				if field.required {
+					b.errs = append(b.errs, requiredError(joinPath(path, field.name)))
+				}
+				continue
+			}
+			previous, ok := b.locate(field.tz, joinPath(path, field.name))
+			if !ok {
+				continue
+			}
+			err := b.record(b.bindValue(target, value, joinPath(path, field.name)))
+			b.location = previous
+			if err != nil {
+				return err
+			}
+			b.checkRules(field.rules, target, joinPath(path, field.name))
+		}
+	}
+	return nil
+}
+
+// checkUnknown records a FieldError for every key of values matching no field of t.
+func (b *binder) checkUnknown(t reflect.Type, values RequestValue, path string) {
+	for _, key := range b.unknownKeys(t, values) {
+		b.errs = append(b.errs, FieldError{Field: joinPath(path, key), Tag: "unknown", Err: ErrUnknownField})
+	}
+}
+
+// unknownKeys lists the keys of values matching no field of t in sorted order.
+func (b *binder) unknownKeys(t reflect.Type, values RequestValue) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		if !b.knownKey(t, key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (b *binder) knownKey(t reflect.Type, key string) bool {
+	for _, field := range b.plan(t) {
+		switch {
+		case field.prefix != "":
+			if len(key) > len(field.prefix) && strings.EqualFold(key[:len(field.prefix)], field.prefix) {
+				return true
+			}
+		case field.embedded:
+			embedded := t.Field(field.index).Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if b.knownKey(embedded, key) {
+				return true
+			}
+		default:
+			if strings.EqualFold(key, field.name) || b.snakeCase && field.untagged && snakeCaseOf(key, field.name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fieldPlan describes how bindStruct fills one struct field.
+type fieldPlan struct {
+	index int
+	name  string
+	// prefix is set for fields tagged `prefix:"address_"`.
+	prefix string
+	// embedded marks anonymous structs flattened into their parent.
+	embedded bool
+	// rules holds the constraint tags checked once the field is bound.
+	rules []fieldRule
+	// required reports a missing key for fields tagged `required:"true"`.
+	required bool
+	// checkbox binds a missing key as false for fields tagged `form:"name,checkbox"`.
+	checkbox bool
+	// untagged marks fields named by their Go name, which WithSnakeCaseFields also matches to snake_case keys.
+	untagged bool
+	// tz names the location of the times without zone bound into fields tagged `tz:"Europe/Paris"`.
+	tz string
+}
+
+type planKey struct {
+	t    reflect.Type
+	tags string
+}
+
+// fieldPlans caches the plan of every struct type per tag list, so reflection
+// over struct tags happens once per type instead of once per request.
+var fieldPlans sync.Map
+
+func (b *binder) plan(t reflect.Type) []fieldPlan {
+	key := planKey{t: t, tags: strings.Join(b.tags, ",")}
+	if plan, ok := fieldPlans.Load(key); ok {
+		return plan.([]fieldPlan)
+	}
+
+	var plan []fieldPlan
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		name, ok := fieldName(field, b.tags)
+		if !ok {
+			continue
+		}
+		if prefix := field.Tag.Get("prefix"); prefix != "" {
+			if field.PkgPath != "" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			plan = append(plan, fieldPlan{index: i, name: name, prefix: prefix})
+			continue
+		}
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				if embedded.Elem().Kind() != reflect.Struct || field.PkgPath != "" {
+					continue
+				}
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				plan = append(plan, fieldPlan{index: i, embedded: true})
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		untagged := name == ""
+		if untagged {
+			name = field.Name
+		}
+		plan = append(plan, fieldPlan{
+			index:    i,
+			name:     name,
+			rules:    rulesOf(field),
+			required: isRequired(field),
+			checkbox: hasTagOption(field, b.tags, "checkbox"),
+			untagged: untagged,
+			tz:       field.Tag.Get("tz"),
+		})
+	}
+	fieldPlans.Store(key, plan)
+	return plan
+}
+
+func (b *binder) bindMap(dst reflect.Value, values RequestValue, path string) error {
+	t := dst.Type()
+	if dst.IsNil() {
+		dst.Set(reflect.MakeMapWithSize(t, len(values)))
+	}
+	for key, value := range values {
+		elem := reflect.New(t.Elem()).Elem()
+		if err := b.record(b.bindValue(elem, value, joinPath(path, key))); err != nil {
+			return err
+		}
+		dst.SetMapIndex(reflect.ValueOf(key).Convert(t.Key()), elem)
+	}
+	return nil
+}
+
+func (b *binder) bindSlice(dst reflect.Value, src interface{}, path string) error {
+	values, ok := src.([]interface{})
+	if s, isString := src.(string); !ok && isString && b.commaArrays {
+		values = b.commaSeparated(s, path)
+	} else if !ok {
+		values = []interface{}{src}
+	}
+	slice := reflect.MakeSlice(dst.Type(), len(values), len(values))
+	for i, value := range values {
+		if err := b.record(b.bindValue(slice.Index(i), value, joinPath(path, strconv.Itoa(i)))); err != nil {
+			return err
+		}
+	}
+	dst.Set(slice)
+	return nil
+}
+
+// commaSeparated splits a comma separated list into its items, converted like form values, empty items are left out.
+func (b *binder) commaSeparated(s string, path string) []interface{} {
+	items := strings.Split(s, ",")
+	values := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			value, _ := b.options.convertValue(path, item)
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func (b *binder) bindBool(dst reflect.Value, src interface{}, path string) error {
+	switch value := src.(type) {
+	case bool:
+		dst.SetBool(value)
+		return nil
+	case int:
+		if value == 0 || value == 1 {
+			dst.SetBool(value == 1)
+			return nil
+		}
+	case string:
+		if b, err := strconv.ParseBool(strings.TrimSpace(value)); err == nil {
+			dst.SetBool(b)
+			return nil
+		}
+	}
+	return typeError(dst, src, path)
+}
+
+func (b *binder) bindInt(dst reflect.Value, src interface{}, path string) error {
+	var n int64
+	switch value := src.(type) {
+	case int:
+		n = int64(value)
+	case int64:
+		n = value
+	case float64:
+		if value != math.Trunc(value) {
+			return typeError(dst, src, path)
+		}
+		n = int64(value)
+	case string:
+		parsed, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return typeError(dst, src, path)
+		}
+		n = parsed
+	default:
+		return typeError(dst, src, path)
+	}
+	if dst.OverflowInt(n) {
+		return typeError(dst, src, path)
+	}
+	dst.SetInt(n)
+	return nil
+}
+
+func (b *binder) bindUint(dst reflect.Value, src interface{}, path string) error {
+	var n uint64
+	switch value := src.(type) {
+	case int:
+		if value < 0 {
+			return typeError(dst, src, path)
+		}
+		n = uint64(value)
+	case int64:
+		if value < 0 {
+			return typeError(dst, src, path)
+		}
+		n = uint64(value)
+	case float64:
+		if value < 0 || value != math.Trunc(value) {
+			return typeError(dst, src, path)
+		}
+		n = uint64(value)
+	case string:
+		parsed, err := strconv.ParseUint(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return typeError(dst, src, path)
+		}
+		n = parsed
+	default:
+		return typeError(dst, src, path)
+	}
+	if dst.OverflowUint(n) {
+		return typeError(dst, src, path)
+	}
+	dst.SetUint(n)
+	return nil
+}
+
+func (b *binder) bindFloat(dst reflect.Value, src interface{}, path string) error {
+	var f float64
+	switch value := src.(type) {
+	case int:
+		f = float64(value)
+	case int64:
+		f = float64(value)
+	case float64:
+		f = value
+	case string:
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return typeError(dst, src, path)
+		}
+		f = parsed
+	default:
+		return typeError(dst, src, path)
+	}
+	if dst.OverflowFloat(f) {
+		return typeError(dst, src, path)
+	}
+	dst.SetFloat(f)
+	return nil
+}
+
+// bindJson falls back to encoding/json for types the binder does not know,
+// e.g. types implementing json.Unmarshaler.
+func (b *binder) bindJson(dst reflect.Value, src interface{}, path string) error {
+	jsonData, err := json.Marshal(src)
+	if err != nil {
+		return &BindError{Field: path, Err: err}
+	}
+	if err = json.Unmarshal(jsonData, dst.Addr().Interface()); err != nil {
+		return jsonBindError(path, err)
+	}
+	return nil
+}
+
+// record keeps a field failure and lets binding carry on with the next value.
+// Failures not tied to a field are returned as they are.
+func (b *binder) record(err error) error {
+	var bindErr *BindError
+	if errors.As(err, &bindErr) && bindErr.Field != "" {
+		b.errs = append(b.errs, FieldError{Field: bindErr.Field, Err: bindErr.Err})
+		return nil
+	}
+	return err
+}
+
+// jsonBindError turns an encoding/json failure into a BindError naming the offending field,
+// e.g. a type mismatch on "age" inside "user" gives Field "user.age".
+func jsonBindError(path string, err error) error {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		path = joinPath(path, typeErr.Field)
+	}
+	return &BindError{Field: path, Err: err}
+}
+
+func typeError(dst reflect.Value, src interface{}, path string) error {
+	return &BindError{Field: path, Err: fmt.Errorf("cannot bind %v into %s", src, dst.Type())}
+}
+
+func scalarString(src interface{}) (string, bool) {
+	switch value := src.(type) {
+	case string:
+		return value, true
+	case int:
+		return strconv.Itoa(value), true
+	case int64:
+		return strconv.FormatInt(value, 10), true
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(value), true
+	}
+	return "", false
+}
+
+// fieldName reads the name from the first of tags present on the field. It reports false for fields tagged "-".
+// A trailing "[*]" as in `form:"meta[*]"` marks a map collecting every meta[key] and is not part of the name.
+func fieldName(field reflect.StructField, tags []string) (string, bool) {
+	for _, tag := range tags {
+		name, ok := field.Tag.Lookup(tag)
+		if !ok {
+			continue
+		}
+		if name == "-" {
+			return "", false
+		}
+		if i := strings.Index(name, ","); i >= 0 {
+			name = name[:i]
+		}
+		if name != "" {
+			return strings.TrimSuffix(name, "[*]"), true
+		}
+	}
+	return "", true
+}
+
+// hasTagOption reports whether the first of tags present on field lists option after its name,
+// e.g. `form:"subscribed,checkbox"` has the option "checkbox".
+func hasTagOption(field reflect.StructField, tags []string, option string) bool {
+	for _, tag := range tags {
+		value, ok := field.Tag.Lookup(tag)
+		if !ok {
+			continue
+		}
+		_, options, _ := strings.Cut(value, ",")
+		for options != "" {
+			var name string
+			name, options, _ = strings.Cut(options, ",")
+			if name == option {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+/*
+Reading a checkbox the way HTML forms send it, a missing key is unchecked
+e.g. "on" gives true, "off" false, and a hidden "0" sent before the checkbox "1" gives the last value
+*/
+func checkboxValue(value interface{}, ok bool) interface{} {
+	if !ok {
+		return false
+	}
+	if items, isSlice := value.([]interface{}); isSlice && len(items) > 0 {
+		value = items[len(items)-1]
+	}
+	if s, isString := value.(string); isString {
+		switch strings.ToLower(strings.TrimSpace(s)) {
+		case "on":
+			return true
+		case "off":
+			return false
+		}
+	}
+	return value
+}
+
+// valuesWithPrefix collects the values whose key starts with prefix, keyed without it,
+// e.g. prefix "address_" turns {"address_city": "Paris"} into {"city": "Paris"}.
+func valuesWithPrefix(values RequestValue, prefix string) RequestValue {
+	result := make(RequestValue)
+	for key, value := range values {
+		if len(key) > len(prefix) && strings.EqualFold(key[:len(prefix)], prefix) {
+			result[key[len(prefix):]] = value
+		}
+	}
+	return result
+}
+
+// lookupKey finds key in values, falling back to a case-insensitive match like encoding/json.
+func lookupKey(values RequestValue, key string) (interface{}, bool) {
+	if value, ok := values[key]; ok {
+		return value, true
+	}
+	for k, value := range values {
+		if strings.EqualFold(k, key) {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// lookup finds the value of field in values, trying snake_case keys for untagged fields under WithSnakeCaseFields.
+func (b *binder) lookup(values RequestValue, field fieldPlan) (interface{}, bool) {
+	value, ok := lookupKey(values, field.name)
+	if ok || !b.snakeCase || !field.untagged {
+		return value, ok
+	}
+	for key, value := range values {
+		if snakeCaseOf(key, field.name) {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// snakeCaseOf reports whether key spells name in snake_case, e.g. "first_name" for FirstName and "user_id" for UserID.
+func snakeCaseOf(key string, name string) bool {
+	return strings.Contains(key, "_") && strings.EqualFold(strings.ReplaceAll(key, "_", ""), strings.ReplaceAll(name, "_", ""))
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
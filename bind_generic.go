@@ -0,0 +1,52 @@
+package inrequest
+
+import "net/http"
+
+/*
+Parsing the request according to its Content-Type and binding it into a new T
+e.g.
+
+	user, err := inrequest.Bind[CreateUser](r)
+*/
+func Bind[T any](r *http.Request, opts ...Option) (T, error) {
+	var model T
+	request, err := Parse(r, opts...)
+	if err != nil {
+		return model, err
+	}
+	err = request.ToBind(&model)
+	return model, err
+}
+
+// BindForm parses a multipart or urlencoded form and binds it into a new T.
+func BindForm[T any](r *http.Request, opts ...Option) (T, error) {
+	var model T
+	request, err := FormDataE(r, opts...)
+	if err != nil {
+		return model, err
+	}
+	err = request.ToBind(&model)
+	return model, err
+}
+
+// BindQuery binds the query string into a new T.
+func BindQuery[T any](r *http.Request, opts ...Option) (T, error) {
+	var model T
+	request, err := QueryE(r, opts...)
+	if err != nil {
+		return model, err
+	}
+	err = request.ToBind(&model)
+	return model, err
+}
+
+// BindJson parses a json body and binds it into a new T.
+func BindJson[T any](r *http.Request, opts ...Option) (T, error) {
+	var model T
+	request, err := Json(r, opts...)
+	if err != nil {
+		return model, err
+	}
+	err = request.ToBind(&model)
+	return model, err
+}
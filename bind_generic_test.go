@@ -0,0 +1,87 @@
+package inrequest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBindGeneric(t *testing.T) {
+	type User struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+	target := User{Name: "John", Age: 31}
+
+	t.Run("should bind by content type", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"John","age":31}`))
+		req.Header.Set("Content-Type", "application/json")
+		user, err := Bind[User](req)
+		if err != nil || user != target {
+			t.Fatalf("Failed binding, expected %+v, got %+v (%v)", target, user, err)
+		}
+	})
+	t.Run("should bind forms", func(t *testing.T) {
+		req := newMultipartRequest(t, map[string]string{"name": "John", "age": "31"}, nil)
+		user, err := BindForm[User](req)
+		if err != nil || user != target {
+			t.Fatalf("Failed binding form, expected %+v, got %+v (%v)", target, user, err)
+		}
+	})
+	t.Run("should bind the query string", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?name=John&age=31", nil)
+		user, err := BindQuery[User](req)
+		if err != nil || user != target {
+			t.Fatalf("Failed binding query, expected %+v, got %+v (%v)", target, user, err)
+		}
+	})
+	t.Run("should return parse errors", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":`))
+		req.Header.Set("Content-Type", "application/json")
+		if _, err := BindJson[User](req); err == nil {
+			t.Fatal("expected an error for a truncated body")
+		}
+	})
+}
+
+func TestJsonBind(t *testing.T) {
+	type Order struct {
+		ID    interface{} `json:"id"`
+		Total float64     `json:"total"`
+	}
+	newRequest := func(body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		return req
+	}
+
+	t.Run("should decode the body into the model", func(t *testing.T) {
+		var order Order
+		if err := JsonBind(newRequest(`{"id":12345678901234567,"total":9.5}`), &order, WithUseNumber()); err != nil {
+			t.Fatal(err)
+		}
+		if order.ID != json.Number("12345678901234567") || order.Total != 9.5 {
+			t.Fatalf("unexpected order %+v", order)
+		}
+	})
+	t.Run("should report unknown fields and type mismatches as bind errors", func(t *testing.T) {
+		var order Order
+		var errs BindErrors
+		if err := JsonBind(newRequest(`{"id":1,"coupon":"x"}`), &order, WithDisallowUnknownFields()); !errors.As(err, &errs) || errs[0].Field != "coupon" || errs[0].Err != ErrUnknownField {
+			t.Fatalf("expected the unknown coupon field, got %v", err)
+		}
+		var bindErr *BindError
+		if err := JsonBind(newRequest(`{"total":"free"}`), &order); !errors.As(err, &bindErr) || bindErr.Field != "total" {
+			t.Fatalf("expected a bind error for total, got %v", err)
+		}
+	})
+	t.Run("should apply the body limits", func(t *testing.T) {
+		var order Order
+		if err := JsonBind(newRequest(`{"id":1,"total":9.5}`), &order, WithMaxBodySize(4)); !IsParseError(err) {
+			t.Fatalf("expected a parse error, got %v", err)
+		}
+	})
+}
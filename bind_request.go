@@ -0,0 +1,164 @@
+package inrequest
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+)
+
+// Sources accepted by the `in` struct tag of BindRequest.
+const (
+	InQuery  = "query"
+	InBody   = "body"
+	InHeader = "header"
+	InCookie = "cookie"
+	InPath   = "path"
+)
+
+/*
+Binding every field of model from the source named by its `in` tag
+e.g.
+
+	type UpdateUser struct {
+		ID        int    `in:"path" path:"id"`
+		Fields    string `in:"query" json:"fields"`
+		RequestID string `in:"header" header:"X-Request-Id"`
+		Session   string `in:"cookie" cookie:"session_id"`
+		Name      string `json:"name"`
+	}
+
+fields without `in` tag are read from the body, a struct field tagged `in:"body"`
+without a name receives the whole body, the Validate method of model runs once every field is bound.
+BeforeBind hooks run once on a copy of every source but path, AfterBind hooks once every field is bound
+*/
+func BindRequest(r *http.Request, model interface{}, opts ...Option) error {
+	dst := reflect.ValueOf(model)
+	if dst.Kind() != reflect.Ptr || dst.IsNil() || dst.Elem().Kind() != reflect.Struct {
+		return &BindError{Err: errors.New("model must be a non-nil pointer to a struct")}
+	}
+	options := requestOptions(r, opts)
+	sources := requestSources{request: r, options: options, values: map[string]RequestValue{}}
+
+	var errs BindErrors
+	dst = dst.Elem()
+	t := dst.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		in := field.Tag.Get("in")
+		if in == "" {
+			in = InBody
+		}
+		if in == "-" {
+			continue
+		}
+		b := binderOf(options, in)
+		name, ok := fieldName(field, b.tags)
+		if !ok {
+			continue
+		}
+		values, err := sources.get(in, name)
+		if err != nil {
+			return err
+		}
+		if in == InBody && name == "" && field.Type.Kind() == reflect.Struct {
+			if err = b.bindValue(dst.Field(i), values, ""); err != nil {
+				return err
+			}
+			errs = append(errs, b.errs...)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		value, ok := lookupKey(values, name)
+		if !ok {
+			if isRequired(field) {
+				errs = append(errs, requiredError(name))
+			}
+			continue
+		}
+		if _, ok = b.locate(field.Tag.Get("tz"), name); !ok {
+			errs = append(errs, b.errs...)
+			continue
+		}
+		if err = b.record(b.bindValue(dst.Field(i), value, name)); err != nil {
+			return err
+		}
+		b.checkRules(rulesOf(field), dst.Field(i), name)
+		errs = append(errs, b.errs...)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return afterBind(options, model)
+}
+
+// requestSources parses each source of the request once, on first use.
+type requestSources struct {
+	request *http.Request
+	options *Options
+	values  map[string]RequestValue
+}
+
+func (s *requestSources) get(in string, name string) (RequestValue, error) {
+	if in == InPath {
+		if name == "" {
+			return RequestValue{}, nil
+		}
+		value := pathValue(s.request, s.options, name)
+		if value == "" {
+			return RequestValue{}, nil
+		}
+		return RequestValue{name: actualTypeOf(value)}, nil
+	}
+	if values, ok := s.values[in]; ok {
+		return values, nil
+	}
+
+	var values RequestValue
+	switch in {
+	case InQuery:
+		request, err := QueryE(s.request, optionsOf(s.options))
+		if err != nil {
+			return nil, err
+		}
+		values = request.ToMap()
+	case InHeader:
+		values = Headers(s.request).ToMap()
+	case InCookie:
+		values = Cookies(s.request).ToMap()
+	case InBody:
+		if !hasBody(s.request) {
+			values = RequestValue{}
+			break
+		}
+		request, err := Parse(s.request, optionsOf(s.options))
+		if err != nil {
+			return nil, err
+		}
+		values = request.ToMap()
+	default:
+		return nil, &BindError{Err: errors.New("unknown source \"" + in + "\" in `in` tag")}
+	}
+	values, err := beforeBind(s.options, values)
+	if err != nil {
+		return nil, err
+	}
+	s.values[in] = values
+	return values, nil
+}
+
+// pathValue reads a path parameter through Options.PathParams,
+// falling back to Request.PathValue of the Go 1.22 router.
+func pathValue(r *http.Request, options *Options, name string) string {
+	if options.PathParams != nil {
+		return options.PathParams(r, name)
+	}
+	if pv, ok := interface{}(r).(interface{ PathValue(string) string }); ok {
+		return pv.PathValue(name)
+	}
+	return ""
+}
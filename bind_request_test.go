@@ -0,0 +1,99 @@
+package inrequest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBindRequest(t *testing.T) {
+	type Profile struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+	type UpdateUser struct {
+		ID        int      `in:"path" path:"id"`
+		Fields    []string `in:"query" json:"fields"`
+		RequestID string   `in:"header" header:"X-Request-Id"`
+		Session   string   `in:"cookie" cookie:"session_id"`
+		Name      string   `json:"name"`
+		Profile   Profile  `in:"body"`
+		Skipped   string   `in:"-"`
+	}
+
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPut, "/users/42?fields=name&fields=age", strings.NewReader(`{"name":"John","age":31}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Request-Id", "req-1")
+		req.AddCookie(&http.Cookie{Name: "session_id", Value: "abc"})
+		return req
+	}
+	target := UpdateUser{
+		ID:        42,
+		Fields:    []string{"name", "age"},
+		RequestID: "req-1",
+		Session:   "abc",
+		Name:      "John",
+		Profile:   Profile{Name: "John", Age: 31},
+	}
+
+	t.Run("should bind every source declared by the in tag", func(t *testing.T) {
+		req := newRequest()
+		setter, ok := interface{}(req).(interface{ SetPathValue(name, value string) })
+		if !ok {
+			t.Skip("Request.SetPathValue requires Go 1.22")
+		}
+		setter.SetPathValue("id", "42")
+		user := UpdateUser{}
+		if err := BindRequest(req, &user); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(user, target) {
+			t.Fatalf("Failed binding request, expected %+v, got %+v", target, user)
+		}
+	})
+	t.Run("should read path parameters through the configured function", func(t *testing.T) {
+		user := UpdateUser{}
+		err := BindRequest(newRequest(), &user, WithPathParams(func(r *http.Request, name string) string {
+			return strings.TrimPrefix(r.URL.Path, "/users/")
+		}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(user, target) {
+			t.Fatalf("Failed binding request, expected %+v, got %+v", target, user)
+		}
+	})
+	t.Run("should honor the binding options", func(t *testing.T) {
+		type Tokened struct {
+			Token *string `in:"header" header:"X-Token"`
+		}
+		req := newRequest()
+		req.Header.Set("X-Token", "nil")
+		model := Tokened{}
+		if err := BindRequest(req, &model, WithNullTokens("nil")); err != nil {
+			t.Fatal(err)
+		}
+		if model.Token != nil {
+			t.Fatalf("expected the null token to bind as nil, got %q", *model.Token)
+		}
+		var strict struct {
+			Profile struct {
+				Name string `json:"name"`
+			} `in:"body"`
+		}
+		if err := BindRequest(newRequest(), &strict, WithDisallowUnknownFields()); !IsBindError(err) {
+			t.Fatalf("expected the unknown age field to be rejected, got %v", err)
+		}
+	})
+	t.Run("should reject unknown sources", func(t *testing.T) {
+		type Bad struct {
+			Value string `in:"session"`
+		}
+		if err := BindRequest(newRequest(), &Bad{}); !IsBindError(err) {
+			t.Fatalf("expected bind error, got %v", err)
+		}
+	})
+}
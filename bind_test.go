@@ -0,0 +1,666 @@
+package inrequest
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testCents int64
+
+type testTenantKey struct{}
+
+type testProjectID int
+
+type testHexColor struct {
+	R, G, B uint8
+}
+
+func (c *testHexColor) UnmarshalJSON(data []byte) error {
+	var r, g, b uint8
+	value := strings.Trim(string(data), `"#`)
+	if _, err := fmt.Sscanf(value, "%02x%02x%02x", &r, &g, &b); err != nil {
+		return err
+	}
+	*c = testHexColor{R: r, G: g, B: b}
+	return nil
+}
+
+type testLevel int
+
+func (l *testLevel) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "low":
+		*l = 1
+	case "high":
+		*l = 2
+	default:
+		return fmt.Errorf("unknown level %q", text)
+	}
+	return nil
+}
+
+// testUUID mimics uuid.UUID, a byte array read from its text form.
+type testUUID [16]byte
+
+func (u *testUUID) UnmarshalText(text []byte) error {
+	value := strings.ReplaceAll(string(text), "-", "")
+	if len(value) != 32 {
+		return fmt.Errorf("invalid uuid %q", text)
+	}
+	_, err := hex.Decode(u[:], []byte(value))
+	return err
+}
+
+type testVersion struct {
+	Major, Minor byte
+}
+
+func (v *testVersion) UnmarshalBinary(data []byte) error {
+	if len(data) != 3 || data[1] != '.' {
+		return fmt.Errorf("invalid version %q", data)
+	}
+	*v = testVersion{Major: data[0] - '0', Minor: data[2] - '0'}
+	return nil
+}
+
+func TestBindSliceOfRichTypes(t *testing.T) {
+	RegisterConverter(testCents(0), func(value string) (interface{}, error) {
+		f, err := strconv.ParseFloat(strings.TrimPrefix(value, "$"), 64)
+		return testCents(f * 100), err
+	})
+
+	type Filter struct {
+		Dates  []time.Time     `json:"dates"`
+		Prices []testCents     `json:"prices"`
+		Colors []testHexColor  `json:"colors"`
+		Since  []time.Time     `json:"since"`
+		Ptrs   []*time.Time    `json:"ptrs"`
+		Limit  *int            `json:"limit"`
+		Tags   map[string]bool `json:"tags"`
+	}
+
+	t.Run("should convert every element of repeated values", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?dates=2024-01-01&dates=2024-02-01T10:00:00Z&prices=$1.50&prices=$2&colors=%23ff0000&since=2023-12-31&ptrs=2024-03-01&limit=10&tags[new]=true", nil)
+		filter := Filter{}
+		if err := Query(req).ToBind(&filter); err != nil {
+			t.Fatal(err)
+		}
+		limit := 10
+		ptr := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+		target := Filter{
+			Dates: []time.Time{
+				time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				time.Date(2024, 2, 1, 10, 0, 0, 0, time.UTC),
+			},
+			Prices: []testCents{150, 200},
+			Colors: []testHexColor{{R: 255}},
+			Since:  []time.Time{time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC)},
+			Ptrs:   []*time.Time{&ptr},
+			Limit:  &limit,
+			Tags:   map[string]bool{"new": true},
+		}
+		if !reflect.DeepEqual(filter, target) {
+			t.Fatalf("Failed binding rich slices, expected %+v, got %+v", target, filter)
+		}
+	})
+	t.Run("should bind text unmarshaler elements from repeated, single and comma separated values", func(t *testing.T) {
+		type Lookup struct {
+			IDs    []testUUID  `json:"ids"`
+			Owners []*testUUID `json:"owners"`
+			Days   []time.Time `json:"days"`
+		}
+		first := testUUID{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00}
+		second := testUUID{15: 1}
+		requests := map[string]*http.Request{
+			"repeated": httptest.NewRequest(http.MethodGet, "/?ids=123e4567-e89b-12d3-a456-426614174000&ids=00000000-0000-0000-0000-000000000001&owners=00000000-0000-0000-0000-000000000001&days=2024-01-01", nil),
+			"brackets": httptest.NewRequest(http.MethodGet, "/?ids[]=123e4567-e89b-12d3-a456-426614174000&ids[]=00000000-0000-0000-0000-000000000001&owners[0]=00000000-0000-0000-0000-000000000001&days[]=2024-01-01", nil),
+			"commas":   httptest.NewRequest(http.MethodGet, "/?ids=123e4567-e89b-12d3-a456-426614174000,00000000-0000-0000-0000-000000000001&owners=00000000-0000-0000-0000-000000000001&days=2024-01-01", nil),
+		}
+		target := Lookup{
+			IDs:    []testUUID{first, second},
+			Owners: []*testUUID{&second},
+			Days:   []time.Time{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		}
+		for name, req := range requests {
+			lookup := Lookup{}
+			if err := Query(req, WithCommaSeparatedArrays()).ToBind(&lookup); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if !reflect.DeepEqual(lookup, target) {
+				t.Fatalf("%s: Failed binding text unmarshaler slices, expected %+v, got %+v", name, target, lookup)
+			}
+		}
+		req := httptest.NewRequest(http.MethodGet, "/?ids=123e4567-e89b-12d3-a456-426614174000&ids=nope", nil)
+		var bindErr *BindError
+		if err := Query(req).ToBind(&Lookup{}); !errors.As(err, &bindErr) || bindErr.Field != "ids.1" {
+			t.Fatalf("expected bind error on ids.1, got %v", err)
+		}
+	})
+	t.Run("should report the failing element", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?dates=2024-01-01&dates=yesterday", nil)
+		filter := Filter{}
+		err := Query(req).ToBind(&filter)
+		var bindErr *BindError
+		if !errors.As(err, &bindErr) || bindErr.Field != "dates.1" {
+			t.Fatalf("expected bind error on dates.1, got %v", err)
+		}
+	})
+}
+
+func TestRegisterConverterCtx(t *testing.T) {
+	projects := map[string]map[string]testProjectID{
+		"acme":   {"website": 1},
+		"globex": {"website": 2},
+	}
+	RegisterConverterCtx(testProjectID(0), func(ctx context.Context, slug string) (interface{}, error) {
+		id, ok := projects[ctx.Value(testTenantKey{}).(string)][slug]
+		if !ok {
+			return nil, fmt.Errorf("unknown project %q", slug)
+		}
+		return id, nil
+	})
+	type Filter struct {
+		Project testProjectID `json:"project"`
+	}
+
+	t.Run("should hand the request context to the converter", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?project=website", nil)
+		req = req.WithContext(context.WithValue(req.Context(), testTenantKey{}, "globex"))
+		filter := Filter{}
+		if err := Query(req).ToBind(&filter); err != nil {
+			t.Fatal(err)
+		}
+		if filter.Project != 2 {
+			t.Fatalf("expected the project of the tenant, got %d", filter.Project)
+		}
+	})
+	t.Run("should hand the context given to values without request", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), testTenantKey{}, "acme")
+		filter := Filter{}
+		request, err := FromValues(url.Values{"project": {"website"}}, WithContext(ctx))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = request.ToBind(&filter); err != nil {
+			t.Fatal(err)
+		}
+		if filter.Project != 1 {
+			t.Fatalf("expected the project of the tenant, got %d", filter.Project)
+		}
+	})
+}
+
+func TestBindLocation(t *testing.T) {
+	paris := time.FixedZone("Paris", 3600)
+	type Meeting struct {
+		Day    time.Time `json:"day"`
+		Start  time.Time `json:"start"`
+		Remote time.Time `json:"remote" tz:"America/New_York"`
+		Zoned  time.Time `json:"zoned"`
+	}
+
+	t.Run("should read times without zone in the location", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?day=2024-03-01&start=2024-03-01+09:30:00&remote=2024-03-01T09:30:00&zoned=2024-03-01T09:30:00Z", nil)
+		meeting := Meeting{}
+		if err := Query(req, WithLocation(paris)).ToBind(&meeting); err != nil {
+			t.Fatal(err)
+		}
+		newYork, _ := time.LoadLocation("America/New_York")
+		target := Meeting{
+			Day:    time.Date(2024, 3, 1, 0, 0, 0, 0, paris),
+			Start:  time.Date(2024, 3, 1, 9, 30, 0, 0, paris),
+			Remote: time.Date(2024, 3, 1, 9, 30, 0, 0, newYork),
+			Zoned:  time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC),
+		}
+		if !reflect.DeepEqual(meeting, target) {
+			t.Fatalf("Failed binding times in their location, expected %+v, got %+v", target, meeting)
+		}
+	})
+	t.Run("should read times of json bodies in the location", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"day":"2024-03-01"}`))
+		req.Header.Set("Content-Type", "application/json")
+		result, err := Json(req, WithLocation(paris))
+		if err != nil {
+			t.Fatal(err)
+		}
+		meeting := Meeting{}
+		if err = result.ToBind(&meeting); err != nil {
+			t.Fatal(err)
+		}
+		if !meeting.Day.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, paris)) {
+			t.Fatalf("expected midnight in Paris, got %v", meeting.Day)
+		}
+	})
+	t.Run("should report an unknown zone", func(t *testing.T) {
+		var event struct {
+			At time.Time `json:"at" tz:"Mars/Olympus"`
+		}
+		req := httptest.NewRequest(http.MethodGet, "/?at=2024-03-01", nil)
+		err := Query(req).ToBind(&event)
+		var errs BindErrors
+		if !errors.As(err, &errs) || errs[0].Field != "at" || errs[0].Tag != "tz" {
+			t.Fatalf("expected a tz error on at, got %v", err)
+		}
+	})
+}
+
+func TestBindDynamicValuesCopy(t *testing.T) {
+	t.Run("should give the model its own maps and slices", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?meta[source]=ads&tags[]=a&tags[]=b", nil)
+		query := Query(req, WithFieldType("meta.source", String))
+		query.ToJsonString()
+		model := struct {
+			Meta map[string]interface{} `json:"meta"`
+			Tags []interface{}          `json:"tags"`
+		}{}
+		if err := query.ToBind(&model); err != nil {
+			t.Fatal(err)
+		}
+		model.Meta["source"] = "MUTATED"
+		model.Tags[0] = "MUTATED"
+		if query.GetString("meta.source") != "ads" || query.GetString("tags.0") != "a" {
+			t.Fatalf("expected the request untouched, got %v", query.ToMap())
+		}
+		if s, _ := query.ToJsonString(); strings.Contains(s, "MUTATED") {
+			t.Fatalf("expected the encoding untouched, got %s", s)
+		}
+	})
+}
+
+func TestBindTagName(t *testing.T) {
+	type Account struct {
+		Email string `api:"user_email" json:"email"`
+		Name  string `json:"name"`
+	}
+	target := Account{Email: "john@example.com", Name: "John"}
+
+	t.Run("should read the tag given per call", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"user_email":"john@example.com","email":"other@example.com","name":"John"}`))
+		req.Header.Set("Content-Type", "application/json")
+		result, err := Json(req, WithTagName("api"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		account := Account{}
+		if err = result.ToBind(&account); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(account, target) {
+			t.Fatalf("Failed binding with tag name, expected %+v, got %+v", target, account)
+		}
+	})
+	t.Run("should read the tag set globally", func(t *testing.T) {
+		SetTagName("api")
+		defer SetTagName("json")
+		req := httptest.NewRequest(http.MethodGet, "/?user_email=john@example.com&email=other@example.com&name=John", nil)
+		account := Account{}
+		if err := Query(req).ToBind(&account); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(account, target) {
+			t.Fatalf("Failed binding with tag name, expected %+v, got %+v", target, account)
+		}
+	})
+}
+
+func TestBindSnakeCaseFields(t *testing.T) {
+	type Profile struct {
+		FirstName string
+		UserID    int
+		Nickname  string `json:"nick_name_tag"`
+	}
+
+	t.Run("should match snake_case keys to untagged fields", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?first_name=John&user_id=7&nick_name=johnny", nil)
+		profile := Profile{}
+		if err := Query(req, WithSnakeCaseFields(), WithDisallowUnknownFields()).ToBind(&profile); err == nil {
+			t.Fatal("expected the tagged field to keep its name")
+		}
+		target := Profile{FirstName: "John", UserID: 7}
+		if !reflect.DeepEqual(profile, target) {
+			t.Fatalf("Failed binding snake_case keys, expected %+v, got %+v", target, profile)
+		}
+	})
+	t.Run("should match snake_case keys of json bodies", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"first_name":"Jane","user_id":8}`))
+		req.Header.Set("Content-Type", "application/json")
+		result, err := Json(req, WithSnakeCaseFields())
+		if err != nil {
+			t.Fatal(err)
+		}
+		profile := Profile{}
+		if err = result.ToBind(&profile); err != nil {
+			t.Fatal(err)
+		}
+		if profile.FirstName != "Jane" || profile.UserID != 8 {
+			t.Fatalf("Failed binding snake_case keys, got %+v", profile)
+		}
+	})
+	t.Run("should ignore snake_case keys by default", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?first_name=John", nil)
+		profile := Profile{}
+		if err := Query(req).ToBind(&profile); err != nil || profile.FirstName != "" {
+			t.Fatalf("expected no match without the option, got %+v (%v)", profile, err)
+		}
+	})
+}
+
+func TestBindUnmarshalers(t *testing.T) {
+	type Alert struct {
+		Level    testLevel   `json:"level"`
+		Levels   []testLevel `json:"levels"`
+		Fallback *testLevel  `json:"fallback"`
+		Version  testVersion `json:"version"`
+	}
+
+	t.Run("should pass raw values to text and binary unmarshalers", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?level=high&levels=low&levels=high&fallback=low&version=1.2", nil)
+		alert := Alert{}
+		if err := Query(req).ToBind(&alert); err != nil {
+			t.Fatal(err)
+		}
+		fallback := testLevel(1)
+		target := Alert{Level: 2, Levels: []testLevel{1, 2}, Fallback: &fallback, Version: testVersion{Major: 1, Minor: 2}}
+		if !reflect.DeepEqual(alert, target) {
+			t.Fatalf("Failed binding unmarshalers, expected %+v, got %+v", target, alert)
+		}
+	})
+	t.Run("should report unmarshaler errors on the field", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?levels=low&levels=urgent", nil)
+		err := Query(req).ToBind(&Alert{})
+		var bindErr *BindError
+		if !errors.As(err, &bindErr) || bindErr.Field != "levels.1" {
+			t.Fatalf("expected bind error on levels.1, got %v", err)
+		}
+	})
+}
+
+func TestBindNullable(t *testing.T) {
+	type Profile struct {
+		Name     sql.NullString `json:"name"`
+		Age      sql.NullInt64  `json:"age"`
+		Verified sql.NullBool   `json:"verified"`
+		Born     sql.NullTime   `json:"born"`
+		Nickname *string        `json:"nickname"`
+		Score    *float64       `json:"score"`
+	}
+
+	t.Run("should mark present values valid", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?name=John&age=31&verified=true&born=1990-05-01&score=9.5", nil)
+		profile := Profile{}
+		if err := Query(req).ToBind(&profile); err != nil {
+			t.Fatal(err)
+		}
+		score := 9.5
+		target := Profile{
+			Name:     sql.NullString{String: "John", Valid: true},
+			Age:      sql.NullInt64{Int64: 31, Valid: true},
+			Verified: sql.NullBool{Bool: true, Valid: true},
+			Born:     sql.NullTime{Time: time.Date(1990, 5, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+			Score:    &score,
+		}
+		if !reflect.DeepEqual(profile, target) {
+			t.Fatalf("Failed binding nullable fields, expected %+v, got %+v", target, profile)
+		}
+	})
+	t.Run("should map null to invalid and nil", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?name=null&age=null&nickname=null", nil)
+		nickname := "johnny"
+		profile := Profile{Name: sql.NullString{String: "old", Valid: true}, Nickname: &nickname}
+		if err := Query(req).ToBind(&profile); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(profile, Profile{}) {
+			t.Fatalf("Failed binding null values, got %+v", profile)
+		}
+	})
+	t.Run("should map the configured null tokens", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?name=nil&age=&nickname=null&score=", nil)
+		result := Query(req, WithNullTokens("nil", ""))
+		if values := result.ToMap(); values["name"] != nil || values["age"] != nil || values["nickname"] != "null" {
+			t.Fatalf("Failed mapping null tokens, got %v", values)
+		}
+		profile := Profile{}
+		if err := result.ToBind(&profile); err != nil {
+			t.Fatal(err)
+		}
+		nickname := "null"
+		if !reflect.DeepEqual(profile, Profile{Nickname: &nickname}) {
+			t.Fatalf("Failed binding null tokens, got %+v", profile)
+		}
+	})
+	t.Run("should bind null as a string when disabled", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?name=null", nil)
+		profile := Profile{}
+		if err := Query(req, WithNullTokens()).ToBind(&profile); err != nil {
+			t.Fatal(err)
+		}
+		if profile.Name != (sql.NullString{String: "null", Valid: true}) {
+			t.Fatalf("Failed disabling null tokens, got %+v", profile)
+		}
+	})
+}
+
+func TestBindPrefixedStructs(t *testing.T) {
+	type Address struct {
+		City string `json:"city"`
+		Zip  string `json:"zip"`
+	}
+	type Audit struct {
+		CreatedBy string `json:"created_by"`
+	}
+	type Order struct {
+		Audit
+		ID       int      `json:"id"`
+		Shipping Address  `prefix:"shipping_" json:"shipping"`
+		Billing  *Address `prefix:"billing_" json:"billing"`
+		Gift     *Address `prefix:"gift_" json:"gift"`
+	}
+
+	t.Run("should bind embedded and prefixed structs from flat fields", func(t *testing.T) {
+		req := newMultipartRequest(t, map[string]string{
+			"id":            "7",
+			"created_by":    "admin",
+			"shipping_city": "Paris",
+			"shipping_zip":  "75001",
+			"billing_city":  "Lyon",
+		}, nil)
+		order := Order{}
+		if err := FormData(req).ToBind(&order); err != nil {
+			t.Fatal(err)
+		}
+		target := Order{
+			Audit:    Audit{CreatedBy: "admin"},
+			ID:       7,
+			Shipping: Address{City: "Paris", Zip: "75001"},
+			Billing:  &Address{City: "Lyon"},
+		}
+		if !reflect.DeepEqual(order, target) {
+			t.Fatalf("Failed binding prefixed structs, expected %+v, got %+v", target, order)
+		}
+	})
+}
+
+func TestBindDynamicMaps(t *testing.T) {
+	type Product struct {
+		Name  string            `json:"name"`
+		Meta  map[string]string `form:"meta[*]" json:"meta"`
+		Sizes map[string]int    `form:"sizes[*]" json:"sizes"`
+		Slots map[string]string `form:"slots[*]" json:"slots"`
+	}
+
+	t.Run("should collect arbitrary keys into map fields", func(t *testing.T) {
+		req := newMultipartRequest(t, map[string]string{
+			"name":        "Shirt",
+			"meta[color]": "red",
+			"meta[size]":  "L",
+			"sizes[w]":    "10",
+			"sizes[h]":    "20",
+			"slots[0]":    "morning",
+			"slots[1]":    "evening",
+		}, nil)
+		product := Product{}
+		if err := FormData(req).ToBind(&product); err != nil {
+			t.Fatal(err)
+		}
+		target := Product{
+			Name:  "Shirt",
+			Meta:  map[string]string{"color": "red", "size": "L"},
+			Sizes: map[string]int{"w": 10, "h": 20},
+			Slots: map[string]string{"0": "morning", "1": "evening"},
+		}
+		if !reflect.DeepEqual(product, target) {
+			t.Fatalf("Failed binding dynamic maps, expected %+v, got %+v", target, product)
+		}
+	})
+}
+
+func TestBindErrors(t *testing.T) {
+	type Order struct {
+		ID       int       `json:"id" required:"true"`
+		Quantity int       `json:"quantity"`
+		Price    float64   `json:"price"`
+		Items    []int     `json:"items"`
+		Shipped  time.Time `json:"shipped"`
+	}
+	fieldsOf := func(err error) []string {
+		var errs BindErrors
+		if !errors.As(err, &errs) {
+			t.Fatalf("expected bind errors, got %v", err)
+		}
+		var fields []string
+		for _, field := range errs.Fields() {
+			fields = append(fields, field.Field)
+		}
+		return fields
+	}
+
+	t.Run("should collect every failing field", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?quantity=many&price=cheap&items=1&items=two&items=3&shipped=soon", nil)
+		got := fieldsOf(Query(req).ToBind(&Order{}))
+		target := []string{"id", "quantity", "price", "items.1", "shipped"}
+		if !reflect.DeepEqual(got, target) {
+			t.Fatalf("Failed collecting errors, expected %v, got %v", target, got)
+		}
+	})
+	t.Run("should collect every failing field of json bodies", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"id":1,"quantity":"many","price":true}`))
+		req.Header.Set("Content-Type", "application/json")
+		result, err := Json(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := fieldsOf(result.ToBind(&Order{}))
+		target := []string{"quantity", "price"}
+		if !reflect.DeepEqual(got, target) {
+			t.Fatalf("Failed collecting errors, expected %v, got %v", target, got)
+		}
+	})
+	t.Run("should still match the first failure as a *BindError", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?id=1&quantity=many&price=cheap", nil)
+		var bindErr *BindError
+		if err := Query(req).ToBind(&Order{}); !errors.As(err, &bindErr) || bindErr.Field != "quantity" {
+			t.Fatalf("expected the first failure on quantity, got %v", err)
+		}
+	})
+}
+
+func TestBindJsonErrorField(t *testing.T) {
+	type Owner struct {
+		Age int `json:"age"`
+	}
+	type Pet struct {
+		Name  string `json:"name"`
+		Owner Owner  `json:"owner"`
+	}
+
+	t.Run("should name the field of a json type mismatch", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"Rex","owner":{"age":"31"}}`))
+		req.Header.Set("Content-Type", "application/json")
+		result, err := Json(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = result.ToBind(&Pet{})
+		var bindErr *BindError
+		if !errors.As(err, &bindErr) || bindErr.Field != "owner.age" {
+			t.Fatalf("expected bind error on owner.age, got %v", err)
+		}
+		var typeErr *json.UnmarshalTypeError
+		if !errors.As(err, &typeErr) {
+			t.Fatalf("expected the json error to stay reachable, got %v", err)
+		}
+	})
+}
+
+func TestBindDisallowUnknownFields(t *testing.T) {
+	type Address struct {
+		City string `json:"city"`
+	}
+	type Base struct {
+		ID int `json:"id"`
+	}
+	type Contact struct {
+		Base
+		Email   string            `json:"email"`
+		Address Address           `json:"address"`
+		Billing Address           `prefix:"billing_" json:"billing"`
+		Meta    map[string]string `json:"meta"`
+	}
+	unknownOf := func(err error) []string {
+		var errs BindErrors
+		if !errors.As(err, &errs) {
+			t.Fatalf("expected bind errors, got %v", err)
+		}
+		var keys []string
+		for _, field := range errs.Fields() {
+			if !errors.Is(field, ErrUnknownField) {
+				t.Fatalf("unexpected failure %v", field)
+			}
+			keys = append(keys, field.Field)
+		}
+		return keys
+	}
+
+	t.Run("should accept known keys", func(t *testing.T) {
+		req := newMultipartRequest(t, map[string]string{
+			"id": "1", "email": "john@example.com", "address[city]": "Paris", "billing_city": "Lyon", "meta[any]": "x",
+		}, nil)
+		if err := FormData(req, WithDisallowUnknownFields()).ToBind(&Contact{}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	t.Run("should list every unknown key", func(t *testing.T) {
+		req := newMultipartRequest(t, map[string]string{
+			"emial": "john@example.com", "address[town]": "Paris", "nickname": "johnny",
+		}, nil)
+		got := unknownOf(FormData(req, WithDisallowUnknownFields()).ToBind(&Contact{}))
+		target := []string{"address.town", "emial", "nickname"}
+		if !reflect.DeepEqual(got, target) {
+			t.Fatalf("Failed listing unknown keys, expected %v, got %v", target, got)
+		}
+	})
+	t.Run("should list unknown keys of json bodies", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"id":1,"emial":"john@example.com"}`))
+		req.Header.Set("Content-Type", "application/json")
+		result, err := Json(req, WithDisallowUnknownFields())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := unknownOf(result.ToBind(&Contact{})); !reflect.DeepEqual(got, []string{"emial"}) {
+			t.Fatalf("Failed listing unknown keys, got %v", got)
+		}
+	})
+}
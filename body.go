@@ -0,0 +1,216 @@
+package inrequest
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type bodyParser func(r *http.Request) (RequestValue, error)
+
+var (
+	formMediaTypes = []string{"multipart/form-data", "application/x-www-form-urlencoded"}
+	jsonMediaTypes = []string{"application/json"}
+)
+
+type parseResult struct {
+	value RequestValue
+	err   error
+}
+
+/*
+Running the body parser under the configured options
+e.g. with a parse timeout the caller gets ErrParseTimeout once the deadline passes,
+even when the client keeps the connection open and trickles bytes
+*/
+func runParser(r *http.Request, options *Options, accepts []string, parse bodyParser) (RequestValue, error) {
+	if err := preflight(r, options, accepts); err != nil {
+		return make(RequestValue), err
+	}
+	if options.MaxBodySize > 0 && r.Body != nil {
+		r.Body = &maxBodyReader{ReadCloser: r.Body, remaining: options.MaxBodySize, max: options.MaxBodySize}
+	}
+	decodedParse := func(r *http.Request) (RequestValue, error) {
+		if err := decompressBody(r, options); err != nil {
+			return make(RequestValue), err
+		}
+		value, err := parse(r)
+		if err == nil && options.MaxKeys > 0 && countValues(value) > options.MaxKeys {
+			return make(RequestValue), &ParseError{Err: &RequestTooLargeError{Limit: "keys", Max: int64(options.MaxKeys)}}
+		}
+		if err == nil && value != nil {
+			if transformErr := options.transform(value); transformErr != nil {
+				return make(RequestValue), &ParseError{Err: transformErr}
+			}
+		}
+		return value, err
+	}
+	if options.ParseTimeout <= 0 {
+		return parseResultOf(decodedParse(r))
+	}
+	if r.Body != nil {
+		r.Body = &deadlineReader{ReadCloser: r.Body, deadline: time.Now().Add(options.ParseTimeout)}
+	}
+
+	// The parser works on a shallow copy, an abandoned parser must not fill the form
+	// of a request the handler already uses again.
+	parsed := *r
+	done := make(chan parseResult, 1)
+	go func() {
+		value, err := decodedParse(&parsed)
+		done <- parseResult{value: value, err: err}
+	}()
+
+	timer := time.NewTimer(options.ParseTimeout)
+	defer timer.Stop()
+	select {
+	case result := <-done:
+		r.Body = parsed.Body
+		r.Form, r.PostForm, r.MultipartForm = parsed.Form, parsed.PostForm, parsed.MultipartForm
+		return parseResultOf(result.value, result.err)
+	case <-timer.C:
+		return make(RequestValue), &ParseError{Err: ErrParseTimeout}
+	}
+}
+
+// parseResultOf reports failures caused by the body limits as *ParseError.
+func parseResultOf(value RequestValue, err error) (RequestValue, error) {
+	var tooLarge *RequestTooLargeError
+	if errors.Is(err, ErrParseTimeout) || errors.Is(err, ErrDecompressedTooLarge) || errors.As(err, &tooLarge) {
+		if !IsParseError(err) {
+			err = &ParseError{Err: err}
+		}
+		return make(RequestValue), err
+	}
+	return value, err
+}
+
+/*
+Reading the whole body of a request in media types accepts under the body options of opts,
+e.g. WithMaxBodySize, WithParseTimeout and the registered decompressors, for decoders outside the package
+such as protobind. Failures are reported as *ParseError like the parsers do
+*/
+func ReadBody(r *http.Request, accepts []string, opts ...Option) ([]byte, error) {
+	if r.Body == nil {
+		return nil, &ParseError{Err: errors.New("missing request body")}
+	}
+	var data []byte
+	_, err := parseBody(r, requestOptions(r, opts), accepts, func(r *http.Request) (RequestValue, error) {
+		var err error
+		data, err = io.ReadAll(r.Body)
+		return nil, err
+	})
+	if err != nil {
+		if !IsParseError(err) {
+			err = &ParseError{Err: err}
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
+// deadlineReader fails every read issued after the deadline, so the abandoned
+// parser goroutine stops as soon as the client sends its next bytes.
+type deadlineReader struct {
+	io.ReadCloser
+	deadline time.Time
+}
+
+func (d *deadlineReader) Read(p []byte) (int, error) {
+	if time.Now().After(d.deadline) {
+		return 0, ErrParseTimeout
+	}
+	return d.ReadCloser.Read(p)
+}
+
+// maxBodyReader fails with a *RequestTooLargeError once more than max bytes are read.
+type maxBodyReader struct {
+	io.ReadCloser
+	remaining int64
+	max       int64
+}
+
+func (m *maxBodyReader) Read(p []byte) (int, error) {
+	if int64(len(p)) > m.remaining+1 {
+		p = p[:m.remaining+1]
+	}
+	n, err := m.ReadCloser.Read(p)
+	if int64(n) <= m.remaining {
+		m.remaining -= int64(n)
+		return n, err
+	}
+	n = int(m.remaining)
+	m.remaining = 0
+	return n, &RequestTooLargeError{Limit: "body", Max: m.max}
+}
+
+// countValues counts the leaf values of a parsed request.
+func countValues(value interface{}) int {
+	switch v := value.(type) {
+	case RequestValue:
+		count := 0
+		for _, item := range v {
+			count += countValues(item)
+		}
+		return count
+	case []interface{}:
+		count := 0
+		for _, item := range v {
+			count += countValues(item)
+		}
+		return count
+	}
+	return 1
+}
+
+/*
+Rejecting the request from its headers alone, before any body byte is read
+e.g. Content-Length: 1073741824 with a 10MB limit fails with a *RequestTooLargeError matching ErrContentTooLarge
+*/
+func preflight(r *http.Request, options *Options, accepts []string) error {
+	if options.MaxContentLength > 0 && r.ContentLength > options.MaxContentLength {
+		return &ParseError{Err: &RequestTooLargeError{Limit: "content-length", Max: options.MaxContentLength}}
+	}
+	if options.MaxBodySize > 0 && r.ContentLength > options.MaxBodySize {
+		return &ParseError{Err: &RequestTooLargeError{Limit: "body", Max: options.MaxBodySize}}
+	}
+	mediaType := requestMediaType(r)
+	if options.StrictContentType && !mediaTypeAccepted(mediaType, accepts) {
+		return &ParseError{Err: ErrUnsupportedMediaType}
+	}
+	if options.Preflight != nil {
+		if err := options.Preflight(r, mediaType); err != nil {
+			return &ParseError{Err: err}
+		}
+	}
+	return nil
+}
+
+func requestMediaType(r *http.Request) string {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return ""
+	}
+	return mediaType
+}
+
+func mediaTypeAccepted(mediaType string, accepts []string) bool {
+	for _, accept := range accepts {
+		if mediaType == accept {
+			return true
+		}
+		if accept == "application/json" && strings.HasSuffix(mediaType, "+json") {
+			return true
+		}
+		if accept == "application/xml" && strings.HasSuffix(mediaType, "+xml") {
+			return true
+		}
+		if accept == "application/cbor" && strings.HasSuffix(mediaType, "+cbor") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,186 @@
+package inrequest
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTimeout(t *testing.T) {
+	t.Run("should fail with a timeout error when the body trickles", func(t *testing.T) {
+		reader, writer := io.Pipe()
+		defer writer.Close()
+		go writer.Write([]byte(`{"name":`))
+
+		req := httptest.NewRequest(http.MethodPost, "/", reader)
+		start := time.Now()
+		_, err := Json(req, WithParseTimeout(50*time.Millisecond))
+
+		if !IsParseError(err) || !errors.Is(err, ErrParseTimeout) {
+			t.Fatalf("expected parse timeout error, got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Fatalf("expected parse to stop at the deadline, took %v", elapsed)
+		}
+	})
+	t.Run("should leave the request form untouched after a timeout", func(t *testing.T) {
+		reader, writer := io.Pipe()
+		go writer.Write([]byte(`name=John&`))
+
+		req := httptest.NewRequest(http.MethodPost, "/", reader)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if _, err := FormDataE(req, WithParseTimeout(50*time.Millisecond)); !errors.Is(err, ErrParseTimeout) {
+			t.Fatalf("expected parse timeout error, got %v", err)
+		}
+		writer.Write([]byte(`age=31`))
+		writer.Close()
+		time.Sleep(50 * time.Millisecond)
+
+		if req.Form != nil || req.PostForm != nil || req.MultipartForm != nil {
+			t.Fatalf("expected the form to stay unset, got %v", req.PostForm)
+		}
+	})
+	t.Run("should parse normally within the timeout", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"John"}`))
+		result, err := Json(req, WithParseTimeout(time.Second))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.ToMap()["name"] != "John" {
+			t.Fatalf("expected name John, got %v", result.ToMap())
+		}
+	})
+}
+
+type trackingReader struct {
+	io.Reader
+	read bool
+}
+
+func (r *trackingReader) Read(p []byte) (int, error) {
+	r.read = true
+	return r.Reader.Read(p)
+}
+
+func TestPreflight(t *testing.T) {
+	t.Run("should reject an oversized content length without reading the body", func(t *testing.T) {
+		body := &trackingReader{Reader: strings.NewReader(`{"name":"John"}`)}
+		req := httptest.NewRequest(http.MethodPost, "/", body)
+		req.Header.Set("Content-Type", "application/json")
+		req.ContentLength = 10 << 20
+
+		_, err := Json(req, WithMaxContentLength(1<<20))
+		if !errors.Is(err, ErrContentTooLarge) {
+			t.Fatalf("expected content too large error, got %v", err)
+		}
+		var tooLarge *RequestTooLargeError
+		if !errors.As(err, &tooLarge) || tooLarge.Limit != "content-length" || tooLarge.Max != 1<<20 {
+			t.Fatalf("expected a typed content length error, got %#v", err)
+		}
+		if body.read {
+			t.Fatal("expected body to stay unread")
+		}
+	})
+	t.Run("should reject a mismatched content type in strict mode", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"John"}`))
+		req.Header.Set("Content-Type", "text/plain")
+
+		if _, err := Json(req, WithStrictContentType()); !errors.Is(err, ErrUnsupportedMediaType) {
+			t.Fatalf("expected unsupported media type error, got %v", err)
+		}
+	})
+	t.Run("should accept structured json suffixes in strict mode", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"John"}`))
+		req.Header.Set("Content-Type", "application/vnd.api+json; charset=utf-8")
+
+		if _, err := Json(req, WithStrictContentType()); err != nil {
+			t.Fatal(err)
+		}
+	})
+	t.Run("should pass the detected media type to the preflight hook", func(t *testing.T) {
+		denied := errors.New("denied")
+		req := newMultipartRequest(t, map[string]string{"name": "John"}, nil)
+		var seen string
+
+		result := FormData(req, WithPreflight(func(r *http.Request, mediaType string) error {
+			seen = mediaType
+			return denied
+		}))
+		if seen != "multipart/form-data" {
+			t.Fatalf("expected multipart/form-data, got %q", seen)
+		}
+		if len(result.ToMap()) != 0 {
+			t.Fatalf("expected empty result, got %v", result.ToMap())
+		}
+	})
+}
+
+func TestRequestTooLarge(t *testing.T) {
+	t.Run("should fail once the body exceeds the size limit", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"John Doe"}`))
+		req.ContentLength = -1
+
+		_, err := Json(req, WithMaxBodySize(8))
+		var tooLarge *RequestTooLargeError
+		if !IsParseError(err) || !errors.As(err, &tooLarge) || tooLarge.Limit != "body" {
+			t.Fatalf("expected body too large error, got %v", err)
+		}
+	})
+	t.Run("should parse a body of exactly the size limit", func(t *testing.T) {
+		body := `{"name":"John"}`
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		if _, err := Json(req, WithMaxBodySize(int64(len(body)))); err != nil {
+			t.Fatal(err)
+		}
+	})
+	t.Run("should fail when the form holds too many keys", func(t *testing.T) {
+		req := newMultipartRequest(t, map[string]string{"a": "1", "b": "2", "c": "3"}, nil)
+
+		_, err := FormDataE(req, WithMaxKeys(2))
+		var tooLarge *RequestTooLargeError
+		if !errors.As(err, &tooLarge) || tooLarge.Limit != "keys" || tooLarge.Max != 2 {
+			t.Fatalf("expected keys too large error, got %v", err)
+		}
+	})
+	t.Run("should count nested json values against the key limit", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"ids":[1,2,3]}`))
+
+		var tooLarge *RequestTooLargeError
+		if _, err := Json(req, WithMaxKeys(2)); !errors.As(err, &tooLarge) {
+			t.Fatalf("expected keys too large error, got %v", err)
+		}
+	})
+	t.Run("should fail when the query holds too many keys", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?a=1&b=2&c=3", nil)
+
+		request, err := QueryE(req, WithMaxKeys(2))
+		var tooLarge *RequestTooLargeError
+		if !errors.As(err, &tooLarge) || len(request.ToMap()) != 0 {
+			t.Fatalf("expected keys too large error and empty result, got %v %v", err, request.ToMap())
+		}
+	})
+}
+
+func TestReadBody(t *testing.T) {
+	t.Run("should read the whole body", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("payload"))
+		req.Header.Set("Content-Type", "application/x-protobuf")
+		data, err := ReadBody(req, []string{"application/x-protobuf"})
+		if err != nil || string(data) != "payload" {
+			t.Fatalf("expected the body, got %q %v", data, err)
+		}
+	})
+	t.Run("should fail once the body exceeds the size limit", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("payload"))
+		req.ContentLength = -1
+		_, err := ReadBody(req, nil, WithMaxBodySize(4))
+		var tooLarge *RequestTooLargeError
+		if !IsParseError(err) || !errors.As(err, &tooLarge) || tooLarge.Limit != "body" {
+			t.Fatalf("expected body too large error, got %v", err)
+		}
+	})
+}
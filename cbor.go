@@ -0,0 +1,253 @@
+package inrequest
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+)
+
+var cborMediaTypes = []string{"application/cbor"}
+
+var errCborBreak = errors.New("cbor: unexpected break")
+
+/*
+Decoding the cbor body into map, the body must hold a map at its top level
+e.g. {"temp": 21.5, "readings": [1, 2]} encoded as cbor gives the same RequestValue
+as its json counterpart, integers are kept as int and date tags become time.Time
+*/
+func parseCbor(r *http.Request) (RequestValue, error) {
+	decoder := cborDecoder{reader: bufio.NewReader(r.Body)}
+	value, err := decoder.decode(0)
+	if err != nil {
+		return make(RequestValue), err
+	}
+	result, ok := value.(RequestValue)
+	if !ok {
+		return make(RequestValue), fmt.Errorf("cbor: expected a map at top level, got %T", value)
+	}
+	return result, nil
+}
+
+type cborDecoder struct {
+	reader *bufio.Reader
+}
+
+func (d *cborDecoder) decode(depth int) (interface{}, error) {
+	if depth > maxDecodeDepth {
+		return nil, errDecodeDepth
+	}
+	initial, err := d.reader.ReadByte()
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	major, info := initial>>5, initial&0x1f
+
+	if major == 7 {
+		return d.decodeSimple(info)
+	}
+	arg, indefinite, err := d.argument(info)
+	if err != nil {
+		return nil, err
+	}
+	if indefinite && (major < 2 || major > 5) {
+		return nil, fmt.Errorf("cbor: indefinite length not allowed for major type %d", major)
+	}
+
+	switch major {
+	case 0:
+		if arg > math.MaxInt64 {
+			return arg, nil
+		}
+		return int(arg), nil
+	case 1:
+		if arg > math.MaxInt64 {
+			return nil, errors.New("cbor: negative integer overflows int64")
+		}
+		return int(-1 - int64(arg)), nil
+	case 2, 3:
+		data, err := d.decodeBytes(major, arg, indefinite)
+		if err != nil {
+			return nil, err
+		}
+		if major == 3 {
+			return string(data), nil
+		}
+		return data, nil
+	case 4:
+		return d.decodeArray(arg, indefinite, depth)
+	case 5:
+		return d.decodeMap(arg, indefinite, depth)
+	}
+	return d.decodeTag(arg, depth)
+}
+
+// argument reads the argument following the initial byte. Additional info 31 marks an indefinite length.
+func (d *cborDecoder) argument(info byte) (uint64, bool, error) {
+	switch {
+	case info < 24:
+		return uint64(info), false, nil
+	case info <= 27:
+		n, err := d.readUint(1 << (info - 24))
+		return n, false, err
+	case info == 31:
+		return 0, true, nil
+	}
+	return 0, false, fmt.Errorf("cbor: invalid additional info %d", info)
+}
+
+func (d *cborDecoder) decodeSimple(info byte) (interface{}, error) {
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		return nil, nil
+	case 25:
+		bits, err := d.readUint(2)
+		return float16ToFloat64(uint16(bits)), err
+	case 26:
+		bits, err := d.readUint(4)
+		return float64(math.Float32frombits(uint32(bits))), err
+	case 27:
+		bits, err := d.readUint(8)
+		return math.Float64frombits(bits), err
+	case 31:
+		return nil, errCborBreak
+	}
+	return nil, fmt.Errorf("cbor: unsupported simple value %d", info)
+}
+
+func (d *cborDecoder) decodeBytes(major byte, n uint64, indefinite bool) ([]byte, error) {
+	if !indefinite {
+		return d.readBytes(n)
+	}
+	var buf bytes.Buffer
+	for {
+		initial, err := d.reader.ReadByte()
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		if initial == 0xff {
+			return buf.Bytes(), nil
+		}
+		if initial>>5 != major {
+			return nil, errors.New("cbor: invalid chunk in indefinite length string")
+		}
+		size, chunkIndefinite, err := d.argument(initial & 0x1f)
+		if err != nil {
+			return nil, err
+		}
+		if chunkIndefinite {
+			return nil, errors.New("cbor: nested indefinite length string")
+		}
+		chunk, err := d.readBytes(size)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(chunk)
+	}
+}
+
+func (d *cborDecoder) decodeArray(n uint64, indefinite bool, depth int) (interface{}, error) {
+	result := make([]interface{}, 0, minUint(n, 64))
+	for i := uint64(0); indefinite || i < n; i++ {
+		value, err := d.decode(depth + 1)
+		if err == errCborBreak && indefinite {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, value)
+	}
+	return result, nil
+}
+
+func (d *cborDecoder) decodeMap(n uint64, indefinite bool, depth int) (interface{}, error) {
+	result := make(RequestValue, minUint(n, 64))
+	for i := uint64(0); indefinite || i < n; i++ {
+		key, err := d.decode(depth + 1)
+		if err == errCborBreak && indefinite {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		value, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		result[mapKeyString(key)] = value
+	}
+	return result, nil
+}
+
+// decodeTag unwraps tagged values. Tags 0 (RFC 3339 date) and 1 (epoch date) become time.Time.
+func (d *cborDecoder) decodeTag(tag uint64, depth int) (interface{}, error) {
+	value, err := d.decode(depth + 1)
+	if err != nil {
+		return nil, err
+	}
+	switch tag {
+	case 0:
+		if text, ok := value.(string); ok {
+			return time.Parse(time.RFC3339Nano, text)
+		}
+		return nil, errors.New("cbor: date tag expects a text string")
+	case 1:
+		switch epoch := value.(type) {
+		case int:
+			return time.Unix(int64(epoch), 0).UTC(), nil
+		case float64:
+			sec, frac := math.Modf(epoch)
+			return time.Unix(int64(sec), int64(frac*1e9)).UTC(), nil
+		}
+		return nil, errors.New("cbor: epoch tag expects a number")
+	}
+	return value, nil
+}
+
+func (d *cborDecoder) readUint(size int) (uint64, error) {
+	return readUint(d.reader, size)
+}
+
+func (d *cborDecoder) readBytes(n uint64) ([]byte, error) {
+	if n > math.MaxInt64 {
+		return nil, errors.New("cbor: length overflows int64")
+	}
+	return readBytes(d.reader, int64(n))
+}
+
+func float16ToFloat64(bits uint16) float64 {
+	exponent := int(bits>>10) & 0x1f
+	mantissa := float64(bits & 0x3ff)
+	var value float64
+	switch exponent {
+	case 0:
+		value = math.Ldexp(mantissa, -24)
+	case 31:
+		if mantissa == 0 {
+			value = math.Inf(1)
+		} else {
+			value = math.NaN()
+		}
+	default:
+		value = math.Ldexp(mantissa+1024, exponent-25)
+	}
+	if bits&0x8000 != 0 {
+		return -value
+	}
+	return value
+}
+
+func minUint(a, b uint64) int {
+	if a < b {
+		return int(a)
+	}
+	return int(b)
+}
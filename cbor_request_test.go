@@ -0,0 +1,112 @@
+package inrequest
+
+import (
+	"bytes"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCbor(t *testing.T) {
+	// {"device":"th-01","temp":21.5,"offset":-3,"ok":true,"readings":[1,2,3](indefinite),"meta":{"fw":"1.2"},"at":1(1700000000),"half":1.5f16}
+	body := []byte{
+		0xa8,
+		0x66, 'd', 'e', 'v', 'i', 'c', 'e', 0x65, 't', 'h', '-', '0', '1',
+		0x64, 't', 'e', 'm', 'p', 0xfb, 0x40, 0x35, 0x80, 0, 0, 0, 0, 0,
+		0x66, 'o', 'f', 'f', 's', 'e', 't', 0x22,
+		0x62, 'o', 'k', 0xf5,
+		0x68, 'r', 'e', 'a', 'd', 'i', 'n', 'g', 's', 0x9f, 0x01, 0x02, 0x03, 0xff,
+		0x64, 'm', 'e', 't', 'a', 0xa1, 0x62, 'f', 'w', 0x7f, 0x61, '1', 0x62, '.', '2', 0xff,
+		0x62, 'a', 't', 0xc1, 0x1a, 0x65, 0x53, 0xf1, 0x00,
+		0x64, 'h', 'a', 'l', 'f', 0xf9, 0x3e, 0x00,
+	}
+
+	t.Run("should decode cbor into map", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/cbor")
+		result, err := Cbor(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		target := RequestValue{
+			"device":   "th-01",
+			"temp":     21.5,
+			"offset":   -3,
+			"ok":       true,
+			"readings": []interface{}{1, 2, 3},
+			"meta":     RequestValue{"fw": "1.2"},
+			"at":       time.Unix(1700000000, 0).UTC(),
+			"half":     1.5,
+		}
+		if !reflect.DeepEqual(result.ToMap(), target) {
+			t.Fatalf("Failed decoding cbor, expected %v, got %v", target, result.ToMap())
+		}
+	})
+	t.Run("should bind through Parse", func(t *testing.T) {
+		type Reading struct {
+			Device   string  `json:"device"`
+			Temp     float64 `json:"temp"`
+			Readings []int   `json:"readings"`
+			Meta     struct {
+				Firmware string `json:"fw"`
+			} `json:"meta"`
+		}
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/cbor")
+		result, err := Parse(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reading := Reading{}
+		if err = result.ToBind(&reading); err != nil {
+			t.Fatal(err)
+		}
+		if reading.Device != "th-01" || reading.Temp != 21.5 || len(reading.Readings) != 3 || reading.Meta.Firmware != "1.2" {
+			t.Fatalf("unexpected reading %+v", reading)
+		}
+	})
+	t.Run("should bind a tagged date into a time field", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/cbor")
+		result, err := Cbor(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reading := struct {
+			At time.Time `json:"at"`
+		}{}
+		if err = result.ToBind(&reading); err != nil {
+			t.Fatal(err)
+		}
+		if !reading.At.Equal(time.Unix(1700000000, 0)) {
+			t.Fatalf("expected at %v, got %v", time.Unix(1700000000, 0), reading.At)
+		}
+	})
+	t.Run("should reject malformed input", func(t *testing.T) {
+		for _, payload := range [][]byte{body[:30], {0xa1, 0x61, 'a', 0xff}, {0x82, 0x01}, {0x01}} {
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(payload))
+			if _, err := Cbor(req); err == nil {
+				t.Fatalf("expected error for % x", payload)
+			}
+		}
+	})
+}
+
+func TestFloat16ToFloat64(t *testing.T) {
+	cases := map[uint16]float64{
+		0x0000: 0,
+		0x3c00: 1,
+		0xc000: -2,
+		0x7bff: 65504,
+		0x0001: 5.960464477539063e-8,
+		0x7c00: math.Inf(1),
+	}
+	for bits, target := range cases {
+		if value := float16ToFloat64(bits); value != target {
+			t.Fatalf("half float %#04x expected %v, got %v", bits, target, value)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package inrequest
+
+type CookieRequest struct {
+	requestValues
+	options *Options
+}
+
+func (r CookieRequest) ToMap() RequestValue {
+	r.invalidate()
+	return r.result
+}
+
+// ToBind binds the cookies into fields tagged `cookie:"session_id"`.
+func (r CookieRequest) ToBind(model interface{}) error {
+	return bindHooked(r.options, r.result, model, newBinder("cookie").with(r.options).bind)
+}
+
+// ToBindValidated binds like ToBind, then runs the validator set with SetValidator.
+// Bind and validation failures are returned together as ValidationErrors.
+func (r CookieRequest) ToBindValidated(model interface{}) error {
+	return bindValidated(r, model)
+}
+
+func (r CookieRequest) ToJsonByte() ([]byte, error) {
+	jsonData, err := r.encode(r.result)
+	if err != nil {
+		return []byte{}, err
+	}
+	return append([]byte(nil), jsonData...), nil
+}
+
+func (r CookieRequest) ToJsonString() (string, error) {
+	jsonData, err := r.encode(r.result)
+	if err != nil {
+		return "", err
+	}
+	return string(jsonData), nil
+}
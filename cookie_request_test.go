@@ -0,0 +1,41 @@
+package inrequest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestCookies(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: "session_id", Value: "abc123"})
+	req.AddCookie(&http.Cookie{Name: "visits", Value: "12"})
+	req.AddCookie(&http.Cookie{Name: "flags", Value: "beta"})
+	req.AddCookie(&http.Cookie{Name: "flags", Value: "dark"})
+
+	t.Run("should map cookies like query values", func(t *testing.T) {
+		target := RequestValue{
+			"session_id": "abc123",
+			"visits":     12,
+			"flags":      []interface{}{"beta", "dark"},
+		}
+		if result := Cookies(req).ToMap(); !reflect.DeepEqual(result, target) {
+			t.Fatalf("Failed mapping cookies, expected %v, got %v", target, result)
+		}
+	})
+	t.Run("should bind cookies through the cookie tag", func(t *testing.T) {
+		type Session struct {
+			ID     string   `cookie:"session_id"`
+			Visits int      `cookie:"visits"`
+			Flags  []string `cookie:"flags"`
+		}
+		session := Session{}
+		if err := Cookies(req).ToBind(&session); err != nil {
+			t.Fatal(err)
+		}
+		if session.ID != "abc123" || session.Visits != 12 || !reflect.DeepEqual(session.Flags, []string{"beta", "dark"}) {
+			t.Fatalf("unexpected session %+v", session)
+		}
+	})
+}
@@ -0,0 +1,133 @@
+package inrequest
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var csvMediaTypes = []string{"text/csv", "application/csv"}
+
+type CsvRequest struct {
+	rows    []RequestValue
+	options *Options
+}
+
+func Csv(r *http.Request, opts ...Option) (CsvRequest, error) {
+	options := requestOptions(r, opts)
+	var rows []RequestValue
+	_, err := parseBody(r, options, csvMediaTypes, func(r *http.Request) (RequestValue, error) {
+		var err error
+		rows, err = parseCsv(r.Body, options)
+		return nil, err
+	})
+	if err != nil {
+		return CsvRequest{}, err
+	}
+	return CsvRequest{rows: rows, options: options}, nil
+}
+
+/*
+Reading csv records into one map per row
+e.g. with header row "name,age" the record "John,31" becomes {"name": "John", "age": 31},
+without header row the columns are keyed by their index "0", "1", ...
+*/
+func parseCsv(body io.Reader, options *Options) ([]RequestValue, error) {
+	reader := csv.NewReader(body)
+	if options.CsvDelimiter != 0 {
+		reader.Comma = options.CsvDelimiter
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var header []string
+	if !options.CsvNoHeader {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return []RequestValue{}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		for i, name := range record {
+			if i == 0 {
+				name = strings.TrimPrefix(name, "\ufeff")
+			}
+			header = append(header, strings.TrimSpace(name))
+		}
+	}
+
+	rows := []RequestValue{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		row := make(RequestValue, len(record))
+		for i, value := range record {
+			key := strconv.Itoa(i)
+			if i < len(header) {
+				key = header[i]
+			}
+			row[key] = actualTypeOf(value)
+		}
+		rows = append(rows, row)
+	}
+}
+
+func (r CsvRequest) Rows() []RequestValue {
+	return r.rows
+}
+
+// BindRows binds every row into an element of the slice model points to, e.g. *[]User.
+func (r CsvRequest) BindRows(model interface{}) error {
+	dst := reflect.ValueOf(model)
+	if dst.Kind() != reflect.Ptr || dst.IsNil() || dst.Elem().Kind() != reflect.Slice {
+		return &BindError{Err: errors.New("model must be a non-nil pointer to a slice")}
+	}
+	rows := make([]interface{}, len(r.rows))
+	for i, row := range r.rows {
+		rows[i] = row
+	}
+	b := binderOf(r.options)
+	return b.result(b.bindValue(dst.Elem(), rows, ""))
+}
+
+func (r CsvRequest) ToJsonByte() ([]byte, error) {
+	jsonData, err := json.Marshal(r.rows)
+	if err != nil {
+		return []byte{}, err
+	}
+	return jsonData, nil
+}
+
+func (r CsvRequest) ToJsonString() (string, error) {
+	jsonData, err := json.Marshal(r.rows)
+	if err != nil {
+		return "", err
+	}
+	return string(jsonData), nil
+}
+
+// MarshalJSON encodes the rows, so json.NewEncoder(w).Encode(req) writes them instead of an empty object.
+func (r CsvRequest) MarshalJSON() ([]byte, error) {
+	return r.ToJsonByte()
+}
+
+// String returns the rows as JSON for debugging.
+func (r CsvRequest) String() string {
+	jsonData, err := json.Marshal(r.rows)
+	if err != nil {
+		return fmt.Sprint(r.rows)
+	}
+	return string(jsonData)
+}
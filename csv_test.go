@@ -0,0 +1,67 @@
+package inrequest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCsv(t *testing.T) {
+	t.Run("should read rows keyed by the header row", func(t *testing.T) {
+		body := "\ufeffname,age,zip\nJohn,31,01234\nJane,28,10001\n"
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set("Content-Type", "text/csv")
+		result, err := Csv(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		target := []RequestValue{
+			{"name": "John", "age": 31, "zip": "01234"},
+			{"name": "Jane", "age": 28, "zip": 10001},
+		}
+		if !reflect.DeepEqual(result.Rows(), target) {
+			t.Fatalf("Failed reading csv, expected %v, got %v", target, result.Rows())
+		}
+	})
+	t.Run("should honor delimiter and missing header", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("John;31\nJane;28\n"))
+		result, err := Csv(req, WithCsvDelimiter(';'), WithoutCsvHeader())
+		if err != nil {
+			t.Fatal(err)
+		}
+		target := []RequestValue{{"0": "John", "1": 31}, {"0": "Jane", "1": 28}}
+		if !reflect.DeepEqual(result.Rows(), target) {
+			t.Fatalf("Failed reading csv, expected %v, got %v", target, result.Rows())
+		}
+	})
+	t.Run("should bind rows into a slice of structs", func(t *testing.T) {
+		type User struct {
+			Name string `json:"name"`
+			Age  int    `json:"age"`
+			Zip  string `json:"zip"`
+		}
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("name,age,zip\nJohn,31,01234\nJane,x,10001\n"))
+		result, err := Csv(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var users []User
+		err = result.BindRows(&users)
+		var bindErr *BindError
+		if !errors.As(err, &bindErr) || bindErr.Field != "1.age" {
+			t.Fatalf("expected bind error on 1.age, got %v", err)
+		}
+
+		req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader("name,age,zip\nJohn,31,01234\n"))
+		result, _ = Csv(req)
+		if err = result.BindRows(&users); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(users, []User{{Name: "John", Age: 31, Zip: "01234"}}) {
+			t.Fatalf("unexpected users %+v", users)
+		}
+	})
+}
@@ -0,0 +1,85 @@
+package inrequest
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+Writing the parsed values as an indented tree with the type of every value, for debugging nested submissions
+e.g. {"user": {"name": "John", "password": "secret"}} dumped with Dump(w, "user.password") gives
+
+	user: map[2]
+	  name: string "John"
+	  password: ***
+
+Redacted paths are written like Except takes them, array indexes are ignored so "items.token" hides the token of every item.
+The keys set with WithRedactedKeys are always redacted.
+Uploaded files are listed with their filename, content type and size
+*/
+func (v requestValues) Dump(w io.Writer, redact ...string) error {
+	redacted := make(map[string]bool, len(v.redact)+len(redact))
+	for path := range v.redact {
+		redacted[path] = true
+	}
+	for _, path := range redact {
+		redacted[fieldPath(path)] = true
+	}
+	var b strings.Builder
+	dumpMap(&b, v.result, "", 0, redacted)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// DebugString returns the tree written by Dump.
+func (v requestValues) DebugString(redact ...string) string {
+	var b strings.Builder
+	v.Dump(&b, redact...)
+	return b.String()
+}
+
+func dumpMap(b *strings.Builder, values RequestValue, path string, depth int, redacted map[string]bool) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		dumpValue(b, key, values[key], joinPath(path, key), depth, redacted)
+	}
+}
+
+func dumpValue(b *strings.Builder, key string, value interface{}, path string, depth int, redacted map[string]bool) {
+	b.WriteString(strings.Repeat("  ", depth))
+	b.WriteString(key)
+	b.WriteString(": ")
+	if redacted[withoutIndexes(path)] {
+		b.WriteString(redactedValue + "\n")
+		return
+	}
+	switch v := value.(type) {
+	case RequestValue:
+		fmt.Fprintf(b, "map[%d]\n", len(v))
+		dumpMap(b, v, path, depth+1, redacted)
+	case []interface{}:
+		fmt.Fprintf(b, "array[%d]\n", len(v))
+		for i, item := range v {
+			dumpValue(b, strconv.Itoa(i), item, joinPath(path, strconv.Itoa(i)), depth+1, redacted)
+		}
+	case *multipart.FileHeader:
+		fmt.Fprintf(b, "file %q %s %d bytes\n", v.Filename, v.Header.Get("Content-Type"), v.Size)
+	case nil:
+		b.WriteString("null\n")
+	case string:
+		fmt.Fprintf(b, "string %q\n", v)
+	case time.Time:
+		fmt.Fprintf(b, "time %s\n", v.Format(time.RFC3339Nano))
+	default:
+		fmt.Fprintf(b, "%T %v\n", v, v)
+	}
+}
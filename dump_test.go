@@ -0,0 +1,48 @@
+package inrequest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDump(t *testing.T) {
+	body := `{"user":{"name":"John","password":"secret"},"items":[{"price":10.5,"token":"abc"}],"note":null}`
+	req, err := Json(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should render the values as a tree with their types", func(t *testing.T) {
+		expected := strings.Join([]string{
+			"items: array[1]",
+			"  0: map[2]",
+			"    price: float64 10.5",
+			`    token: string "abc"`,
+			"note: null",
+			"user: map[2]",
+			`  name: string "John"`,
+			`  password: string "secret"`,
+			"",
+		}, "\n")
+		if s := req.DebugString(); s != expected {
+			t.Fatalf("expected\n%s\ngot\n%s", expected, s)
+		}
+	})
+	t.Run("should redact the given paths", func(t *testing.T) {
+		s := req.DebugString("user.password", "items[][token]")
+		if strings.Contains(s, "secret") || strings.Contains(s, "abc") || strings.Count(s, "***") != 2 {
+			t.Fatalf("expected the password and tokens redacted, got\n%s", s)
+		}
+	})
+	t.Run("should list uploaded files", func(t *testing.T) {
+		form, err := FormDataE(newUploadRequest(t, "avatar", "me.png", "\x89PNG\r\n\x1a\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := form.DebugString(); !strings.Contains(s, `avatar: file "me.png"`) || !strings.Contains(s, "8 bytes") {
+			t.Fatalf("expected the file metadata, got\n%s", s)
+		}
+	})
+}
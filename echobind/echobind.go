@@ -0,0 +1,46 @@
+// Package echobind plugs inrequest parsing into Echo, so c.Bind reads nested bracket keys and uploaded files.
+package echobind
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ezartsh/inrequest"
+	"github.com/labstack/echo/v4"
+)
+
+// Binder implements echo.Binder with inrequest.Parse, install it with e.Binder = echobind.New().
+type Binder struct {
+	opts []inrequest.Option
+}
+
+// New returns a Binder parsing under opts, e.g. New(inrequest.WithMaxMemory(8 << 20)).
+func New(opts ...inrequest.Option) *Binder {
+	return &Binder{opts: opts}
+}
+
+/*
+Binding the request into i by its Content-Type, a request without body binds its query string
+e.g. "user[name]=John&photos[0]=@file" fills
+
+	type Profile struct {
+		User   struct{ Name string `form:"name"` } `form:"user"`
+		Photos []*multipart.FileHeader `form:"photos"`
+	}
+
+Path parameters are left to c.Param. Failures are returned as *echo.HTTPError, 415 for an unsupported Content-Type and 400 otherwise
+*/
+func (b *Binder) Bind(i interface{}, c echo.Context) error {
+	request, err := inrequest.Parse(c.Request(), b.opts...)
+	if err == nil {
+		err = request.ToBind(i)
+	}
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, inrequest.ErrUnsupportedMediaType) {
+			status = http.StatusUnsupportedMediaType
+		}
+		return echo.NewHTTPError(status, err.Error()).SetInternal(err)
+	}
+	return nil
+}
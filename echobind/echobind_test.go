@@ -0,0 +1,44 @@
+package echobind
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestBinder(t *testing.T) {
+	e := echo.New()
+	e.Binder = New()
+
+	t.Run("should bind nested bracket keys", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("user[name]=John&tags[0]=a&tags[1]=b"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		c := e.NewContext(req, httptest.NewRecorder())
+		var profile struct {
+			User struct {
+				Name string `form:"name"`
+			} `form:"user"`
+			Tags []string `form:"tags"`
+		}
+		if err := c.Bind(&profile); err != nil {
+			t.Fatal(err)
+		}
+		if profile.User.Name != "John" || len(profile.Tags) != 2 {
+			t.Fatalf("unexpected profile %+v", profile)
+		}
+	})
+	t.Run("should report failures as http errors", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("name"))
+		req.Header.Set("Content-Type", "text/plain")
+		c := e.NewContext(req, httptest.NewRecorder())
+		var dst struct{}
+		var httpErr *echo.HTTPError
+		if err := c.Bind(&dst); !errors.As(err, &httpErr) || httpErr.Code != http.StatusUnsupportedMediaType {
+			t.Fatalf("expected a 415 http error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,124 @@
+package inrequest
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// DefaultMaxDecompressedSize caps decompressed bodies when no other limit is configured.
+const DefaultMaxDecompressedSize = 32 << 20
+
+// Decompressor wraps a compressed body into a reader of its decompressed content.
+type Decompressor func(body io.Reader) (io.Reader, error)
+
+var (
+	decompressorsMu sync.RWMutex
+	decompressors   = map[string]Decompressor{
+		"gzip":    decompressGzip,
+		"x-gzip":  decompressGzip,
+		"deflate": decompressDeflate,
+	}
+)
+
+// RegisterDecompressor adds support for a Content-Encoding, e.g. "br" backed by a brotli reader:
+//
+//	inrequest.RegisterDecompressor("br", func(body io.Reader) (io.Reader, error) {
+//		return brotli.NewReader(body), nil
+//	})
+func RegisterDecompressor(encoding string, fn Decompressor) {
+	decompressorsMu.Lock()
+	defer decompressorsMu.Unlock()
+	decompressors[strings.ToLower(encoding)] = fn
+}
+
+func decompressorFor(encoding string) (Decompressor, bool) {
+	decompressorsMu.RLock()
+	defer decompressorsMu.RUnlock()
+	fn, ok := decompressors[encoding]
+	return fn, ok
+}
+
+func decompressGzip(body io.Reader) (io.Reader, error) {
+	return gzip.NewReader(body)
+}
+
+// decompressDeflate accepts zlib wrapped data as required by HTTP and raw deflate streams sent by some clients.
+func decompressDeflate(body io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(body)
+	header, err := buffered.Peek(2)
+	if err == nil && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(buffered)
+	}
+	return flate.NewReader(buffered), nil
+}
+
+/*
+Replacing the body with its decompressed content according to Content-Encoding
+e.g. "Content-Encoding: gzip" is removed from the request and the body is read through gzip,
+encodings listed together such as "deflate, gzip" are undone from last to first
+*/
+func decompressBody(r *http.Request, options *Options) error {
+	header := r.Header.Get("Content-Encoding")
+	if header == "" || r.Body == nil {
+		return nil
+	}
+	encodings := strings.Split(header, ",")
+	var body io.Reader = r.Body
+	for i := len(encodings) - 1; i >= 0; i-- {
+		encoding := strings.ToLower(strings.TrimSpace(encodings[i]))
+		if encoding == "" || encoding == "identity" {
+			continue
+		}
+		fn, ok := decompressorFor(encoding)
+		if !ok {
+			return &ParseError{Err: ErrUnsupportedEncoding}
+		}
+		reader, err := fn(body)
+		if err != nil {
+			return &ParseError{Err: err}
+		}
+		body = reader
+	}
+
+	limit := options.MaxDecompressedSize
+	if limit == 0 {
+		limit = DefaultMaxDecompressedSize
+	}
+	if limit > 0 {
+		body = &decompressedLimitReader{reader: body, remaining: limit}
+	}
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{body, r.Body}
+	r.Header.Del("Content-Encoding")
+	r.ContentLength = -1
+	return nil
+}
+
+// decompressedLimitReader fails with ErrDecompressedTooLarge once more than the limit has been produced.
+type decompressedLimitReader struct {
+	reader    io.Reader
+	remaining int64
+}
+
+func (l *decompressedLimitReader) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, ErrDecompressedTooLarge
+	}
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.reader.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n, ErrDecompressedTooLarge
+	}
+	return n, err
+}
@@ -0,0 +1,93 @@
+package inrequest
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func compressedRequest(t *testing.T, encoding string, body []byte) *http.Request {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	var writer io.WriteCloser
+	switch encoding {
+	case "gzip":
+		writer = gzip.NewWriter(buf)
+	case "deflate":
+		writer = zlib.NewWriter(buf)
+	case "raw-deflate":
+		writer, _ = flate.NewWriter(buf, flate.DefaultCompression)
+		encoding = "deflate"
+	}
+	writer.Write(body)
+	writer.Close()
+	req := httptest.NewRequest(http.MethodPost, "/", buf)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", encoding)
+	return req
+}
+
+func TestContentEncoding(t *testing.T) {
+	t.Run("should decompress gzip and deflate bodies", func(t *testing.T) {
+		for _, encoding := range []string{"gzip", "deflate", "raw-deflate"} {
+			result, err := Json(compressedRequest(t, encoding, []byte(`{"name":"John"}`)))
+			if err != nil {
+				t.Fatalf("%s: %v", encoding, err)
+			}
+			if result.ToMap()["name"] != "John" {
+				t.Fatalf("%s: unexpected result %v", encoding, result.ToMap())
+			}
+		}
+	})
+	t.Run("should decompress form bodies through Parse", func(t *testing.T) {
+		req := compressedRequest(t, "gzip", []byte("name=John&tags[]=a"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		result, err := Parse(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.ToMap()["name"] != "John" {
+			t.Fatalf("unexpected result %v", result.ToMap())
+		}
+	})
+	t.Run("should stop bodies expanding beyond the limit", func(t *testing.T) {
+		body := `{"data":"` + strings.Repeat("a", 1<<20) + `"}`
+		_, err := Json(compressedRequest(t, "gzip", []byte(body)), WithMaxDecompressedSize(1024))
+		if !IsParseError(err) || !errors.Is(err, ErrDecompressedTooLarge) {
+			t.Fatalf("expected decompressed too large error, got %v", err)
+		}
+	})
+	t.Run("should reject unknown encodings and accept registered ones", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"John"}`))
+		req.Header.Set("Content-Encoding", "reverse")
+		if _, err := Json(req); !errors.Is(err, ErrUnsupportedEncoding) {
+			t.Fatalf("expected unsupported encoding error, got %v", err)
+		}
+
+		RegisterDecompressor("reverse", func(body io.Reader) (io.Reader, error) {
+			data, err := io.ReadAll(body)
+			for i, j := 0, len(data)-1; i < j; i, j = i+1, j-1 {
+				data[i], data[j] = data[j], data[i]
+			}
+			return bytes.NewReader(data), err
+		})
+		defer func() {
+			decompressorsMu.Lock()
+			delete(decompressors, "reverse")
+			decompressorsMu.Unlock()
+		}()
+		req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`}"nhoJ":"eman"{`))
+		req.Header.Set("Content-Encoding", "reverse")
+		result, err := Json(req)
+		if err != nil || result.ToMap()["name"] != "John" {
+			t.Fatalf("expected registered decompressor to run, got %v %v", result.ToMap(), err)
+		}
+	})
+}
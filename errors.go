@@ -0,0 +1,231 @@
+package inrequest
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrParseTimeout is reported when reading the body exceeds the configured parse timeout.
+	ErrParseTimeout = errors.New("parse timeout exceeded")
+	// ErrContentTooLarge is reported when the declared Content-Length exceeds the configured maximum.
+	ErrContentTooLarge = errors.New("declared content length too large")
+	// ErrUnsupportedMediaType is reported when the Content-Type does not match the entry point.
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+	// ErrUnsupportedEncoding is reported for a Content-Encoding without a registered decompressor.
+	ErrUnsupportedEncoding = errors.New("unsupported content encoding")
+	// ErrDecompressedTooLarge is reported when a compressed body expands beyond the configured maximum.
+	ErrDecompressedTooLarge = errors.New("decompressed body too large")
+
+	// ErrArrayIndexTooLarge is reported for a form key indexing past the configured maximum array index.
+	ErrArrayIndexTooLarge = errors.New("array index too large")
+	// ErrMalformedKey is reported for a form or query key WithStrictKeys rejects, e.g. "a[b".
+	ErrMalformedKey = errors.New("malformed key")
+	// ErrUnrepresentableNumber is reported for a number WithNumberPolicy(RejectNumber) rejects, e.g. "1e10".
+	ErrUnrepresentableNumber = errors.New("number past int64 or in exponent notation")
+	// ErrTooManyFiles is reported for a multipart field holding more files than WithMaxFiles allows.
+	ErrTooManyFiles = errors.New("too many files")
+	// ErrUnknownField is reported for request keys matching no field when unknown fields are disallowed.
+	ErrUnknownField = errors.New("unknown field")
+	// ErrUnknownVariant is reported for a union field whose discriminator names no registered variant, see RegisterUnion.
+	ErrUnknownVariant = errors.New("unknown union variant")
+
+	// ErrParse matches every *ParseError with errors.Is, e.g. errors.Is(err, ErrParse).
+	ErrParse = errors.New("inrequest: parse request")
+	// ErrBind matches every *BindError and BindErrors with errors.Is, e.g. errors.Is(err, ErrBind).
+	ErrBind = errors.New("inrequest: bind")
+)
+
+// ParseError is returned when the request body cannot be read or decoded.
+type ParseError struct {
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	return "inrequest: parse request: " + e.Err.Error()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+func (e *ParseError) Is(target error) bool {
+	return target == ErrParse
+}
+
+// IsParseError reports whether err is or wraps a *ParseError.
+func IsParseError(err error) bool {
+	var parseErr *ParseError
+	return errors.As(err, &parseErr)
+}
+
+// BindError is returned when a parsed value cannot be bound into the model.
+// Field holds the dot path of the offending value, e.g. "items.0.price".
+type BindError struct {
+	Field string
+	Err   error
+}
+
+func (e *BindError) Error() string {
+	if e.Field == "" {
+		return "inrequest: bind: " + e.Err.Error()
+	}
+	return "inrequest: bind field \"" + e.Field + "\": " + e.Err.Error()
+}
+
+func (e *BindError) Unwrap() error {
+	return e.Err
+}
+
+func (e *BindError) Is(target error) bool {
+	return target == ErrBind
+}
+
+// IsBindError reports whether err is or wraps a *BindError or BindErrors.
+func IsBindError(err error) bool {
+	var bindErr *BindError
+	return errors.As(err, &bindErr)
+}
+
+// RequestTooLargeError is returned when the request exceeds a size limit, handlers usually answer it with
+// 413 Request Entity Too Large. Limit names the exceeded setting, "content-length" for WithMaxContentLength,
+// "body" for WithMaxBodySize, "keys" for WithMaxKeys or "memory" for the memory cap of WithInMemoryUploads.
+type RequestTooLargeError struct {
+	Limit string
+	Max   int64
+}
+
+func (e *RequestTooLargeError) Error() string {
+	switch e.Limit {
+	case "content-length":
+		return fmt.Sprintf("declared content length exceeds %d bytes", e.Max)
+	case "keys":
+		return fmt.Sprintf("request has more than %d keys", e.Max)
+	case "memory":
+		return fmt.Sprintf("uploads exceed %d bytes of memory", e.Max)
+	}
+	return fmt.Sprintf("request body exceeds %d bytes", e.Max)
+}
+
+// Is matches ErrContentTooLarge for a declared Content-Length over WithMaxContentLength.
+func (e *RequestTooLargeError) Is(target error) bool {
+	return target == ErrContentTooLarge && e.Limit == "content-length"
+}
+
+// FileTooLargeError is returned when an uploaded file exceeds WithMaxFileSize, or WithMaxTotalUploadSize
+// when Total is set. Field is the form field of the file and Size the bytes read before parsing stopped,
+// which is at most one past Max.
+type FileTooLargeError struct {
+	Field string
+	Size  int64
+	Max   int64
+	Total bool
+}
+
+func (e *FileTooLargeError) Error() string {
+	if e.Total {
+		return fmt.Sprintf("uploads exceed %d bytes at file %q", e.Max, e.Field)
+	}
+	return fmt.Sprintf("file %q exceeds %d bytes", e.Field, e.Max)
+}
+
+// FileTypeError is returned when an uploaded file is of a type the upload policy of its field rejects.
+// Type is the sniffed content type, or the extension for WithAllowedExtensions.
+type FileTypeError struct {
+	Field    string
+	Filename string
+	Type     string
+}
+
+func (e *FileTypeError) Error() string {
+	return fmt.Sprintf("file %q of field %q has disallowed type %q", e.Filename, e.Field, e.Type)
+}
+
+// FileRejectedError is returned when the scanner set with WithFileScanner rejects an uploaded file, Err is its reason.
+type FileRejectedError struct {
+	Field    string
+	Filename string
+	Err      error
+}
+
+func (e *FileRejectedError) Error() string {
+	return fmt.Sprintf("file %q of field %q rejected: %v", e.Filename, e.Field, e.Err)
+}
+
+func (e *FileRejectedError) Unwrap() error {
+	return e.Err
+}
+
+// FieldError describes why a single field was rejected.
+// Tag names the failed validation rule, e.g. "required", and is empty when the value could not be bound.
+type FieldError struct {
+	Field string
+	Tag   string
+	Param string
+	Err   error
+}
+
+func (e FieldError) Error() string {
+	if e.Field == "" {
+		return e.Err.Error()
+	}
+	return e.Field + ": " + e.Err.Error()
+}
+
+func (e FieldError) Unwrap() error {
+	return e.Err
+}
+
+// BindErrors lists every field ToBind could not fill or whose constraint tags it breaks.
+type BindErrors []FieldError
+
+func (e BindErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, field := range e {
+		messages[i] = field.Error()
+	}
+	return "inrequest: bind: " + strings.Join(messages, "; ")
+}
+
+// Fields returns the rejected fields in the order they were found.
+func (e BindErrors) Fields() []FieldError {
+	return e
+}
+
+func (e BindErrors) Is(target error) bool {
+	return target == ErrBind
+}
+
+// As lets errors.As match the first failure as a *BindError, or the first field error matching target.
+func (e BindErrors) As(target interface{}) bool {
+	if len(e) == 0 {
+		return false
+	}
+	if bindErr, ok := target.(**BindError); ok {
+		*bindErr = &BindError{Field: e[0].Field, Err: e[0].Err}
+		return true
+	}
+	for _, field := range e {
+		if errors.As(field.Err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidationErrors lists every field rejected by ToBindValidated.
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, field := range e {
+		messages[i] = field.Error()
+	}
+	return "inrequest: validate: " + strings.Join(messages, "; ")
+}
+
+// Fields returns the rejected fields in the order they were found.
+func (e ValidationErrors) Fields() []FieldError {
+	return e
+}
@@ -0,0 +1,38 @@
+package inrequest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWrappedErrors(t *testing.T) {
+	t.Run("should detect wrapped parse errors", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("a=1"))
+		req.Header.Set("Content-Type", "text/plain")
+		_, err := Parse(req)
+		err = fmt.Errorf("middleware: %w", err)
+		if !IsParseError(err) || !errors.Is(err, ErrParse) || errors.Is(err, ErrBind) {
+			t.Fatalf("expected a wrapped parse error, got %v", err)
+		}
+	})
+	t.Run("should detect wrapped bind errors", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?age=old", nil)
+		model := struct {
+			Age int `json:"age"`
+		}{}
+		err := fmt.Errorf("middleware: %w", Query(req).ToBind(&model))
+		if !IsBindError(err) || !errors.Is(err, ErrBind) || errors.Is(err, ErrParse) {
+			t.Fatalf("expected a wrapped bind error, got %v", err)
+		}
+	})
+	t.Run("should not match unrelated errors", func(t *testing.T) {
+		err := errors.New("boom")
+		if IsParseError(err) || IsBindError(err) || errors.Is(err, ErrParse) || errors.Is(err, ErrBind) {
+			t.Fatalf("unexpected match for %v", err)
+		}
+	})
+}
@@ -1,38 +1,176 @@
 package inrequest
 
-import "encoding/json"
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"sync"
+)
 
-type formRequest struct {
-	result RequestValue
+type FormRequest struct {
+	requestValues
+	options *Options
+	parts   *formParts
+	files   *formFiles
 }
 
-func (r formRequest) ToMap() RequestValue {
-	return r.result
+// formFiles removes the temporary files of a multipart form once, shared by every copy of the request.
+type formFiles struct {
+	once sync.Once
+	form *multipart.Form
+	err  error
 }
 
-func (r formRequest) ToBind(model interface{}) error {
-	jsonData, err := json.Marshal(r.result)
-	if err != nil {
-		return err
+// Cleanup removes the temporary files the uploads of a multipart body were stored in, so they do not
+// wait for the server to finish the request. Files can no longer be opened afterwards.
+// Calling it again, or from several goroutines, returns the result of the first call.
+func (r FormRequest) Cleanup() error {
+	if r.files == nil {
+		return nil
 	}
-	if err = json.Unmarshal(jsonData, &model); err != nil {
-		return err
-	}
-	return nil
+	r.files.once.Do(func() {
+		r.files.err = removeForm(r.files.form)
+	})
+	return r.files.err
+}
+
+// Close calls Cleanup, so a FormRequest is an io.Closer, e.g. defer req.Close().
+func (r FormRequest) Close() error {
+	return r.Cleanup()
+}
+
+func (r FormRequest) ToMap() RequestValue {
+	r.invalidate()
+	return r.output()
+}
+
+// ToMapCopy returns a deep copy of ToMap, safe to modify without affecting the request.
+func (r FormRequest) ToMapCopy() RequestValue {
+	return deepCopy(r.output()).(RequestValue)
+}
+
+// ToBind binds the form into model, fields are named by their `form` tag and fall back to `json` or the configured tag name.
+func (r FormRequest) ToBind(model interface{}) error {
+	return bindHooked(r.options, r.result, model, binderOf(r.options, "form").bind)
 }
 
-func (r formRequest) ToJsonByte() ([]byte, error) {
-	jsonData, err := json.Marshal(r.result)
+// ToBindValidated binds like ToBind, then runs the validator set with SetValidator.
+// Bind and validation failures are returned together as ValidationErrors.
+func (r FormRequest) ToBindValidated(model interface{}) error {
+	return bindValidated(r, model)
+}
+
+func (r FormRequest) ToJsonByte() ([]byte, error) {
+	jsonData, err := r.encode(r.output())
 	if err != nil {
 		return []byte{}, err
 	}
-	return jsonData, nil
+	return append([]byte(nil), jsonData...), nil
 }
 
-func (r formRequest) ToJsonString() (string, error) {
-	jsonData, err := json.Marshal(r.result)
+func (r FormRequest) ToJsonString() (string, error) {
+	jsonData, err := r.encode(r.output())
 	if err != nil {
 		return "", err
 	}
 	return string(jsonData), nil
 }
+
+// MarshalJSON encodes the parsed values, so json.NewEncoder(w).Encode(req) writes them instead of an empty object.
+func (r FormRequest) MarshalJSON() ([]byte, error) {
+	return r.ToJsonByte()
+}
+
+// String returns the parsed values as JSON for debugging.
+func (r FormRequest) String() string {
+	jsonData, err := r.encode(r.output())
+	if err != nil {
+		return fmt.Sprint(r.output())
+	}
+	return string(jsonData)
+}
+
+// GetFile returns the file uploaded at path, the first one when several were sent under it.
+func (r FormRequest) GetFile(path string) (*multipart.FileHeader, bool) {
+	files := filesAt(r.result, path)
+	if len(files) == 0 {
+		return nil, false
+	}
+	return files[0], true
+}
+
+// GetFiles returns the files uploaded at path, whether one or several were sent.
+func (r FormRequest) GetFiles(path string) []*multipart.FileHeader {
+	return filesAt(r.result, path)
+}
+
+// PartHeaders returns the MIME headers of the multipart parts sent under field, e.g. "meta" or "user[avatar]",
+// in the order they arrived. Headers are only recorded with WithPartHeaders.
+func (r FormRequest) PartHeaders(field string) []textproto.MIMEHeader {
+	return r.parts.headersOf(field)
+}
+
+// SaveFile writes the file uploaded under field to dst and returns dst,
+// e.g. SaveFile("attachments[0][file]", "/srv/uploads/resume.pdf").
+// It fails with http.ErrMissingFile when no file was uploaded under field.
+func (r FormRequest) SaveFile(field string, dst string) (string, error) {
+	file, ok := r.GetFile(field)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", http.ErrMissingFile, field)
+	}
+	return dst, saveFile(file, dst)
+}
+
+// SaveFiles writes every file uploaded under field into dir and returns their paths.
+// naming picks the file name of each upload, nil keeps the sanitized client filename.
+func (r FormRequest) SaveFiles(field string, dir string, naming func(*multipart.FileHeader) string) ([]string, error) {
+	files := r.GetFiles(field)
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%w: %s", http.ErrMissingFile, field)
+	}
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		name := sanitizeFilename(file.Filename)
+		if naming != nil {
+			name = naming(file)
+		}
+		if name == "" {
+			return paths, fmt.Errorf("inrequest: no file name for %s upload %q", field, file.Filename)
+		}
+		dst := filepath.Join(dir, name)
+		if err := saveFile(file, dst); err != nil {
+			return paths, err
+		}
+		paths = append(paths, dst)
+	}
+	return paths, nil
+}
+
+func saveFile(file *multipart.FileHeader, dst string) error {
+	src, err := openFile(file)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, src); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// output is the result as exposed to ToMap and the JSON methods.
+func (r FormRequest) output() RequestValue {
+	if r.options != nil && r.options.OmitFiles {
+		return withoutFiles(r.result)
+	}
+	return r.result
+}
@@ -0,0 +1,935 @@
+package inrequest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newMultipartRequest(t *testing.T, fields map[string]string, files map[string]string) *http.Request {
+	t.Helper()
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for name, value := range fields {
+		if err := writer.WriteField(name, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, content := range files {
+		part, err := writer.CreateFormFile(name, name+".txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = part.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestFormDataOmitFiles(t *testing.T) {
+	fields := map[string]string{
+		"name":                   "John Doe",
+		"attachments[0][title]":  "Resume",
+		"attachments[1][title]":  "Portfolio",
+		"attachments[1][detail]": "2023",
+	}
+	files := map[string]string{
+		"avatar":               "avatar content",
+		"attachments[0][file]": "resume content",
+		"attachments[1][file]": "portfolio content",
+	}
+
+	t.Run("should keep files by default", func(t *testing.T) {
+		req := FormData(newMultipartRequest(t, fields, files))
+		if _, ok := req.ToMap()["avatar"].(*multipart.FileHeader); !ok {
+			t.Fatalf("expected avatar file header, got %v", req.ToMap()["avatar"])
+		}
+	})
+	t.Run("should exclude files from map and json output", func(t *testing.T) {
+		req := FormData(newMultipartRequest(t, fields, files), WithOmitFiles())
+		target := RequestValue{
+			"name": "John Doe",
+			"attachments": []interface{}{
+				RequestValue{"title": "Resume"},
+				RequestValue{"title": "Portfolio", "detail": 2023},
+			},
+		}
+		if !reflect.DeepEqual(req.ToMap(), target) {
+			t.Fatalf("Failed omitting files, expected %v, got %v", target, req.ToMap())
+		}
+		jsonString, err := req.ToJsonString()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := `{"attachments":[{"title":"Resume"},{"detail":2023,"title":"Portfolio"}],"name":"John Doe"}`
+		if jsonString != expected {
+			t.Fatalf("expected %s, got %s", expected, jsonString)
+		}
+	})
+	t.Run("should still bind files", func(t *testing.T) {
+		type Body struct {
+			Avatar *multipart.FileHeader `json:"avatar"`
+		}
+		body := Body{}
+		req := FormData(newMultipartRequest(t, fields, files), WithOmitFiles())
+		if err := req.ToBind(&body); err != nil {
+			t.Fatal(err)
+		}
+		if body.Avatar == nil || body.Avatar.Filename != "avatar.txt" {
+			t.Fatalf("expected avatar to be bound, got %v", body.Avatar)
+		}
+	})
+}
+
+func TestFormDataFormTag(t *testing.T) {
+	type Signup struct {
+		Email    string `form:"user_email" json:"email"`
+		Name     string `json:"name"`
+		Password string `form:"-" json:"password"`
+		Address  struct {
+			City string `form:"town" json:"city"`
+		} `form:"addr" json:"address"`
+	}
+	req := newMultipartRequest(t, map[string]string{
+		"user_email": "john@example.com",
+		"email":      "ignored@example.com",
+		"name":       "John",
+		"password":   "secret",
+		"addr[town]": "Jakarta",
+	}, nil)
+
+	t.Run("should name fields by form tag and fall back to json", func(t *testing.T) {
+		signup := Signup{}
+		if err := FormData(req).ToBind(&signup); err != nil {
+			t.Fatal(err)
+		}
+		target := Signup{Email: "john@example.com", Name: "John"}
+		target.Address.City = "Jakarta"
+		if !reflect.DeepEqual(signup, target) {
+			t.Fatalf("Failed binding form tags, expected %+v, got %+v", target, signup)
+		}
+	})
+}
+
+func TestFormDataNestedFiles(t *testing.T) {
+	type Document struct {
+		Title string                `json:"title"`
+		File  *multipart.FileHeader `json:"file"`
+	}
+	type Upload struct {
+		Owner    string   `json:"owner"`
+		Document Document `json:"document"`
+	}
+
+	t.Run("should bind files inside nested structs", func(t *testing.T) {
+		req := newMultipartRequest(t, map[string]string{
+			"owner":           "John",
+			"document[title]": "Contract",
+		}, map[string]string{"document[file]": "signed"})
+		upload := Upload{}
+		if err := FormData(req).ToBind(&upload); err != nil {
+			t.Fatal(err)
+		}
+		if upload.Owner != "John" || upload.Document.Title != "Contract" {
+			t.Fatalf("Failed binding nested fields, got %+v", upload)
+		}
+		if upload.Document.File == nil || upload.Document.File.Filename != "document[file].txt" {
+			t.Fatalf("Failed binding nested file, got %+v", upload.Document.File)
+		}
+	})
+}
+
+func BenchmarkFormDataToBind(b *testing.B) {
+	type Address struct {
+		City string `json:"city"`
+		Zip  string `json:"zip"`
+	}
+	type Signup struct {
+		Name    string   `json:"name"`
+		Age     int      `json:"age"`
+		Tags    []string `json:"tags"`
+		Address Address  `json:"address"`
+	}
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.PostForm = map[string][]string{
+		"name":          {"John"},
+		"age":           {"31"},
+		"tags":          {"a", "b", "c"},
+		"address[city]": {"Paris"},
+		"address[zip]":  {"75001"},
+	}
+	form := FormData(req)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		signup := Signup{}
+		if err := form.ToBind(&signup); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestFormDataFilesInSlices(t *testing.T) {
+	type Attachment struct {
+		Title string                `json:"title"`
+		File  *multipart.FileHeader `json:"file"`
+	}
+	type Message struct {
+		Subject     string       `json:"subject"`
+		Attachments []Attachment `json:"attachments"`
+	}
+
+	t.Run("should bind files inside slices of structs", func(t *testing.T) {
+		req := newMultipartRequest(t, map[string]string{
+			"subject":               "Report",
+			"attachments[0][title]": "Summary",
+			"attachments[1][title]": "Numbers",
+		}, map[string]string{
+			"attachments[0][file]": "summary",
+			"attachments[1][file]": "numbers",
+		})
+		message := Message{}
+		if err := FormData(req).ToBind(&message); err != nil {
+			t.Fatal(err)
+		}
+		if message.Subject != "Report" || len(message.Attachments) != 2 {
+			t.Fatalf("Failed binding attachments, got %+v", message)
+		}
+		for i, title := range []string{"Summary", "Numbers"} {
+			attachment := message.Attachments[i]
+			if attachment.Title != title || attachment.File == nil || attachment.File.Filename != "attachments["+strconv.Itoa(i)+"][file].txt" {
+				t.Fatalf("Failed binding attachment %d, got %+v", i, attachment)
+			}
+		}
+	})
+	t.Run("should bind files sent under one name into a slice", func(t *testing.T) {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		for _, name := range []string{"a.txt", "b.txt"} {
+			part, err := writer.CreateFormFile("photos", name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			part.Write([]byte(name))
+		}
+		writer.Close()
+		req := httptest.NewRequest(http.MethodPost, "/", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+
+		album := struct {
+			Photos []*multipart.FileHeader `json:"photos"`
+		}{}
+		if err := FormData(req).ToBind(&album); err != nil {
+			t.Fatal(err)
+		}
+		if len(album.Photos) != 2 || album.Photos[0].Filename != "a.txt" || album.Photos[1].Filename != "b.txt" {
+			t.Fatalf("Failed binding repeated files, got %+v", album.Photos)
+		}
+	})
+	t.Run("should push values and files sent under empty brackets in order", func(t *testing.T) {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		for _, name := range []string{"a.txt", "b.txt"} {
+			writer.WriteField("tags[]", name)
+			part, err := writer.CreateFormFile("photos[]", name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			part.Write([]byte(name))
+		}
+		writer.Close()
+		req := httptest.NewRequest(http.MethodPost, "/", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+
+		album := struct {
+			Tags   []string                `json:"tags"`
+			Photos []*multipart.FileHeader `json:"photos"`
+		}{}
+		if err := FormData(req).ToBind(&album); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(album.Tags, []string{"a.txt", "b.txt"}) || len(album.Photos) != 2 || album.Photos[1].Filename != "b.txt" {
+			t.Fatalf("Failed pushing items, got %+v", album)
+		}
+	})
+}
+
+func TestFormDataE(t *testing.T) {
+	t.Run("should return the parsed form", func(t *testing.T) {
+		req := newMultipartRequest(t, map[string]string{"name": "John"}, nil)
+		result, err := FormDataE(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.ToMap()["name"] != "John" {
+			t.Fatalf("unexpected form %v", result.ToMap())
+		}
+	})
+	t.Run("should report malformed multipart bodies", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("--other\r\nbroken"))
+		req.Header.Set("Content-Type", "multipart/form-data; boundary=expected")
+		if _, err := FormDataE(req); !IsParseError(err) {
+			t.Fatalf("expected parse error, got %v", err)
+		}
+	})
+	t.Run("should report bodies over the server limit", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("name="+strings.Repeat("a", 100)))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Body = http.MaxBytesReader(httptest.NewRecorder(), req.Body, 10)
+		if _, err := FormDataE(req); !IsParseError(err) {
+			t.Fatalf("expected parse error, got %v", err)
+		}
+	})
+	t.Run("should report array indexes past the maximum", func(t *testing.T) {
+		req := newMultipartRequest(t, map[string]string{"items[0]": "a", "items[11]": "b"}, nil)
+		if _, err := FormDataE(req, WithMaxArrayIndex(10)); !IsParseError(err) || !errors.Is(err, ErrArrayIndexTooLarge) {
+			t.Fatalf("expected an array index error, got %v", err)
+		}
+	})
+	t.Run("should keep FormData returning an empty result", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("--other\r\nbroken"))
+		req.Header.Set("Content-Type", "multipart/form-data; boundary=expected")
+		if result := FormData(req); len(result.ToMap()) != 0 {
+			t.Fatalf("expected an empty form, got %v", result.ToMap())
+		}
+	})
+}
+
+func TestFormDataMaxMemory(t *testing.T) {
+	isOnDisk := func(t *testing.T, opts ...Option) bool {
+		req := newMultipartRequest(t, nil, map[string]string{"avatar": "image bytes"})
+		avatar, ok := FormData(req, opts...).ToMap()["avatar"].(*multipart.FileHeader)
+		if !ok {
+			t.Fatal("expected an uploaded file")
+		}
+		file, err := avatar.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		_, ok = file.(*os.File)
+		return ok
+	}
+
+	t.Run("should store files on disk by default", func(t *testing.T) {
+		if !isOnDisk(t) {
+			t.Fatal("expected the file in a temporary file")
+		}
+	})
+	t.Run("should keep small files in memory", func(t *testing.T) {
+		if isOnDisk(t, WithMaxMemory(1<<20)) {
+			t.Fatal("expected the file in memory")
+		}
+	})
+}
+
+func TestFormDataFileSizeLimits(t *testing.T) {
+	fields := map[string]string{"name": "John Doe"}
+	files := map[string]string{
+		"avatar":               "avatar content",
+		"attachments[0][file]": "resume content",
+		"attachments[1][file]": "a much longer portfolio content",
+	}
+
+	t.Run("should keep files within the limits", func(t *testing.T) {
+		req := newMultipartRequest(t, fields, files)
+		result, err := FormDataE(req, WithMaxFileSize("avatar", 1<<10), WithMaxTotalUploadSize(1<<10))
+		if err != nil {
+			t.Fatal(err)
+		}
+		avatar, ok := result.ToMap()["avatar"].(*multipart.FileHeader)
+		if !ok || avatar.Filename != "avatar.txt" || avatar.Size != int64(len(files["avatar"])) {
+			t.Fatalf("expected the avatar upload, got %v", result.ToMap()["avatar"])
+		}
+		if result.ToMap()["name"] != "John Doe" {
+			t.Fatalf("expected name John Doe, got %v", result.ToMap()["name"])
+		}
+		file, err := avatar.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		if content, _ := io.ReadAll(file); string(content) != files["avatar"] {
+			t.Fatalf("expected avatar content, got %q", content)
+		}
+	})
+	t.Run("should report the field of an oversized file", func(t *testing.T) {
+		req := newMultipartRequest(t, fields, files)
+		_, err := FormDataE(req, WithMaxFileSize("attachments[][file]", 20))
+
+		var tooLarge *FileTooLargeError
+		if !IsParseError(err) || !errors.As(err, &tooLarge) {
+			t.Fatalf("expected file too large error, got %v", err)
+		}
+		if tooLarge.Field != "attachments[1][file]" || tooLarge.Max != 20 || tooLarge.Size != 21 || tooLarge.Total {
+			t.Fatalf("unexpected error %+v", tooLarge)
+		}
+	})
+	t.Run("should report uploads over the total limit", func(t *testing.T) {
+		req := newMultipartRequest(t, fields, files)
+		_, err := FormDataE(req, WithMaxTotalUploadSize(30))
+
+		var tooLarge *FileTooLargeError
+		if !errors.As(err, &tooLarge) || !tooLarge.Total || tooLarge.Max != 30 || tooLarge.Size != 31 {
+			t.Fatalf("expected total upload error, got %v", err)
+		}
+	})
+}
+
+func TestFormDataUploadLimitsBudget(t *testing.T) {
+	limited := WithMaxFileSize("avatar", 1<<20)
+
+	t.Run("should bound the values of a form together", func(t *testing.T) {
+		value := strings.Repeat("x", 5<<20)
+		fields := map[string]string{"a": value, "b": value, "c": value}
+		_, stdErr := FormDataE(newMultipartRequest(t, fields, nil))
+		_, err := FormDataE(newMultipartRequest(t, fields, nil), limited)
+		if !errors.Is(stdErr, multipart.ErrMessageTooLarge) || !errors.Is(err, multipart.ErrMessageTooLarge) {
+			t.Fatalf("expected both paths to reject the values, got %v and %v", stdErr, err)
+		}
+	})
+	t.Run("should bound the number of parts", func(t *testing.T) {
+		fields := make(map[string]string, 1001)
+		for i := 0; i <= 1000; i++ {
+			fields["f"+strconv.Itoa(i)] = "x"
+		}
+		if _, err := FormDataE(newMultipartRequest(t, fields, nil), limited); !errors.Is(err, multipart.ErrMessageTooLarge) {
+			t.Fatalf("expected too many parts to be rejected, got %v", err)
+		}
+	})
+	t.Run("should share the memory between files", func(t *testing.T) {
+		content := strings.Repeat("x", 600)
+		req := newMultipartRequest(t, nil, map[string]string{"first": content, "second": content})
+		result, err := FormDataE(req, limited, WithMaxMemory(1000))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer result.Cleanup()
+		onDisk := 0
+		for _, name := range []string{"first", "second"} {
+			file, err := result.ToMap()[name].(*multipart.FileHeader).Open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := file.(*os.File); ok {
+				onDisk++
+			}
+			file.Close()
+		}
+		if onDisk != 1 {
+			t.Fatalf("expected one of the files past MaxMemory on disk, got %d", onDisk)
+		}
+	})
+}
+
+func TestFormDataAllowedMimeTypes(t *testing.T) {
+	png := "\x89PNG\r\n\x1a\n" + strings.Repeat("\x00", 16)
+
+	t.Run("should accept files sniffed as an allowed type", func(t *testing.T) {
+		req := newMultipartRequest(t, nil, map[string]string{"photos[0]": png})
+		result, err := FormDataE(req, WithAllowedMimeTypes("photos", "image/png", "image/jpeg"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		photos, ok := result.ToMap()["photos"].([]interface{})
+		if !ok || len(photos) != 1 {
+			t.Fatalf("expected one photo, got %v", result.ToMap()["photos"])
+		}
+		file, err := photos[0].(*multipart.FileHeader).Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		if content, _ := io.ReadAll(file); string(content) != png {
+			t.Fatalf("expected the sniffed bytes to be kept, got %q", content)
+		}
+	})
+	t.Run("should reject files whose content is of another type", func(t *testing.T) {
+		req := newMultipartRequest(t, nil, map[string]string{"photos[0]": "<html><body>hi</body></html>"})
+		_, err := FormDataE(req, WithAllowedMimeTypes("photos", "image/*"))
+
+		var typeErr *FileTypeError
+		if !IsParseError(err) || !errors.As(err, &typeErr) {
+			t.Fatalf("expected file type error, got %v", err)
+		}
+		if typeErr.Field != "photos[0]" || typeErr.Type != "text/html" {
+			t.Fatalf("unexpected error %+v", typeErr)
+		}
+	})
+}
+
+func newUploadRequest(t *testing.T, field string, filename string, content string) *http.Request {
+	t.Helper()
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", `form-data; name="`+field+`"; filename="`+filename+`"`)
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = part.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err = writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestFormDataFilenamePolicy(t *testing.T) {
+	t.Run("should sanitize crafted filenames", func(t *testing.T) {
+		cases := map[string]string{
+			`..\\..\\evil.sh`:            "evil.sh",
+			"UTF-8''report%07.pdf":       "report.pdf",
+			"UTF-8''na%C3%AFve%20cv.txt": "naïve cv.txt",
+			"..":                         "",
+		}
+		for filename, expected := range cases {
+			req := newUploadRequest(t, "resume", filename, "content")
+			result, err := FormDataE(req, WithSanitizedFilenames())
+			if err != nil {
+				t.Fatal(err)
+			}
+			resume, ok := result.ToMap()["resume"].(*multipart.FileHeader)
+			if !ok || resume.Filename != expected {
+				t.Fatalf("expected %q to become %q, got %v", filename, expected, result.ToMap()["resume"])
+			}
+		}
+	})
+	t.Run("should accept allowed extensions case-insensitively", func(t *testing.T) {
+		req := newUploadRequest(t, "resume", "cv.PDF", "content")
+		if _, err := FormDataE(req, WithAllowedExtensions("resume", "pdf", ".docx")); err != nil {
+			t.Fatal(err)
+		}
+	})
+	t.Run("should reject other extensions", func(t *testing.T) {
+		req := newUploadRequest(t, "resume", "cv.pdf.exe", "content")
+		_, err := FormDataE(req, WithAllowedExtensions("resume", ".pdf"))
+
+		var typeErr *FileTypeError
+		if !errors.As(err, &typeErr) || typeErr.Field != "resume" || typeErr.Type != ".exe" {
+			t.Fatalf("expected file type error for .exe, got %v", err)
+		}
+	})
+}
+
+func TestFormDataMaxFiles(t *testing.T) {
+	files := map[string]string{"photos[0]": "a", "photos[1]": "b", "photos[2]": "c", "avatar": "d"}
+
+	t.Run("should accept files within the count", func(t *testing.T) {
+		if _, err := FormDataE(newMultipartRequest(t, nil, files), WithMaxFiles("photos", 3)); err != nil {
+			t.Fatal(err)
+		}
+	})
+	t.Run("should reject a field holding too many files", func(t *testing.T) {
+		_, err := FormDataE(newMultipartRequest(t, nil, files), WithMaxFiles("photos[]", 2))
+		if !IsParseError(err) || !errors.Is(err, ErrTooManyFiles) {
+			t.Fatalf("expected too many files error, got %v", err)
+		}
+	})
+}
+
+func TestFormDataSaveFiles(t *testing.T) {
+	files := map[string]string{"avatar": "avatar content", "photos[0]": "first", "photos[1]": "second"}
+
+	t.Run("should save a single upload", func(t *testing.T) {
+		dst := filepath.Join(t.TempDir(), "me.png")
+		path, err := FormData(newMultipartRequest(t, nil, files)).SaveFile("avatar", dst)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if content, _ := os.ReadFile(path); string(content) != "avatar content" {
+			t.Fatalf("expected saved avatar content, got %q", content)
+		}
+	})
+	t.Run("should save every upload of a field", func(t *testing.T) {
+		dir := t.TempDir()
+		paths, err := FormData(newMultipartRequest(t, nil, files)).SaveFiles("photos", dir, func(file *multipart.FileHeader) string {
+			return "copy-" + file.Filename
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := []string{filepath.Join(dir, "copy-photos[0].txt"), filepath.Join(dir, "copy-photos[1].txt")}
+		if !reflect.DeepEqual(paths, expected) {
+			t.Fatalf("expected %v, got %v", expected, paths)
+		}
+		if content, _ := os.ReadFile(paths[1]); string(content) != "second" {
+			t.Fatalf("expected second photo content, got %q", content)
+		}
+	})
+	t.Run("should report a missing upload", func(t *testing.T) {
+		_, err := FormData(newMultipartRequest(t, nil, files)).SaveFile("resume", filepath.Join(t.TempDir(), "cv"))
+		if !errors.Is(err, http.ErrMissingFile) {
+			t.Fatalf("expected missing file error, got %v", err)
+		}
+	})
+}
+
+func TestFormDataInMemoryUploads(t *testing.T) {
+	files := map[string]string{"avatar": "avatar content", "resume": "resume content"}
+
+	t.Run("should keep every file in memory", func(t *testing.T) {
+		result, err := FormDataE(newMultipartRequest(t, nil, files), WithInMemoryUploads())
+		if err != nil {
+			t.Fatal(err)
+		}
+		file, err := result.ToMap()["avatar"].(*multipart.FileHeader).Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		if _, ok := file.(*os.File); ok {
+			t.Fatal("expected the file in memory")
+		}
+	})
+	t.Run("should fail once the files exceed the memory cap", func(t *testing.T) {
+		_, err := FormDataE(newMultipartRequest(t, nil, files), WithInMemoryUploads(), WithMaxMemory(20))
+
+		var tooLarge *RequestTooLargeError
+		if !IsParseError(err) || !errors.As(err, &tooLarge) || tooLarge.Limit != "memory" || tooLarge.Max != 20 {
+			t.Fatalf("expected memory cap error, got %v", err)
+		}
+	})
+}
+
+func TestFormDataFileScanner(t *testing.T) {
+	files := map[string]string{"avatar": "avatar content", "resume": "EICAR test signature"}
+	infected := errors.New("infected")
+	scanner := WithFileScanner(func(ctx context.Context, header *multipart.FileHeader, file io.Reader) error {
+		content, err := io.ReadAll(file)
+		if err != nil {
+			return err
+		}
+		if strings.Contains(string(content), "EICAR") {
+			return infected
+		}
+		return nil
+	})
+
+	t.Run("should accept files passing the scanner", func(t *testing.T) {
+		req := newMultipartRequest(t, nil, map[string]string{"avatar": files["avatar"]})
+		if _, err := FormDataE(req, scanner); err != nil {
+			t.Fatal(err)
+		}
+	})
+	t.Run("should fail the parse for a rejected file", func(t *testing.T) {
+		_, err := FormDataE(newMultipartRequest(t, nil, files), scanner)
+
+		var rejected *FileRejectedError
+		if !IsParseError(err) || !errors.As(err, &rejected) || rejected.Field != "resume" || !errors.Is(err, infected) {
+			t.Fatalf("expected rejected resume, got %v", err)
+		}
+	})
+}
+
+func TestFormDataDefaults(t *testing.T) {
+	t.Run("should start every call from the configured options", func(t *testing.T) {
+		Configure(Options{MaxKeys: 1})
+		t.Cleanup(func() { Configure(Options{}) })
+
+		req := newMultipartRequest(t, map[string]string{"a": "1", "b": "2"}, nil)
+		var tooLarge *RequestTooLargeError
+		if _, err := FormDataE(req); !errors.As(err, &tooLarge) {
+			t.Fatalf("expected the default key limit, got %v", err)
+		}
+		req = newMultipartRequest(t, map[string]string{"a": "1", "b": "2"}, nil)
+		if _, err := FormDataE(req, WithMaxKeys(0)); err != nil {
+			t.Fatalf("expected call options to override the defaults, got %v", err)
+		}
+	})
+	t.Run("should keep call options out of the defaults", func(t *testing.T) {
+		Configure(Options{FieldTypes: map[string]FieldType{"zip": String}})
+		t.Cleanup(func() { Configure(Options{}) })
+
+		newOptions([]Option{WithFieldType("age", Int)})
+		if options := newOptions(nil); len(options.FieldTypes) != 1 {
+			t.Fatalf("expected the defaults untouched, got %v", options.FieldTypes)
+		}
+	})
+	t.Run("should store uploads in the configured temp directory", func(t *testing.T) {
+		t.Cleanup(func() { Configure(Options{}) })
+		tempDir := os.TempDir()
+		dir := t.TempDir()
+		if err := SetTempDir(dir); err != nil {
+			t.Fatal(err)
+		}
+		SetDefaultMaxMemory(0)
+		if os.TempDir() != tempDir {
+			t.Fatalf("expected os.TempDir to stay %s, got %s", tempDir, os.TempDir())
+		}
+
+		req := newMultipartRequest(t, nil, map[string]string{"avatar": "avatar content"})
+		file, err := UploadedFile{FileHeader: FormData(req).ToMap()["avatar"].(*multipart.FileHeader)}.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		if f, ok := file.(*os.File); !ok || filepath.Dir(f.Name()) != dir {
+			t.Fatalf("expected the upload stored in %s, got %v", dir, file)
+		}
+	})
+	t.Run("should store uploads in the temp directory of the call", func(t *testing.T) {
+		dir := t.TempDir()
+		req := newMultipartRequest(t, nil, map[string]string{"avatar": "avatar content", "icon": "icon"})
+		form, err := FormDataE(req, WithTempDir(dir), WithMaxMemory(5))
+		if err != nil {
+			t.Fatal(err)
+		}
+		avatar, err := UploadedFile{FileHeader: form.ToMap()["avatar"].(*multipart.FileHeader)}.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer avatar.Close()
+		if f, ok := avatar.(*os.File); !ok || filepath.Dir(f.Name()) != dir {
+			t.Fatalf("expected the upload stored in %s, got %v", dir, avatar)
+		}
+		content, _ := io.ReadAll(avatar)
+		if string(content) != "avatar content" {
+			t.Fatalf("expected the upload content, got %q", content)
+		}
+		icon, _ := UploadedFile{FileHeader: form.ToMap()["icon"].(*multipart.FileHeader)}.Open()
+		if _, ok := icon.(*os.File); ok {
+			t.Fatal("expected the small upload kept in memory")
+		}
+		avatar.Close()
+		if err = form.Cleanup(); err != nil {
+			t.Fatal(err)
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+			t.Fatalf("expected Cleanup to delete the stored upload, got %v", entries)
+		}
+	})
+	t.Run("should remove the stored uploads once the request is done", func(t *testing.T) {
+		dir := t.TempDir()
+		ctx, cancel := context.WithCancel(context.Background())
+		req := newMultipartRequest(t, nil, map[string]string{"avatar": "avatar content"}).WithContext(ctx)
+		if _, err := FormDataE(req, WithTempDir(dir), WithMaxMemory(0)); err != nil {
+			t.Fatal(err)
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+			t.Fatalf("expected the stored upload, got %v", entries)
+		}
+		cancel()
+		for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+			if entries, _ := os.ReadDir(dir); len(entries) == 0 {
+				break
+			}
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+			t.Fatalf("expected the stored upload removed, got %v", entries)
+		}
+	})
+	t.Run("should fail when the temp directory cannot be used", func(t *testing.T) {
+		req := newMultipartRequest(t, nil, map[string]string{"avatar": "avatar content"})
+		if _, err := FormDataE(req, WithTempDir(filepath.Join(t.TempDir(), "missing")), WithMaxMemory(0)); err == nil {
+			t.Fatal("expected an error for a missing directory")
+		}
+	})
+	t.Run("should reject a temp directory that does not exist", func(t *testing.T) {
+		if err := SetTempDir(filepath.Join(t.TempDir(), "missing")); err == nil {
+			t.Fatal("expected an error for a missing directory")
+		}
+	})
+}
+
+func TestFormDataGetFiles(t *testing.T) {
+	req := FormData(newMultipartRequest(t, map[string]string{"name": "John"}, map[string]string{
+		"document":  "document content",
+		"photos[0]": "first",
+		"photos[1]": "second",
+	}))
+
+	t.Run("should get a single file", func(t *testing.T) {
+		document, ok := req.GetFile("document")
+		if !ok || document.Filename != "document.txt" {
+			t.Fatalf("expected the document, got %v", document)
+		}
+		if _, ok = req.GetFile("name"); ok {
+			t.Fatal("expected no file for a text field")
+		}
+	})
+	t.Run("should get every file of a field", func(t *testing.T) {
+		if photos := req.GetFiles("photos"); len(photos) != 2 || photos[1].Filename != "photos[1].txt" {
+			t.Fatalf("expected two photos, got %v", photos)
+		}
+		if documents := req.GetFiles("document"); len(documents) != 1 {
+			t.Fatalf("expected the single document as a slice, got %v", documents)
+		}
+	})
+}
+
+func TestFormDataQueryMerged(t *testing.T) {
+	t.Run("should fold the query string into urlencoded bodies", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/?page=2&name=Query", strings.NewReader("name=John"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		result := FormData(req, WithQueryMerged()).ToMap()
+		if target := (RequestValue{"page": 2, "name": "John"}); !reflect.DeepEqual(result, target) {
+			t.Fatalf("expected %v, got %v", target, result)
+		}
+	})
+	t.Run("should fold the query string into multipart bodies", func(t *testing.T) {
+		req := newMultipartRequest(t, map[string]string{"name": "John"}, map[string]string{"avatar": "png"})
+		req.URL.RawQuery = "token=abc&avatar=query"
+		result := FormData(req, WithQueryMerged()).ToMap()
+		if result["token"] != "abc" || result["name"] != "John" {
+			t.Fatalf("unexpected form %v", result)
+		}
+		if _, ok := result["avatar"].(*multipart.FileHeader); !ok {
+			t.Fatalf("expected the uploaded avatar to win, got %v", result["avatar"])
+		}
+	})
+	t.Run("should ignore the query string by default", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/?page=2", strings.NewReader("name=John"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if result := FormData(req).ToMap(); !reflect.DeepEqual(result, RequestValue{"name": "John"}) {
+			t.Fatalf("unexpected form %v", result)
+		}
+	})
+}
+
+func TestFormDataCheckbox(t *testing.T) {
+	type Preferences struct {
+		Subscribed bool  `form:"subscribed,checkbox"`
+		Terms      bool  `form:"terms,checkbox"`
+		Tracking   *bool `form:"tracking,checkbox"`
+		Remember   bool  `form:"remember,checkbox"`
+		Admin      bool  `form:"admin"`
+	}
+	t.Run("should bind missing checkboxes as false and on as true", func(t *testing.T) {
+		prefs := Preferences{Terms: true, Admin: true}
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("subscribed=on&remember=0&remember=1"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if err := FormData(req).ToBind(&prefs); err != nil {
+			t.Fatal(err)
+		}
+		if !prefs.Subscribed || prefs.Terms || prefs.Tracking == nil || *prefs.Tracking || !prefs.Remember {
+			t.Fatalf("unexpected preferences %+v", prefs)
+		}
+		if !prefs.Admin {
+			t.Fatal("expected a field without the checkbox option to keep its value")
+		}
+	})
+}
+
+func TestFormDataParts(t *testing.T) {
+	newRequest := func(t *testing.T, meta string) *http.Request {
+		t.Helper()
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		writer.WriteField("name", "John")
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", `form-data; name="user[meta]"`)
+		header.Set("Content-Type", "application/json; charset=utf-8")
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write([]byte(meta))
+		writer.Close()
+		req := httptest.NewRequest(http.MethodPost, "/", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		return req
+	}
+
+	t.Run("should expose the headers of value parts", func(t *testing.T) {
+		result, err := FormDataE(newRequest(t, `{}`), WithPartHeaders())
+		if err != nil {
+			t.Fatal(err)
+		}
+		headers := result.PartHeaders("user[meta]")
+		if len(headers) != 1 || headers[0].Get("Content-Type") != "application/json; charset=utf-8" {
+			t.Fatalf("unexpected part headers %v", headers)
+		}
+		if len(result.PartHeaders("name")) != 1 {
+			t.Fatalf("expected the headers of the name part, got %v", result.PartHeaders("name"))
+		}
+	})
+	t.Run("should not record headers by default", func(t *testing.T) {
+		if headers := FormData(newRequest(t, `{}`)).PartHeaders("name"); headers != nil {
+			t.Fatalf("expected no headers, got %v", headers)
+		}
+	})
+	t.Run("should decode json parts into nested values", func(t *testing.T) {
+		result, err := FormDataE(newRequest(t, `{"tags":["a","b"],"zip":"007","count":"12"}`), WithJsonParts())
+		if err != nil {
+			t.Fatal(err)
+		}
+		target := RequestValue{
+			"name": "John",
+			"user": RequestValue{"meta": map[string]interface{}{"tags": []interface{}{"a", "b"}, "zip": "007", "count": "12"}},
+		}
+		if !reflect.DeepEqual(result.ToMap(), target) {
+			t.Fatalf("expected %v, got %v", target, result.ToMap())
+		}
+	})
+	t.Run("should report malformed json parts", func(t *testing.T) {
+		if _, err := FormDataE(newRequest(t, `{"tags":`), WithJsonParts()); !IsParseError(err) {
+			t.Fatalf("expected a parse error, got %v", err)
+		}
+	})
+}
+
+func TestFormDataCleanup(t *testing.T) {
+	t.Run("should remove the temporary files once", func(t *testing.T) {
+		req := newMultipartRequest(t, nil, map[string]string{"avatar": "image bytes"})
+		result, err := FormDataE(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var closer io.Closer = result
+		defer closer.Close()
+		avatar, _ := result.GetFile("avatar")
+		if _, err = avatar.Open(); err != nil {
+			t.Fatal(err)
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := result.Cleanup(); err != nil {
+					t.Error(err)
+				}
+			}()
+		}
+		wg.Wait()
+		if err = closer.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if file, err := avatar.Open(); err == nil {
+			file.Close()
+			t.Fatal("expected the temporary file to be removed")
+		}
+	})
+	t.Run("should close requests without files", func(t *testing.T) {
+		if err := FormData(httptest.NewRequest(http.MethodPost, "/", nil)).Close(); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
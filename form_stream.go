@@ -0,0 +1,114 @@
+package inrequest
+
+import (
+	"io"
+	"mime/multipart"
+	"net/http"
+)
+
+// FormStreamHandler receives the parts of a multipart body in the order they are sent, see FormStream.
+// A nil callback skips the parts it would receive, an error returned by a callback stops the stream.
+type FormStreamHandler struct {
+	// OnField receives every value that is not a file.
+	OnField func(name string, value string) error
+
+	// OnFile receives every file while it is read from the body, file can only be read until OnFile returns.
+	// The header holds the filename and part headers, its Size is unknown and stays zero.
+	OnFile func(name string, file io.Reader, header *multipart.FileHeader) error
+}
+
+/*
+Reading a multipart body part by part without storing any of it
+e.g. piping every upload straight into object storage:
+
+	err := inrequest.FormStream(r, inrequest.FormStreamHandler{
+		OnFile: func(name string, file io.Reader, header *multipart.FileHeader) error {
+			_, err := bucket.Upload(ctx, header.Filename, file)
+			return err
+		},
+	})
+
+Body and upload limits apply like for FormDataE, a file over its size limit fails with a
+*FileTooLargeError once the callback reads past the limit.
+*/
+func FormStream(r *http.Request, handler FormStreamHandler, opts ...Option) error {
+	options := requestOptions(r, opts)
+	_, err := parseBody(r, options, formMediaTypes[:1], func(r *http.Request) (RequestValue, error) {
+		return nil, streamParts(r, handler, options)
+	})
+	return err
+}
+
+func streamParts(r *http.Request, handler FormStreamHandler, options *Options) error {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return &ParseError{Err: err}
+	}
+	limits := newUploadLimits(options)
+	budget := newFormBudget(options)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil
+		}
+		if err == nil {
+			err = budget.part(part)
+		}
+		if err != nil {
+			return &ParseError{Err: err}
+		}
+		name := part.FormName()
+		if name == "" {
+			continue
+		}
+		if part.FileName() == "" {
+			if handler.OnField == nil {
+				continue
+			}
+			value, err := budget.readValue(part)
+			if err != nil {
+				return &ParseError{Err: err}
+			}
+			if err = handler.OnField(name, value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if handler.OnFile == nil {
+			continue
+		}
+		body, limit, err := limits.open(name, part)
+		if err != nil {
+			return &ParseError{Err: err}
+		}
+		counter := &countingReader{Reader: body}
+		var file io.Reader = counter
+		if limit >= 0 {
+			file = &fileLimitReader{counter: counter, limit: limit}
+		}
+		err = handler.OnFile(name, file, &multipart.FileHeader{Filename: options.filename(part), Header: part.Header})
+		if limitErr := limits.done(name, counter.n, limit); limitErr != nil {
+			return &ParseError{Err: limitErr}
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// fileLimitReader fails with errFileTooLarge once more than limit bytes are read.
+type fileLimitReader struct {
+	counter *countingReader
+	limit   int64
+}
+
+func (f *fileLimitReader) Read(p []byte) (int, error) {
+	if f.counter.n > f.limit {
+		return 0, errFileTooLarge
+	}
+	if remaining := f.limit + 1 - f.counter.n; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	return f.counter.Read(p)
+}
@@ -0,0 +1,65 @@
+package inrequest
+
+import (
+	"errors"
+	"io"
+	"mime/multipart"
+	"reflect"
+	"testing"
+)
+
+func TestFormStream(t *testing.T) {
+	fields := map[string]string{"name": "John Doe"}
+	files := map[string]string{"avatar": "avatar content"}
+
+	t.Run("should stream fields and files to the callbacks", func(t *testing.T) {
+		values := make(map[string]string)
+		err := FormStream(newMultipartRequest(t, fields, files), FormStreamHandler{
+			OnField: func(name string, value string) error {
+				values[name] = value
+				return nil
+			},
+			OnFile: func(name string, file io.Reader, header *multipart.FileHeader) error {
+				content, err := io.ReadAll(file)
+				values[name] = header.Filename + ":" + string(content)
+				return err
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := map[string]string{"name": "John Doe", "avatar": "avatar.txt:avatar content"}
+		if !reflect.DeepEqual(values, expected) {
+			t.Fatalf("expected %v, got %v", expected, values)
+		}
+	})
+	t.Run("should stop at the first callback error", func(t *testing.T) {
+		failed := errors.New("upload failed")
+		err := FormStream(newMultipartRequest(t, fields, files), FormStreamHandler{
+			OnFile: func(name string, file io.Reader, header *multipart.FileHeader) error {
+				return failed
+			},
+		})
+		if err != failed {
+			t.Fatalf("expected the callback error, got %v", err)
+		}
+	})
+	t.Run("should fail a file read past its size limit", func(t *testing.T) {
+		var read int
+		err := FormStream(newMultipartRequest(t, fields, files), FormStreamHandler{
+			OnFile: func(name string, file io.Reader, header *multipart.FileHeader) error {
+				content, err := io.ReadAll(file)
+				read = len(content)
+				return err
+			},
+		}, WithMaxFileSize("avatar", 6))
+
+		var tooLarge *FileTooLargeError
+		if !IsParseError(err) || !errors.As(err, &tooLarge) || tooLarge.Field != "avatar" {
+			t.Fatalf("expected file too large error, got %v", err)
+		}
+		if read > 7 {
+			t.Fatalf("expected reading to stop past the limit, read %d bytes", read)
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package inrequest
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+Encoding the values back into form fields, so the request can be forwarded upstream
+e.g. {"user": {"name": "John"}, "tags": ["a", "b"]} gives user[name]=John&tags[0]=a&tags[1]=b,
+uploaded files are left out, see ToMultipart
+*/
+func (v requestValues) ToURLValues() url.Values {
+	values := make(url.Values)
+	walkFields(v.result, "", func(key string, value interface{}) error {
+		if _, ok := value.(*multipart.FileHeader); !ok {
+			values.Add(key, fieldString(value))
+		}
+		return nil
+	})
+	return values
+}
+
+// ToMultipart writes the values as multipart fields named like ToURLValues, uploaded files included.
+// The caller closes w.
+func (v requestValues) ToMultipart(w *multipart.Writer) error {
+	return walkFields(v.result, "", func(key string, value interface{}) error {
+		file, ok := value.(*multipart.FileHeader)
+		if !ok {
+			return w.WriteField(key, fieldString(value))
+		}
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, quoteEscaper.Replace(key), quoteEscaper.Replace(file.Filename)))
+		header.Set("Content-Type", "application/octet-stream")
+		if contentType := file.Header.Get("Content-Type"); contentType != "" {
+			header.Set("Content-Type", contentType)
+		}
+		part, err := w.CreatePart(header)
+		if err != nil {
+			return err
+		}
+		src, err := openFile(file)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		_, err = io.Copy(part, src)
+		return err
+	})
+}
+
+// quoteEscaper escapes multipart header parameters like multipart.Writer.CreateFormFile.
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// walkFields calls fn with the bracket key of every value in sorted key order.
+func walkFields(value interface{}, key string, fn func(key string, value interface{}) error) error {
+	switch v := value.(type) {
+	case RequestValue:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if err := walkFields(v[k], bracketKey(key, k), fn); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []interface{}:
+		for i, item := range v {
+			if err := walkFields(item, bracketKey(key, strconv.Itoa(i)), fn); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fn(key, value)
+}
+
+func bracketKey(key string, sub string) string {
+	if key == "" {
+		return sub
+	}
+	return key + "[" + sub + "]"
+}
+
+// fieldString formats a parsed value as the form field it was sent as.
+func fieldString(value interface{}) string {
+	if s, ok := scalarString(value); ok {
+		return s
+	}
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,67 @@
+package inrequest
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestForward(t *testing.T) {
+	t.Run("should encode nested values as bracket keys", func(t *testing.T) {
+		body := `{"user":{"name":"John","age":31},"tags":["a","b"],"active":true}`
+		req, err := Json(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := url.Values{
+			"user[name]": {"John"},
+			"user[age]":  {"31"},
+			"tags[0]":    {"a"},
+			"tags[1]":    {"b"},
+			"active":     {"true"},
+		}
+		if values := req.ToURLValues(); !reflect.DeepEqual(values, expected) {
+			t.Fatalf("expected %v, got %v", expected, values)
+		}
+	})
+	t.Run("should forward fields and files as multipart", func(t *testing.T) {
+		original := FormData(newMultipartRequest(t, map[string]string{
+			"name":                  "John",
+			"attachments[0][title]": "Resume",
+		}, map[string]string{
+			"attachments[0][file]": "resume content",
+		}))
+
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		if err := original.ToMultipart(writer); err != nil {
+			t.Fatal(err)
+		}
+		writer.Close()
+		req := httptest.NewRequest(http.MethodPost, "/", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		forwarded := FormData(req)
+
+		if forwarded.GetString("name") != "John" || forwarded.GetString("attachments[0][title]") != "Resume" {
+			t.Fatalf("expected the fields forwarded, got %v", forwarded.ToMap())
+		}
+		file, ok := forwarded.GetFile("attachments[0][file]")
+		if !ok || file.Filename != "attachments[0][file].txt" {
+			t.Fatalf("expected the file forwarded, got %v", forwarded.ToMap())
+		}
+		src, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer src.Close()
+		if content, _ := io.ReadAll(src); string(content) != "resume content" {
+			t.Fatalf("expected the file content forwarded, got %q", content)
+		}
+	})
+}
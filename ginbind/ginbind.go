@@ -0,0 +1,107 @@
+// Package ginbind provides gin bindings backed by inrequest, so handlers keep using c.ShouldBindWith
+// while reading nested bracket keys such as user[address][city] and uploaded files.
+package ginbind
+
+import (
+	"net/http"
+
+	"github.com/ezartsh/inrequest"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// Bindings for c.ShouldBindWith(&dst, ginbind.Form) and friends. Decoded values are checked with
+// binding.Validator like the gin bindings do, so `binding:"required"` tags keep working.
+var (
+	// Form binds urlencoded and multipart bodies with inrequest.FormDataE.
+	Form binding.Binding = formBinding{}
+	// Query binds the query string with inrequest.QueryE.
+	Query binding.Binding = queryBinding{}
+	// JSON binds json bodies with inrequest.Json.
+	JSON binding.Binding = jsonBinding{}
+	// Default picks the parser from the Content-Type with inrequest.Parse.
+	Default binding.Binding = parseBinding{}
+)
+
+// Bindings holds the bindings of New, parsing under its options.
+type Bindings struct {
+	Form    binding.Binding
+	Query   binding.Binding
+	JSON    binding.Binding
+	Default binding.Binding
+}
+
+// New returns bindings parsing under opts, e.g. New(inrequest.WithMaxMemory(8 << 20)).Form.
+func New(opts ...inrequest.Option) *Bindings {
+	return &Bindings{
+		Form:    formBinding{opts: opts},
+		Query:   queryBinding{opts: opts},
+		JSON:    jsonBinding{opts: opts},
+		Default: parseBinding{opts: opts},
+	}
+}
+
+type formBinding struct {
+	opts []inrequest.Option
+}
+
+func (formBinding) Name() string {
+	return "inrequest-form"
+}
+
+func (b formBinding) Bind(r *http.Request, obj interface{}) error {
+	request, err := inrequest.FormDataE(r, b.opts...)
+	return bind(request, err, obj)
+}
+
+type queryBinding struct {
+	opts []inrequest.Option
+}
+
+func (queryBinding) Name() string {
+	return "inrequest-query"
+}
+
+func (b queryBinding) Bind(r *http.Request, obj interface{}) error {
+	request, err := inrequest.QueryE(r, b.opts...)
+	return bind(request, err, obj)
+}
+
+type jsonBinding struct {
+	opts []inrequest.Option
+}
+
+func (jsonBinding) Name() string {
+	return "inrequest-json"
+}
+
+func (b jsonBinding) Bind(r *http.Request, obj interface{}) error {
+	request, err := inrequest.Json(r, b.opts...)
+	return bind(request, err, obj)
+}
+
+type parseBinding struct {
+	opts []inrequest.Option
+}
+
+func (parseBinding) Name() string {
+	return "inrequest"
+}
+
+func (b parseBinding) Bind(r *http.Request, obj interface{}) error {
+	request, err := inrequest.Parse(r, b.opts...)
+	return bind(request, err, obj)
+}
+
+// bind fills obj from a parsed request and validates it like the gin bindings.
+func bind(request inrequest.Request, err error, obj interface{}) error {
+	if err != nil {
+		return err
+	}
+	if err = request.ToBind(obj); err != nil {
+		return err
+	}
+	if binding.Validator == nil {
+		return nil
+	}
+	return binding.Validator.ValidateStruct(obj)
+}
@@ -0,0 +1,66 @@
+package ginbind
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ezartsh/inrequest"
+	"github.com/gin-gonic/gin"
+)
+
+func TestBindings(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	type Profile struct {
+		User struct {
+			Name string `form:"name" binding:"required"`
+		} `form:"user"`
+		Tags []string `form:"tags"`
+	}
+
+	t.Run("should bind nested bracket keys with ShouldBindWith", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("user[name]=John&tags[0]=a&tags[1]=b"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = req
+		var profile Profile
+		if err := c.ShouldBindWith(&profile, Form); err != nil {
+			t.Fatal(err)
+		}
+		if profile.User.Name != "John" || len(profile.Tags) != 2 {
+			t.Fatalf("unexpected profile %+v", profile)
+		}
+	})
+	t.Run("should validate binding tags", func(t *testing.T) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/?tags[0]=a", nil)
+		var profile Profile
+		if err := c.ShouldBindWith(&profile, Query); err == nil {
+			t.Fatal("expected the missing user name to fail validation")
+		}
+	})
+	t.Run("should pick the parser from the content type", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"user":{"name":"John"}}`))
+		req.Header.Set("Content-Type", "application/json")
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = req
+		var profile Profile
+		if err := c.ShouldBindWith(&profile, Default); err != nil || profile.User.Name != "John" {
+			t.Fatalf("unexpected profile %+v (%v)", profile, err)
+		}
+	})
+	t.Run("should parse under the options given to New", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"user":{"name":"John"}}`))
+		req.Header.Set("Content-Type", "application/json")
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = req
+		var profile Profile
+		var tooLarge *inrequest.RequestTooLargeError
+		if err := c.ShouldBindWith(&profile, New(inrequest.WithMaxBodySize(8)).JSON); !errors.As(err, &tooLarge) {
+			t.Fatalf("expected body too large error, got %v", err)
+		}
+	})
+}
@@ -1,3 +1,3 @@
 module github.com/ezartsh/inrequest
 
-go 1.17.0
+go 1.21
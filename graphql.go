@@ -0,0 +1,210 @@
+package inrequest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type GraphqlRequest struct {
+	requestValues
+	query         string
+	operationName string
+	extensions    RequestValue
+	options       *Options
+}
+
+type graphqlPayload struct {
+	Query         string       `json:"query"`
+	OperationName string       `json:"operationName"`
+	Variables     RequestValue `json:"variables"`
+	Extensions    RequestValue `json:"extensions"`
+}
+
+/*
+Reading a graphql operation from a json body, a GET query string or a
+multipart request following the graphql multipart request spec
+e.g. operations={"query":"...","variables":{"file":null}}, map={"0":["variables.file"]}
+and a file part named "0" gives variables {"file": *multipart.FileHeader}
+*/
+func Graphql(r *http.Request, opts ...Option) (GraphqlRequest, error) {
+	options := requestOptions(r, opts)
+	var payload graphqlPayload
+	var err error
+
+	switch mediaType := requestMediaType(r); {
+	case mediaType == "multipart/form-data":
+		_, err = parseBody(r, options, formMediaTypes, func(r *http.Request) (RequestValue, error) {
+			parsed, parseErr := parseGraphqlMultipart(r, options)
+			payload = parsed
+			return nil, parseErr
+		})
+	case mediaTypeAccepted(mediaType, jsonMediaTypes):
+		_, err = parseBody(r, options, jsonMediaTypes, func(r *http.Request) (RequestValue, error) {
+			return nil, json.NewDecoder(r.Body).Decode(&payload)
+		})
+	case r.Method == http.MethodGet:
+		payload, err = parseGraphqlQuery(r)
+	default:
+		err = &ParseError{Err: ErrUnsupportedMediaType}
+	}
+	if err != nil {
+		return GraphqlRequest{requestValues: valuesOf(make(RequestValue), options), options: options}, err
+	}
+	if payload.Variables == nil {
+		payload.Variables = make(RequestValue)
+	}
+	if err = options.transform(payload.Variables); err != nil {
+		return GraphqlRequest{requestValues: valuesOf(make(RequestValue), options), options: options}, &ParseError{Err: err}
+	}
+	return GraphqlRequest{
+		query:         payload.Query,
+		operationName: payload.OperationName,
+		requestValues: valuesOf(payload.Variables, options),
+		extensions:    payload.Extensions,
+		options:       options,
+	}, nil
+}
+
+func parseGraphqlQuery(r *http.Request) (graphqlPayload, error) {
+	values := r.URL.Query()
+	payload := graphqlPayload{Query: values.Get("query"), OperationName: values.Get("operationName")}
+	for name, target := range map[string]*RequestValue{"variables": &payload.Variables, "extensions": &payload.Extensions} {
+		if raw := values.Get(name); raw != "" {
+			if err := json.Unmarshal([]byte(raw), target); err != nil {
+				return payload, &ParseError{Err: fmt.Errorf("graphql %s: %w", name, err)}
+			}
+		}
+	}
+	return payload, nil
+}
+
+func parseGraphqlMultipart(r *http.Request, options *Options) (graphqlPayload, error) {
+	var payload graphqlPayload
+	// Every file goes to disk like ParseMultipartForm(0) would store it, under the upload limits of options.
+	onDisk := options.clone()
+	onDisk.MaxMemory, onDisk.InMemoryUploads = 0, false
+	form, err := readMultipartLimited(r, &onDisk, nil)
+	if err != nil {
+		return payload, err
+	}
+	r.MultipartForm = form
+	operations, ok := form.Value["operations"]
+	if !ok || len(operations) == 0 {
+		return payload, &ParseError{Err: errors.New("graphql: missing operations field")}
+	}
+	if err = json.Unmarshal([]byte(operations[0]), &payload); err != nil {
+		return payload, &ParseError{Err: fmt.Errorf("graphql operations: %w", err)}
+	}
+
+	var fileMap map[string][]string
+	if values, ok := form.Value["map"]; ok && len(values) > 0 {
+		if err := json.Unmarshal([]byte(values[0]), &fileMap); err != nil {
+			return payload, fmt.Errorf("graphql map: %w", err)
+		}
+	}
+	root := RequestValue{"variables": payload.Variables}
+	if payload.Variables == nil {
+		root["variables"] = make(RequestValue)
+	}
+	for key, paths := range fileMap {
+		files, ok := form.File[key]
+		if !ok || len(files) == 0 {
+			return payload, &ParseError{Err: fmt.Errorf("graphql: missing file part %q", key)}
+		}
+		for _, path := range paths {
+			if err := setGraphqlPath(root, strings.Split(path, "."), files[0]); err != nil {
+				return payload, &ParseError{Err: err}
+			}
+		}
+	}
+	payload.Variables = root["variables"].(RequestValue)
+	return payload, nil
+}
+
+// setGraphqlPath replaces the value at a dot path such as variables.files.0 with value.
+func setGraphqlPath(target interface{}, segments []string, value interface{}) error {
+	last := len(segments) == 1
+	switch container := target.(type) {
+	case RequestValue:
+		if last {
+			container[segments[0]] = value
+			return nil
+		}
+		next, ok := container[segments[0]]
+		if !ok {
+			return fmt.Errorf("graphql: map path %q not found in operations", segments[0])
+		}
+		return setGraphqlPath(next, segments[1:], value)
+	case []interface{}:
+		index, err := strconv.Atoi(segments[0])
+		if err != nil || index < 0 || index >= len(container) {
+			return fmt.Errorf("graphql: invalid map index %q", segments[0])
+		}
+		if last {
+			container[index] = value
+			return nil
+		}
+		return setGraphqlPath(container[index], segments[1:], value)
+	}
+	return fmt.Errorf("graphql: map path %q does not point into operations", segments[0])
+}
+
+func (r GraphqlRequest) Query() string {
+	return r.query
+}
+
+func (r GraphqlRequest) OperationName() string {
+	return r.operationName
+}
+
+// Variables returns the operation variables, shared with the request like ToMap.
+func (r GraphqlRequest) Variables() RequestValue {
+	r.invalidate()
+	return r.result
+}
+
+func (r GraphqlRequest) Extensions() RequestValue {
+	return r.extensions
+}
+
+// ToMap returns the operation variables.
+func (r GraphqlRequest) ToMap() RequestValue {
+	r.invalidate()
+	return r.result
+}
+
+// Get returns the variable at a dot or bracket path, e.g. "input.tags[0]".
+func (r GraphqlRequest) Get(path string) (interface{}, bool) {
+	return r.requestValues.Get(path)
+}
+
+// ToBind binds the operation variables into model.
+func (r GraphqlRequest) ToBind(model interface{}) error {
+	return bindHooked(r.options, r.result, model, binderOf(r.options).bind)
+}
+
+// ToBindValidated binds like ToBind, then runs the validator set with SetValidator.
+// Bind and validation failures are returned together as ValidationErrors.
+func (r GraphqlRequest) ToBindValidated(model interface{}) error {
+	return bindValidated(r, model)
+}
+
+func (r GraphqlRequest) ToJsonByte() ([]byte, error) {
+	jsonData, err := r.encode(r.result)
+	if err != nil {
+		return []byte{}, err
+	}
+	return append([]byte(nil), jsonData...), nil
+}
+
+func (r GraphqlRequest) ToJsonString() (string, error) {
+	jsonData, err := r.encode(r.result)
+	if err != nil {
+		return "", err
+	}
+	return string(jsonData), nil
+}
@@ -0,0 +1,166 @@
+package inrequest
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGraphql(t *testing.T) {
+	t.Run("should read a json operation", func(t *testing.T) {
+		body := `{"query":"query User($id: ID!) { user(id: $id) { name } }","operationName":"User","variables":{"id":"42","limit":10}}`
+		req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		result, err := Graphql(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.OperationName() != "User" || !strings.HasPrefix(result.Query(), "query User") {
+			t.Fatalf("unexpected operation %q %q", result.OperationName(), result.Query())
+		}
+		if !reflect.DeepEqual(result.Variables(), RequestValue{"id": "42", "limit": float64(10)}) {
+			t.Fatalf("unexpected variables %v", result.Variables())
+		}
+	})
+	t.Run("should encode changes made to the variables", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query":"{ me }","variables":{"id":"42"}}`))
+		req.Header.Set("Content-Type", "application/json")
+		result, err := Graphql(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result.ToJsonString()
+		result.Variables()["id"] = "7"
+		if s, _ := result.ToJsonString(); s != `{"id":"7"}` {
+			t.Fatalf("expected the changed variables, got %s", s)
+		}
+	})
+	t.Run("should read a GET operation", func(t *testing.T) {
+		query := url.Values{"query": {"{ me { id } }"}, "variables": {`{"first":5}`}}
+		req := httptest.NewRequest(http.MethodGet, "/graphql?"+query.Encode(), nil)
+		result, err := Graphql(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Query() != "{ me { id } }" || result.Variables()["first"] != float64(5) {
+			t.Fatalf("unexpected operation %q %v", result.Query(), result.Variables())
+		}
+	})
+	t.Run("should map multipart files into variables", func(t *testing.T) {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		writer.WriteField("operations", `{"query":"mutation($file: Upload!, $docs: [Upload!]!) { upload(file: $file, docs: $docs) }","variables":{"file":null,"docs":[null,null]}}`)
+		writer.WriteField("map", `{"0":["variables.file"],"1":["variables.docs.0"],"2":["variables.docs.1"]}`)
+		for _, name := range []string{"0", "1", "2"} {
+			part, _ := writer.CreateFormFile(name, "file"+name+".txt")
+			part.Write([]byte("content " + name))
+		}
+		writer.Close()
+		req := httptest.NewRequest(http.MethodPost, "/graphql", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+
+		result, err := Graphql(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		type Variables struct {
+			File *multipart.FileHeader   `json:"file"`
+			Docs []*multipart.FileHeader `json:"docs"`
+		}
+		variables := Variables{}
+		if err = result.ToBind(&variables); err != nil {
+			t.Fatal(err)
+		}
+		if variables.File == nil || variables.File.Filename != "file0.txt" || len(variables.Docs) != 2 || variables.Docs[1].Filename != "file2.txt" {
+			t.Fatalf("unexpected variables %+v", variables)
+		}
+	})
+	t.Run("should store multipart files in the temp directory", func(t *testing.T) {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		writer.WriteField("operations", `{"query":"mutation($file: Upload!) { upload(file: $file) }","variables":{"file":null}}`)
+		writer.WriteField("map", `{"0":["variables.file"]}`)
+		part, _ := writer.CreateFormFile("0", "a.txt")
+		part.Write([]byte("a"))
+		writer.Close()
+		req := httptest.NewRequest(http.MethodPost, "/graphql", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+
+		dir := t.TempDir()
+		result, err := Graphql(req, WithTempDir(dir))
+		if err != nil {
+			t.Fatal(err)
+		}
+		file, err := UploadedFile{FileHeader: result.Variables()["file"].(*multipart.FileHeader)}.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		if f, ok := file.(*os.File); !ok || filepath.Dir(f.Name()) != dir {
+			t.Fatalf("expected the upload stored in %s, got %v", dir, file)
+		}
+	})
+	t.Run("should reject a map pointing outside the operations", func(t *testing.T) {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		writer.WriteField("operations", `{"query":"mutation { upload }","variables":{}}`)
+		writer.WriteField("map", `{"0":["variables.missing.0"]}`)
+		part, _ := writer.CreateFormFile("0", "a.txt")
+		part.Write([]byte("a"))
+		writer.Close()
+		req := httptest.NewRequest(http.MethodPost, "/graphql", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+
+		if _, err := Graphql(req); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+	t.Run("should report a missing operations field as a parse error", func(t *testing.T) {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		writer.WriteField("map", `{}`)
+		writer.Close()
+		req := httptest.NewRequest(http.MethodPost, "/graphql", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+
+		if _, err := Graphql(req); !IsParseError(err) {
+			t.Fatalf("expected parse error, got %v", err)
+		}
+	})
+	t.Run("should apply the upload limits to multipart files", func(t *testing.T) {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		writer.WriteField("operations", `{"query":"mutation($file: Upload!) { upload(file: $file) }","variables":{"file":null}}`)
+		writer.WriteField("map", `{"0":["variables.file"]}`)
+		part, _ := writer.CreateFormFile("0", "a.txt")
+		part.Write([]byte("too large"))
+		writer.Close()
+		req := httptest.NewRequest(http.MethodPost, "/graphql", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+
+		var tooLarge *FileTooLargeError
+		if _, err := Graphql(req, WithMaxFileSize("0", 4)); !errors.As(err, &tooLarge) {
+			t.Fatalf("expected file too large error, got %v", err)
+		}
+	})
+	t.Run("should keep the encoded variables when reading one", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query":"{ me }","variables":{"id":"42"}}`))
+		req.Header.Set("Content-Type", "application/json")
+		result, err := Graphql(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result.ToJsonByte()
+		if id, _ := result.Get("id"); id != "42" || result.encoded.shared {
+			t.Fatalf("expected Get to keep the variables memoized, got id %v", id)
+		}
+	})
+}
@@ -0,0 +1,39 @@
+package inrequest
+
+type HeaderRequest struct {
+	requestValues
+	options *Options
+}
+
+func (r HeaderRequest) ToMap() RequestValue {
+	r.invalidate()
+	return r.result
+}
+
+// ToBind binds the headers into fields tagged `header:"X-Request-Id"`.
+// Header names are matched case-insensitively.
+func (r HeaderRequest) ToBind(model interface{}) error {
+	return bindHooked(r.options, r.result, model, newBinder("header").with(r.options).bind)
+}
+
+// ToBindValidated binds like ToBind, then runs the validator set with SetValidator.
+// Bind and validation failures are returned together as ValidationErrors.
+func (r HeaderRequest) ToBindValidated(model interface{}) error {
+	return bindValidated(r, model)
+}
+
+func (r HeaderRequest) ToJsonByte() ([]byte, error) {
+	jsonData, err := r.encode(r.result)
+	if err != nil {
+		return []byte{}, err
+	}
+	return append([]byte(nil), jsonData...), nil
+}
+
+func (r HeaderRequest) ToJsonString() (string, error) {
+	jsonData, err := r.encode(r.result)
+	if err != nil {
+		return "", err
+	}
+	return string(jsonData), nil
+}
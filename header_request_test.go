@@ -0,0 +1,46 @@
+package inrequest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestHeaders(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("x-request-id", "0123abc")
+	req.Header.Set("X-Retry-Count", "3")
+	req.Header.Add("Accept-Language", "en")
+	req.Header.Add("Accept-Language", "id")
+	req.Header["authorization"] = []string{"Bearer token"}
+
+	t.Run("should map headers by canonical name", func(t *testing.T) {
+		target := RequestValue{
+			"X-Request-Id":    "0123abc",
+			"X-Retry-Count":   3,
+			"Accept-Language": []interface{}{"en", "id"},
+			"Authorization":   "Bearer token",
+		}
+		if result := Headers(req).ToMap(); !reflect.DeepEqual(result, target) {
+			t.Fatalf("Failed mapping headers, expected %v, got %v", target, result)
+		}
+	})
+	t.Run("should bind headers through the header tag", func(t *testing.T) {
+		type Meta struct {
+			RequestID     string   `header:"x-request-id"`
+			Retries       int      `header:"X-Retry-Count"`
+			Languages     []string `header:"Accept-Language"`
+			Authorization string
+			Ignored       string `header:"-"`
+		}
+		meta := Meta{}
+		if err := Headers(req).ToBind(&meta); err != nil {
+			t.Fatal(err)
+		}
+		target := Meta{RequestID: "0123abc", Retries: 3, Languages: []string{"en", "id"}, Authorization: "Bearer token"}
+		if !reflect.DeepEqual(meta, target) {
+			t.Fatalf("Failed binding headers, expected %+v, got %+v", target, meta)
+		}
+	})
+}
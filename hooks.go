@@ -0,0 +1,56 @@
+package inrequest
+
+// BeforeBindHook changes the values about to be bound, e.g. trimming strings. It receives a copy,
+// the request keeps the values it parsed. An error stops the binding and is returned by ToBind.
+type BeforeBindHook func(values RequestValue) error
+
+// AfterBindHook post-processes the bound model, e.g. normalizing a phone number or stamping audit fields.
+// It runs once binding succeeded, an error is returned by ToBind.
+type AfterBindHook func(model interface{}) error
+
+/*
+Binding values into model with bind between the hooks of options, which may be nil
+e.g. WithBeforeBind(trim) and WithAfterBind(stamp) run trim on a copy of values, bind the copy, run stamp on model
+and finally the Validate method of model
+*/
+func bindHooked(options *Options, values RequestValue, model interface{}, bind func(RequestValue, interface{}) error) error {
+	values, err := beforeBind(options, values)
+	if err != nil {
+		return err
+	}
+	if err = bind(values, model); err != nil {
+		return err
+	}
+	return afterBind(options, model)
+}
+
+// beforeBind runs the BeforeBind hooks of options, which may be nil, on a copy of values.
+func beforeBind(options *Options, values RequestValue) (RequestValue, error) {
+	if !options.rewritesValues() {
+		return values, nil
+	}
+	values = deepCopy(values).(RequestValue)
+	for _, hook := range options.BeforeBind {
+		if err := hook(values); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// afterBind runs the AfterBind hooks of options, which may be nil, then the Validate method of model.
+func afterBind(options *Options, model interface{}) error {
+	if options != nil {
+		for _, hook := range options.AfterBind {
+			if err := hook(model); err != nil {
+				return err
+			}
+		}
+	}
+	return validateModel(model)
+}
+
+// rewritesValues reports whether BeforeBind hooks bind a copy of the values, options may be nil.
+func (o *Options) rewritesValues() bool {
+	return o != nil && len(o.BeforeBind) > 0
+}
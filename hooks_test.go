@@ -0,0 +1,126 @@
+package inrequest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBindHooks(t *testing.T) {
+	trim := func(values RequestValue) error {
+		for key, value := range values {
+			if s, ok := value.(string); ok {
+				values[key] = strings.TrimSpace(s)
+			}
+		}
+		return nil
+	}
+	type signup struct {
+		Name  string `json:"name"`
+		Phone string `json:"phone"`
+		Audit string `json:"-"`
+	}
+	stamp := func(model interface{}) error {
+		model.(*signup).Audit = "stamped"
+		return nil
+	}
+
+	t.Run("should bind the values changed before binding", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?name=+john+", nil)
+		request := Query(req, WithBeforeBind(trim), WithAfterBind(stamp))
+		var model signup
+		if err := request.ToBind(&model); err != nil {
+			t.Fatal(err)
+		}
+		if model.Name != "john" || model.Audit != "stamped" {
+			t.Fatalf("unexpected model %+v", model)
+		}
+		if request.ToMap()["name"] != " john " {
+			t.Fatalf("expected the parsed values to be kept, got %q", request.ToMap()["name"])
+		}
+	})
+	t.Run("should run the hooks of a parser on json bodies", func(t *testing.T) {
+		parser := New(Options{BeforeBind: []BeforeBindHook{trim}, AfterBind: []AfterBindHook{stamp}})
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name": " jane ", "phone": "555"}`))
+		req.Header.Set("Content-Type", "application/json")
+		request, err := parser.Json(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var model signup
+		if err := request.ToBind(&model); err != nil {
+			t.Fatal(err)
+		}
+		if model.Name != "jane" || model.Phone != "555" || model.Audit != "stamped" {
+			t.Fatalf("unexpected model %+v", model)
+		}
+		if request.String() != `{"name":" jane ","phone":"555"}` {
+			t.Fatalf("expected the parsed body to be kept, got %s", request.String())
+		}
+	})
+	t.Run("should run the hooks of headers, cookies and whole requests", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?name=+john+", nil)
+		req.Header.Set("Name", " john ")
+		req.AddCookie(&http.Cookie{Name: "name", Value: " john "})
+		binds := map[string]func(model *signup) error{
+			"header": func(model *signup) error {
+				return Headers(req, WithBeforeBind(trim), WithAfterBind(stamp)).ToBind(model)
+			},
+			"cookie": func(model *signup) error {
+				return Cookies(req, WithBeforeBind(trim), WithAfterBind(stamp)).ToBind(model)
+			},
+		}
+		for name, bind := range binds {
+			var model signup
+			if err := bind(&model); err != nil {
+				t.Fatal(err)
+			}
+			if model.Name != "john" || model.Audit != "stamped" {
+				t.Fatalf("unexpected %s model %+v", name, model)
+			}
+		}
+		var model struct {
+			Name   string `in:"query" json:"name"`
+			Header string `in:"header" header:"Name"`
+		}
+		called := false
+		err := BindRequest(req, &model, WithBeforeBind(trim), WithAfterBind(func(interface{}) error {
+			called = true
+			return nil
+		}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if model.Name != "john" || model.Header != "john" || !called {
+			t.Fatalf("unexpected request model %+v (after hook called: %v)", model, called)
+		}
+	})
+	t.Run("should stop binding on a hook error", func(t *testing.T) {
+		failure := errors.New("rejected")
+		called := false
+		req := httptest.NewRequest(http.MethodGet, "/?name=john", nil)
+		err := Query(req, WithBeforeBind(func(RequestValue) error { return failure }), WithAfterBind(func(interface{}) error {
+			called = true
+			return nil
+		})).ToBind(&signup{})
+		if !errors.Is(err, failure) || called {
+			t.Fatalf("expected the before hook error alone, got %v (after hook called: %v)", err, called)
+		}
+	})
+	t.Run("should not run after hooks when binding fails", func(t *testing.T) {
+		called := false
+		req := httptest.NewRequest(http.MethodGet, "/?age=old", nil)
+		var model struct {
+			Age int `json:"age"`
+		}
+		err := Query(req, WithAfterBind(func(interface{}) error {
+			called = true
+			return nil
+		})).ToBind(&model)
+		if !IsBindError(err) || called {
+			t.Fatalf("expected a bind error without the after hook, got %v (called: %v)", err, called)
+		}
+	})
+}
@@ -0,0 +1,115 @@
+package inrequest
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrorTranslator returns the message of a field error in lang, or "" to keep the original message.
+// key is the failed rule such as "required" or "min", "invalid" for a value of the wrong type,
+// args are the field path followed by the rule parameter, e.g. ("fr", "min", "username", "3").
+type ErrorTranslator func(lang string, key string, args ...interface{}) string
+
+var (
+	translatorMu    sync.RWMutex
+	errorTranslator ErrorTranslator
+)
+
+// SetErrorTranslator makes Localize translate field errors with fn, e.g. backed by a message catalog.
+func SetErrorTranslator(fn ErrorTranslator) {
+	translatorMu.Lock()
+	defer translatorMu.Unlock()
+	errorTranslator = fn
+}
+
+func currentTranslator() ErrorTranslator {
+	translatorMu.RLock()
+	defer translatorMu.RUnlock()
+	return errorTranslator
+}
+
+// localizedError is a translated message standing in for the error it wraps.
+type localizedError struct {
+	message string
+	err     error
+}
+
+func (e *localizedError) Error() string {
+	return e.message
+}
+
+func (e *localizedError) Unwrap() error {
+	return e.err
+}
+
+/*
+Translating the field errors of err into lang with the translator set by SetErrorTranslator
+e.g. answering in the language of the client:
+
+	err = inrequest.Localize(err, inrequest.AcceptLanguage(r))
+	inrequest.WriteProblem(w, err)
+
+BindErrors, ValidationErrors and *BindError come back as a copy holding the translated messages,
+whose original errors still match errors.Is. Other errors, or any error without a translator, are returned as is
+*/
+func Localize(err error, lang string) error {
+	translate := currentTranslator()
+	if translate == nil || err == nil {
+		return err
+	}
+	var bindErrs BindErrors
+	var invalid ValidationErrors
+	var bindErr *BindError
+	switch {
+	case errors.As(err, &bindErrs):
+		return BindErrors(localizeFields(bindErrs, lang, translate))
+	case errors.As(err, &invalid):
+		return ValidationErrors(localizeFields(invalid, lang, translate))
+	case errors.As(err, &bindErr):
+		field := localizeFields([]FieldError{{Field: bindErr.Field, Err: bindErr.Err}}, lang, translate)[0]
+		return &BindError{Field: field.Field, Err: field.Err}
+	}
+	return err
+}
+
+// localizeFields copies fields with their errors translated, messages the translator leaves empty are kept.
+func localizeFields(fields []FieldError, lang string, translate ErrorTranslator) []FieldError {
+	localized := make([]FieldError, len(fields))
+	for i, field := range fields {
+		key := field.Tag
+		if key == "" {
+			key = "invalid"
+		}
+		if message := translate(lang, key, field.Field, field.Param); message != "" {
+			field.Err = &localizedError{message: message, err: field.Err}
+		}
+		localized[i] = field
+	}
+	return localized
+}
+
+/*
+Picking the language the client prefers from its Accept-Language header
+e.g. "fr-CH, fr;q=0.9, en;q=0.8" gives "fr-CH". It returns "" without a usable language
+*/
+func AcceptLanguage(r *http.Request) string {
+	best, bestQ := "", 0.0
+	for _, item := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(item), ";")
+		tag = strings.TrimSpace(tag)
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			var err error
+			if q, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+		if tag != "" && tag != "*" && q > bestQ {
+			best, bestQ = tag, q
+		}
+	}
+	return best
+}
@@ -0,0 +1,82 @@
+package inrequest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLocalize(t *testing.T) {
+	messages := map[string]map[string]string{
+		"fr": {"required": "%[1]s est obligatoire", "min": "%[1]s doit contenir au moins %[2]s caractères"},
+	}
+	SetErrorTranslator(func(lang string, key string, args ...interface{}) string {
+		if format, ok := messages[lang][key]; ok {
+			return fmt.Sprintf(format, args...)
+		}
+		return ""
+	})
+	t.Cleanup(func() { SetErrorTranslator(nil) })
+
+	t.Run("should translate field errors into the language", func(t *testing.T) {
+		var signup struct {
+			Username string `json:"username" min:"3"`
+			Email    string `json:"email" required:"true"`
+			Age      int    `json:"age"`
+		}
+		err := Query(httptest.NewRequest(http.MethodGet, "/?username=ab&age=old", nil)).ToBind(&signup)
+		localized := Localize(err, "fr")
+		var fields BindErrors
+		if !errors.As(localized, &fields) || len(fields) != 3 {
+			t.Fatalf("expected three field errors, got %v", localized)
+		}
+		messages := map[string]string{}
+		for _, field := range fields {
+			messages[field.Field] = field.Err.Error()
+		}
+		if messages["username"] != "username doit contenir au moins 3 caractères" || messages["email"] != "email est obligatoire" {
+			t.Fatalf("unexpected messages %v", messages)
+		}
+		if messages["age"] == "" || messages["age"] != errorOf(err, "age").Error() {
+			t.Fatalf("expected the untranslated age message to be kept, got %q", messages["age"])
+		}
+		if !IsBindError(localized) {
+			t.Fatal("expected the localized error to stay a bind error")
+		}
+	})
+	t.Run("should keep errors in languages without messages", func(t *testing.T) {
+		err := BindErrors{{Field: "email", Tag: "required", Err: errors.New("is required")}}
+		if localized := Localize(err, "de"); localized.Error() != err.Error() {
+			t.Fatalf("expected %q, got %q", err.Error(), localized.Error())
+		}
+	})
+	t.Run("should pick the preferred accepted language", func(t *testing.T) {
+		headers := map[string]string{
+			"fr-CH, fr;q=0.9, en;q=0.8": "fr-CH",
+			"en;q=0.5, de;q=0.9, *":     "de",
+			"":                          "",
+			"*, es;q=0":                 "",
+		}
+		for header, lang := range headers {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept-Language", header)
+			if got := AcceptLanguage(req); got != lang {
+				t.Fatalf("header %q: expected %q, got %q", header, lang, got)
+			}
+		}
+	})
+}
+
+// errorOf returns the error of field in err.
+func errorOf(err error, field string) error {
+	var fields BindErrors
+	errors.As(err, &fields)
+	for _, f := range fields {
+		if f.Field == field {
+			return f.Err
+		}
+	}
+	return nil
+}
@@ -2,78 +2,290 @@ package inrequest
 
 import (
 	"encoding/json"
+	"fmt"
+	"log/slog"
+	"mime/multipart"
 	"net/http"
+	"net/textproto"
+	"net/url"
 	"strconv"
 	"strings"
 )
 
-func FormData(r *http.Request) formRequest {
-	r.ParseMultipartForm(0)
-	var forms []GroupRequestProperty
+func FormData(r *http.Request, opts ...Option) FormRequest {
+	request, err := FormDataE(r, opts...)
+	if err != nil {
+		request.options.log(slog.LevelWarn, "inrequest: form parse failed", "error", err)
+	}
+	return request
+}
 
-	if r.MultipartForm != nil {
-		for name, val := range r.MultipartForm.Value {
-			if strings.Contains(name, "[") || len(val) == 1 {
-				forms = append(forms, GroupRequestProperty{Path: name, Value: val[0]})
-			} else {
-				for i, sVal := range val {
-					forms = append(forms, GroupRequestProperty{Path: name + "[" + strconv.Itoa(i) + "]", Value: sVal})
-				}
-			}
+// FormDataE is FormData reporting a malformed or oversized body as a *ParseError
+// instead of an empty result.
+func FormDataE(r *http.Request, opts ...Option) (FormRequest, error) {
+	options := requestOptions(r, opts)
+	parts := newFormParts(options)
+	result, err := parseBody(r, options, formMediaTypes, formParser(options, parts))
+	request := FormRequest{requestValues: valuesOf(result, options), options: options}
+	// After a timeout the abandoned parser may still be recording parts and storing files.
+	if err == nil {
+		request.parts = parts
+		if r.MultipartForm != nil {
+			request.files = &formFiles{form: r.MultipartForm}
 		}
-		for name := range r.MultipartForm.File {
-			forms = append(forms, GroupRequestProperty{Path: name, Value: r.MultipartForm.File[name][0]})
+	}
+	return request, err
+}
+
+func Query(r *http.Request, opts ...Option) QueryRequest {
+	request, err := QueryE(r, opts...)
+	if err != nil {
+		request.options.log(slog.LevelWarn, "inrequest: query parse failed", "error", err)
+	}
+	return request
+}
+
+// QueryE is Query reporting a query string over the key or array index limits as a *ParseError.
+// Past the array index limit the offending keys are still returned in a map.
+func QueryE(r *http.Request, opts ...Option) (QueryRequest, error) {
+	options := requestOptions(r, opts)
+	result, err := expandValues(r.URL.Query(), options)
+	values := valuesOf(result, options)
+	if options.PreserveOrder {
+		values.order = queryOrder(r.URL.RawQuery, options)
+	}
+	return QueryRequest{requestValues: values, options: options}, err
+}
+
+// expandValues maps bracket keys into nested values under the key and array index limits of options.
+func expandValues(values url.Values, options *Options) (RequestValue, error) {
+	properties := valuesProperties(values, options)
+	if options.MaxKeys > 0 && len(properties) > options.MaxKeys {
+		return make(RequestValue), &ParseError{Err: &RequestTooLargeError{Limit: "keys", Max: int64(options.MaxKeys)}}
+	}
+	result, err := mapValues(properties, options)
+	if err == nil {
+		err = options.transform(result)
+	}
+	if err != nil {
+		err = &ParseError{Err: err}
+	}
+	return result, err
+}
+
+/*
+Mapping the request headers by their canonical name
+e.g. "x-request-id: abc" and "Accept: a" + "Accept: b" give
+{"X-Request-Id": "abc", "Accept": ["a", "b"]}
+the binding options such as WithBeforeBind apply to ToBind
+*/
+func Headers(r *http.Request, opts ...Option) HeaderRequest {
+	result := make(RequestValue, len(r.Header))
+	for name, values := range r.Header {
+		key := textproto.CanonicalMIMEHeaderKey(name)
+		if len(values) == 1 {
+			result[key] = actualTypeOf(values[0])
+			continue
 		}
+		items := make([]interface{}, len(values))
+		for i, value := range values {
+			items[i] = actualTypeOf(value)
+		}
+		result[key] = items
 	}
-	return formRequest{result: mapValuesOf(forms)}
+	return HeaderRequest{requestValues: valuesOf(result, nil), options: requestOptions(r, opts)}
 }
 
-func Query(r *http.Request) queryRequest {
-	var forms []GroupRequestProperty
-	var values = r.URL.Query()
-	for key, value := range values {
-		if strings.Contains(key, "[") || len(value) == 1 {
-			forms = append(forms, GroupRequestProperty{Path: key, Value: value[0]})
-		} else {
-			for i, sVal := range value {
-				forms = append(forms, GroupRequestProperty{Path: key + "[" + strconv.Itoa(i) + "]", Value: sVal})
+// Cookies maps the request cookies with the same conversion rules as Query,
+// e.g. "visits=12" gives {"visits": 12} and a repeated cookie name gives a slice.
+// The binding options such as WithBeforeBind apply to ToBind.
+func Cookies(r *http.Request, opts ...Option) CookieRequest {
+	values := make(url.Values)
+	for _, cookie := range r.Cookies() {
+		values.Add(cookie.Name, cookie.Value)
+	}
+	return CookieRequest{requestValues: valuesOf(mapValuesOf(valuesProperties(values, nil), nil), nil), options: requestOptions(r, opts)}
+}
+
+func Json(r *http.Request, opts ...Option) (JsonRequest, error) {
+	options := requestOptions(r, opts)
+	if !options.PreserveOrder {
+		result, err := parseBody(r, options, jsonMediaTypes, parseJson)
+		return JsonRequest{requestValues: valuesOf(result, options), options: options}, err
+	}
+	order := &keyOrder{}
+	result, err := parseBody(r, options, jsonMediaTypes, orderedJsonParser(order))
+	values := valuesOf(result, options)
+	// After a timeout the abandoned parser may still be recording keys.
+	if err == nil {
+		values.order = order
+	}
+	return JsonRequest{requestValues: values, options: options}, err
+}
+
+func Xml(r *http.Request, opts ...Option) (XmlRequest, error) {
+	options := requestOptions(r, opts)
+	result, err := parseBody(r, options, xmlMediaTypes, parseXml)
+	return XmlRequest{requestValues: valuesOf(result, options), options: options}, err
+}
+
+func Msgpack(r *http.Request, opts ...Option) (MsgpackRequest, error) {
+	options := requestOptions(r, opts)
+	result, err := parseBody(r, options, msgpackMediaTypes, parseMsgpack)
+	return MsgpackRequest{requestValues: valuesOf(result, options), options: options}, err
+}
+
+func Cbor(r *http.Request, opts ...Option) (CborRequest, error) {
+	options := requestOptions(r, opts)
+	result, err := parseBody(r, options, cborMediaTypes, parseCbor)
+	return CborRequest{requestValues: valuesOf(result, options), options: options}, err
+}
+
+// formParser reads multipart and urlencoded bodies under options, recording into parts, which may be nil.
+func formParser(options *Options, parts *formParts) bodyParser {
+	return func(r *http.Request) (RequestValue, error) {
+		// ParseForm first, ParseMultipartForm hides its errors behind ErrNotMultipart for urlencoded bodies.
+		if err := r.ParseForm(); err != nil {
+			return make(RequestValue), &ParseError{Err: err}
+		}
+		if options.limitsUploads() && requestMediaType(r) == "multipart/form-data" {
+			form, err := readMultipartLimited(r, options, parts)
+			if err != nil {
+				return make(RequestValue), &ParseError{Err: err}
+			}
+			r.MultipartForm = form
+		} else if err := r.ParseMultipartForm(options.MaxMemory); err != nil && err != http.ErrNotMultipart {
+			return make(RequestValue), &ParseError{Err: err}
+		}
+		values := r.PostForm
+		var files map[string][]*multipart.FileHeader
+		if r.MultipartForm != nil {
+			if options.SanitizeFilenames {
+				sanitizeFilenames(r.MultipartForm)
 			}
+			values, files = r.MultipartForm.Value, r.MultipartForm.File
+		}
+		if options.MergeQuery {
+			values = withQuery(values, files, r.URL.Query())
+		}
+		forms := valuesProperties(values, options)
+		forms = append(forms, filesProperties(files)...)
+		result, err := mapValues(forms, options)
+		if err != nil {
+			return make(RequestValue), &ParseError{Err: err}
+		}
+		// Json parts are set once the values are converted, so their strings and arrays stay as decoded.
+		var buf [8]string
+		for _, property := range parts.properties() {
+			if !setSegments(result, limitSegments(options.keySegments(property.Path, buf[:0]), options.maxDepth()), property.Value) {
+				options.log(slog.LevelDebug, "inrequest: dropped a json part conflicting with nested values", "key", property.Path)
+			}
+		}
+		return result, nil
+	}
+}
+
+// withQuery adds the query values whose key the body does not hold as a value or a file, leaving body untouched.
+func withQuery(body url.Values, files map[string][]*multipart.FileHeader, query url.Values) url.Values {
+	merged := make(url.Values, len(body)+len(query))
+	for key, values := range query {
+		if _, ok := files[key]; !ok {
+			merged[key] = values
 		}
 	}
-	return queryRequest{result: mapValuesOf(forms)}
+	for key, values := range body {
+		merged[key] = values
+	}
+	return merged
 }
 
-func Json(r *http.Request) (jsonRequest, error) {
+func parseJson(r *http.Request) (RequestValue, error) {
 	var result RequestValue
 	err := json.NewDecoder(r.Body).Decode(&result)
+	return result, err
+}
 
-	return jsonRequest{result: result}, err
+/*
+Listing the values of a form or query string as paths
+e.g. repeated key "names" with 2 values is listed as names[0] and names[1],
+and every value of an empty bracket key such as "tags[]" is pushed as tags[0], tags[1] in order.
+A repeated plain key keeps a single value under the FirstWins and LastWins policies of options, which may be nil
+*/
+func valuesProperties(values url.Values, options *Options) []GroupRequestProperty {
+	var forms []GroupRequestProperty
+	policy := options.duplicateKeys()
+	for key, value := range values {
+		if len(value) > 1 && policy != Combine && !strings.Contains(key, "[") {
+			if policy == LastWins {
+				value = value[len(value)-1:]
+			}
+			forms = append(forms, GroupRequestProperty{Path: key, Value: value[0]})
+			continue
+		}
+		if !strings.Contains(key, "[]") && (strings.Contains(key, "[") || len(value) == 1) {
+			forms = append(forms, GroupRequestProperty{Path: key, Value: value[0]})
+			continue
+		}
+		for i, sVal := range value {
+			forms = append(forms, GroupRequestProperty{Path: indexedKey(key, i), Value: sVal})
+		}
+	}
+	return forms
 }
 
-func mapValuesOf(queries []GroupRequestProperty) RequestValue {
-	maps := make(RequestValue)
-	mapQuery := groupMapKey(queries)
-	for key, query := range mapQuery {
-		if _, ok := maps[key]; !ok {
-			maps[key] = make(RequestValue)
+// filesProperties lists uploaded files like valuesProperties, several files sent under one name become a slice.
+func filesProperties(files map[string][]*multipart.FileHeader) []GroupRequestProperty {
+	var forms []GroupRequestProperty
+	for name, headers := range files {
+		if !strings.Contains(name, "[]") && (strings.Contains(name, "[") || len(headers) == 1) {
+			forms = append(forms, GroupRequestProperty{Path: name, Value: headers[0]})
+			continue
 		}
-		for _, v := range query {
-			transformDotPathToMap(&maps, v.Path, v.Value)
+		for i, header := range headers {
+			forms = append(forms, GroupRequestProperty{Path: indexedKey(name, i), Value: header})
 		}
 	}
-	fixValueToActualType(&maps)
+	return forms
+}
+
+// indexedKey numbers the i-th value of a repeated key, in its first empty bracket when it has one,
+// e.g. "names" becomes "names[1]" and "items[][id]" becomes "items[1][id]".
+func indexedKey(key string, i int) string {
+	if j := strings.Index(key, "[]"); j >= 0 {
+		return key[:j+1] + strconv.Itoa(i) + key[j+1:]
+	}
+	return key + "[" + strconv.Itoa(i) + "]"
+}
+
+// mapValuesOf nests the listed values into a map under options, which may be nil.
+func mapValuesOf(queries []GroupRequestProperty, options *Options) RequestValue {
+	maps, _ := mapValues(queries, options)
 	return maps
 }
 
-func groupMapKey(data []GroupRequestProperty) GroupRequest {
-	mapQuery := make(GroupRequest)
-	for _, p := range data {
-		dotKey := replaceBracketKeyIntoDotKey(p.Path)
-		var paths = strings.Split(dotKey, ".")
-		mapQuery[paths[0]] = append(mapQuery[paths[0]], GroupRequestProperty{
-			Path:  dotKey,
-			Value: p.Value,
-		})
+// mapValues is mapValuesOf reporting array indexes above the configured maximum and, with strict keys, malformed keys.
+func mapValues(queries []GroupRequestProperty, options *Options) (RequestValue, error) {
+	maps := make(RequestValue)
+	maxDepth := options.maxDepth()
+	strict := options.strictKeys()
+	var firstErr error
+	var buf [8]string
+	for _, query := range queries {
+		if strict && firstErr == nil {
+			if reason := malformedKey(query.Path, options.dotKeys()); reason != "" {
+				firstErr = fmt.Errorf("%w %q: %s", ErrMalformedKey, query.Path, reason)
+			}
+		}
+		segments := options.keySegments(query.Path, buf[:0])
+		if !setSegments(maps, limitSegments(segments, maxDepth), query.Value) {
+			options.log(slog.LevelDebug, "inrequest: dropped a plain value conflicting with nested values", "key", query.Path)
+			if strict && firstErr == nil {
+				firstErr = fmt.Errorf("%w %q: conflicts with a plain value", ErrMalformedKey, query.Path)
+			}
+		}
+	}
+	if err := fixValueToActualType(&maps, options); err != nil && firstErr == nil {
+		firstErr = err
 	}
-	return mapQuery
+	return maps, firstErr
 }
@@ -3,70 +3,13 @@ package inrequest
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 )
 
-func TestGroupMapKey(t *testing.T) {
-	t.Run("should get grouped keys from 1 dimensional object", func(t *testing.T) {
-		var source = []GroupRequestProperty{
-			{Path: "name", Value: "John Doe"},
-			{Path: "description", Value: "I'm a fullstack developer"},
-		}
-		target := GroupRequest{
-			"name": []GroupRequestProperty{
-				{
-					Path:  "name",
-					Value: "John Doe",
-				},
-			},
-			"description": []GroupRequestProperty{
-				{
-					Path:  "description",
-					Value: "I'm a fullstack developer",
-				},
-			},
-		}
-
-		groupKeys := groupMapKey(source)
-
-		if !reflect.DeepEqual(groupKeys, target) {
-			t.Fatalf("Failed group keys %v, %v, got %v", source, target, groupKeys)
-		}
-	})
-	t.Run("should get grouped keys from 1 dimension object and 2 dimensional array", func(t *testing.T) {
-		var source = []GroupRequestProperty{
-			{Path: "names[0]", Value: "John Doe"},
-			{Path: "names[1]", Value: "Michael"},
-			{Path: "description", Value: "They are fullstack developers"},
-		}
-		target := GroupRequest{
-			"names": []GroupRequestProperty{
-				{
-					Path:  "names.0",
-					Value: "John Doe",
-				},
-				{
-					Path:  "names.1",
-					Value: "Michael",
-				},
-			},
-			"description": []GroupRequestProperty{
-				{
-					Path:  "description",
-					Value: "They are fullstack developers",
-				},
-			},
-		}
-
-		groupKeys := groupMapKey(source)
-
-		if !reflect.DeepEqual(groupKeys, target) {
-			t.Fatalf("Failed group keys %v, %v, got %v", source, target, groupKeys)
-		}
-	})
-}
-
 func TestMappingValues(t *testing.T) {
 	t.Run("should get 1 dimensional object", func(t *testing.T) {
 		var source []GroupRequestProperty = []GroupRequestProperty{
@@ -78,7 +21,7 @@ func TestMappingValues(t *testing.T) {
 			"description": "I'm a fullstack developer",
 		}
 
-		mappedValues := mapValuesOf(source)
+		mappedValues := mapValuesOf(source, nil)
 
 		if !reflect.DeepEqual(mappedValues, target) {
 			t.Fatalf("Failed mapping values %v, %v, got %v", source, target, mappedValues)
@@ -98,7 +41,7 @@ func TestMappingValues(t *testing.T) {
 			"description": "They are fullstack developers",
 		}
 
-		mappedValues := mapValuesOf(source)
+		mappedValues := mapValuesOf(source, nil)
 
 		if !reflect.DeepEqual(mappedValues, target) {
 			t.Fatalf("Failed mapping values %v, %v, got %v", source, target, mappedValues)
@@ -129,7 +72,7 @@ func TestBindFormRequestToStruct(t *testing.T) {
 		Status:   true,
 	}
 
-	mappedValues := mapValuesOf(source)
+	mappedValues := mapValuesOf(source, nil)
 
 	jsonString, err := json.Marshal(mappedValues)
 	if err != nil {
@@ -149,3 +92,34 @@ func TestBindFormRequestToStruct(t *testing.T) {
 		t.Fatalf("Failed bind values to struct %v, %v, got %v", source, target, bindUser)
 	}
 }
+
+func TestGet(t *testing.T) {
+	body := `{"user":{"address":{"city":"Paris"}},"items":[{"price":10.5},{"price":3}]}`
+	req := httptest.NewRequest(http.MethodPost, "/?filter[status]=active&ids[0]=4", strings.NewReader(body))
+	jsonRequest, err := Json(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := Query(req)
+
+	cases := []struct {
+		request Request
+		path    string
+		value   interface{}
+		found   bool
+	}{
+		{jsonRequest, "user.address.city", "Paris", true},
+		{jsonRequest, "items[1].price", float64(3), true},
+		{jsonRequest, "items.0.price", 10.5, true},
+		{jsonRequest, "items[2].price", nil, false},
+		{jsonRequest, "user.name", nil, false},
+		{query, "filter[status]", "active", true},
+		{query, "ids[0]", 4, true},
+	}
+	for _, c := range cases {
+		value, found := c.request.Get(c.path)
+		if found != c.found || !reflect.DeepEqual(value, c.value) {
+			t.Fatalf("expected %v %v at %s, got %v %v", c.value, c.found, c.path, value, found)
+		}
+	}
+}
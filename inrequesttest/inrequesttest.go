@@ -0,0 +1,125 @@
+// Package inrequesttest builds requests for testing handlers that use inrequest,
+// like net/http/httptest does for handlers in general.
+package inrequesttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/ezartsh/inrequest"
+)
+
+// File is an upload sent by NewMultipartRequestWithFiles.
+// Empty Filename means the last segment of Field, empty ContentType means application/octet-stream.
+type File struct {
+	Field       string
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
+/*
+Building a multipart/form-data POST request
+e.g. NewMultipartRequest(map[string]string{"user[name]": "John"}, map[string][]byte{"avatar": png})
+sends the file under field "avatar" with filename "avatar". Parts are written in sorted key order
+*/
+func NewMultipartRequest(fields map[string]string, files map[string][]byte) *http.Request {
+	uploads := make([]File, 0, len(files))
+	for field, content := range files {
+		uploads = append(uploads, File{Field: field, Content: content})
+	}
+	sort.Slice(uploads, func(i, j int) bool { return uploads[i].Field < uploads[j].Field })
+	return NewMultipartRequestWithFiles(fields, uploads...)
+}
+
+// NewMultipartRequestWithFiles is NewMultipartRequest with control over the filename and content type of
+// every upload, files are written in the given order so a field can hold several.
+func NewMultipartRequestWithFiles(fields map[string]string, files ...File) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for _, key := range sortedKeys(fields) {
+		writer.WriteField(key, fields[key])
+	}
+	for _, file := range files {
+		filename := file.Filename
+		if filename == "" {
+			filename = path.Base(strings.NewReplacer("[", "/", "]", "").Replace(file.Field))
+		}
+		contentType := file.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", `form-data; name="`+quoteEscaper.Replace(file.Field)+`"; filename="`+quoteEscaper.Replace(filename)+`"`)
+		header.Set("Content-Type", contentType)
+		part, _ := writer.CreatePart(header)
+		part.Write(file.Content)
+	}
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+// NewFormRequest builds an application/x-www-form-urlencoded POST request carrying values.
+func NewFormRequest(values url.Values) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(values.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+// NewJsonRequest builds an application/json POST request with v encoded as its body.
+// It panics when v cannot be encoded, as httptest.NewRequest does for invalid input.
+func NewJsonRequest(v interface{}) *http.Request {
+	body, err := json.Marshal(v)
+	if err != nil {
+		panic("inrequesttest: encode json body: " + err.Error())
+	}
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+// NewQueryRequest builds a GET request with values as its query string.
+func NewQueryRequest(values url.Values) *http.Request {
+	return httptest.NewRequest(http.MethodGet, "/?"+values.Encode(), nil)
+}
+
+// Fake is an inrequest.Request holding fixed values, for handlers and services taking a Request.
+// ToBind returns BindErr when it is set.
+type Fake struct {
+	inrequest.JsonRequest
+	BindErr error
+}
+
+// NewFake returns a Fake holding values, bound like a json body.
+func NewFake(values inrequest.RequestValue) *Fake {
+	return &Fake{JsonRequest: inrequest.FromMap(values)}
+}
+
+func (f *Fake) ToBind(model interface{}) error {
+	if f.BindErr != nil {
+		return f.BindErr
+	}
+	return f.JsonRequest.ToBind(model)
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,63 @@
+package inrequesttest
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/ezartsh/inrequest"
+)
+
+func TestBuilders(t *testing.T) {
+	t.Run("should build a multipart request with files", func(t *testing.T) {
+		req := NewMultipartRequest(map[string]string{"user[name]": "John"}, map[string][]byte{"avatar": []byte("png")})
+		form, err := inrequest.FormDataE(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		file, ok := form.GetFile("avatar")
+		if !ok || file.Filename != "avatar" || file.Size != 3 || form.GetString("user.name") != "John" {
+			t.Fatalf("unexpected form %v", form.ToMap())
+		}
+	})
+	t.Run("should name files by the last field segment", func(t *testing.T) {
+		req := NewMultipartRequestWithFiles(nil, File{Field: "attachments[0][file]", Content: []byte("pdf")})
+		form, err := inrequest.FormDataE(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if file, ok := form.GetFile("attachments.0.file"); !ok || file.Filename != "file" {
+			t.Fatalf("unexpected form %v", form.ToMap())
+		}
+	})
+	t.Run("should build form, json and query requests", func(t *testing.T) {
+		values := url.Values{"name": {"John"}}
+		form, _ := inrequest.FormDataE(NewFormRequest(values))
+		json, _ := inrequest.Json(NewJsonRequest(map[string]string{"name": "John"}))
+		query := inrequest.Query(NewQueryRequest(values))
+		for _, req := range []inrequest.Request{form, json, query} {
+			if req.GetString("name") != "John" {
+				t.Fatalf("expected John, got %v", req.ToMap())
+			}
+		}
+	})
+}
+
+func TestFake(t *testing.T) {
+	t.Run("should bind its values", func(t *testing.T) {
+		var user struct {
+			Name string `json:"name"`
+		}
+		var req inrequest.Request = NewFake(inrequest.RequestValue{"name": "John"})
+		if err := req.ToBind(&user); err != nil || user.Name != "John" {
+			t.Fatalf("unexpected user %v (%v)", user, err)
+		}
+	})
+	t.Run("should return the configured bind error", func(t *testing.T) {
+		fake := NewFake(nil)
+		fake.BindErr = errors.New("boom")
+		if err := fake.ToBind(&struct{}{}); err != fake.BindErr {
+			t.Fatalf("expected the bind error, got %v", err)
+		}
+	})
+}
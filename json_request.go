@@ -1,38 +1,160 @@
 package inrequest
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+)
 
-type jsonRequest struct {
-	result RequestValue
+type JsonRequest struct {
+	requestValues
+	options *Options
 }
 
-func (r jsonRequest) ToMap() RequestValue {
+func (r JsonRequest) ToMap() RequestValue {
+	r.invalidate()
 	return r.result
 }
 
-func (r jsonRequest) ToBind(model interface{}) error {
-	jsonData, err := json.Marshal(r.result)
+// ToBind decodes the body into model with encoding/json, or with the binder when another tag name, WithSnakeCaseFields
+// or WithLocation is configured, or the model holds a union registered with RegisterUnion or a field tagged tz.
+func (r JsonRequest) ToBind(model interface{}) error {
+	return bindHooked(r.options, r.result, model, r.bind)
+}
+
+// bind is ToBind for values, the parsed values or the copy the BeforeBind hooks changed.
+func (r JsonRequest) bind(values RequestValue, model interface{}) error {
+	if !r.options.decodesJson() || needsBinder(reflect.TypeOf(model)) {
+		return binderOf(r.options).bind(values, model)
+	}
+	var jsonData []byte
+	var err error
+	if r.options.rewritesValues() {
+		jsonData, err = json.Marshal(values)
+	} else {
+		jsonData, err = r.marshal(values)
+	}
 	if err != nil {
 		return err
 	}
 	if err = json.Unmarshal(jsonData, &model); err != nil {
-		return err
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			if errs := fieldErrors(r.options, values, model); errs != nil {
+				return errs
+			}
+		}
+		return jsonBindError("", err)
 	}
-	return nil
+	return binderOf(r.options).check(values, model)
+}
+
+// ToBindValidated binds like ToBind, then runs the validator set with SetValidator.
+// Bind and validation failures are returned together as ValidationErrors.
+func (r JsonRequest) ToBindValidated(model interface{}) error {
+	return bindValidated(r, model)
 }
 
-func (r jsonRequest) ToByte() ([]byte, error) {
-	jsonData, err := json.Marshal(r.result)
+func (r JsonRequest) ToByte() ([]byte, error) {
+	jsonData, err := r.encode(r.result)
 	if err != nil {
 		return []byte{}, err
 	}
-	return jsonData, nil
+	return append([]byte(nil), jsonData...), nil
 }
 
-func (r jsonRequest) ToString() (string, error) {
-	jsonData, err := json.Marshal(r.result)
+func (r JsonRequest) ToString() (string, error) {
+	jsonData, err := r.encode(r.result)
 	if err != nil {
 		return "", err
 	}
 	return string(jsonData), nil
 }
+
+func (r JsonRequest) ToJsonByte() ([]byte, error) {
+	return r.ToByte()
+}
+
+func (r JsonRequest) ToJsonString() (string, error) {
+	return r.ToString()
+}
+
+// fieldErrors collects every failing field of values by binding a scratch copy of model under options,
+// which may be nil, encoding/json only reports the first type mismatch.
+func fieldErrors(options *Options, values RequestValue, model interface{}) BindErrors {
+	t := reflect.TypeOf(model)
+	if t == nil || t.Kind() != reflect.Ptr {
+		return nil
+	}
+	var errs BindErrors
+	b := newBinder("json")
+	if options != nil {
+		b.ctx = options.ctx
+	}
+	errors.As(b.bind(values, reflect.New(t.Elem()).Interface()), &errs)
+	return errs
+}
+
+/*
+Decoding a json body straight into model with encoding/json, without building the map ToBind works from
+e.g. for large payloads:
+
+	var order Order
+	err := inrequest.JsonBind(r, &order, inrequest.WithDisallowUnknownFields())
+
+The body limits, timeout and Content-Encoding options apply as for Json, MaxKeys and the constraint tags
+are not checked. An unknown field is reported in BindErrors like ToBind does, a type mismatch as a *BindError.
+With a tag name other than json, WithSnakeCaseFields, WithLocation, BeforeBind hooks or a model holding a union
+or a tz tag, the body is bound through Json and ToBind instead
+*/
+func JsonBind(r *http.Request, model interface{}, opts ...Option) error {
+	options := requestOptions(r, opts)
+	if !options.decodesJson() || options.rewritesValues() || needsBinder(reflect.TypeOf(model)) {
+		request, err := Json(r, optionsOf(options))
+		if err != nil {
+			return err
+		}
+		return request.ToBind(model)
+	}
+	_, err := parseBody(r, options, jsonMediaTypes, func(r *http.Request) (RequestValue, error) {
+		decoder := json.NewDecoder(r.Body)
+		if options.DisallowUnknownFields {
+			decoder.DisallowUnknownFields()
+		}
+		if options.UseNumber {
+			decoder.UseNumber()
+		}
+		return nil, jsonDecodeError(decoder.Decode(model))
+	})
+	if err != nil {
+		return err
+	}
+	return afterBind(options, model)
+}
+
+// jsonDecodeError reports the errors of decoding into a model the way ToBind does.
+func jsonDecodeError(err error) error {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return jsonBindError("", err)
+	}
+	if field, ok := unknownFieldOf(err); ok {
+		return BindErrors{{Field: field, Tag: "unknown", Err: ErrUnknownField}}
+	}
+	return err
+}
+
+// unknownFieldOf returns the field named by the error encoding/json reports under DisallowUnknownFields.
+func unknownFieldOf(err error) (string, bool) {
+	if err == nil {
+		return "", false
+	}
+	const prefix = `json: unknown field "`
+	message := err.Error()
+	if !strings.HasPrefix(message, prefix) || !strings.HasSuffix(message, `"`) {
+		return "", false
+	}
+	return message[len(prefix) : len(message)-1], true
+}
@@ -0,0 +1,131 @@
+package inrequest
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrMergeConflict is reported by Merge under RejectConflicts for a key present in both requests.
+var ErrMergeConflict = errors.New("merge conflict")
+
+// MergePolicy decides which value Merge keeps for a key present in both requests.
+// Nested maps are always merged key by key, the policy applies to the values inside them.
+type MergePolicy int
+
+const (
+	// PreferLast keeps the value of the second request.
+	PreferLast MergePolicy = iota
+	// PreferFirst keeps the value of the first request.
+	PreferFirst
+	// RejectConflicts fails the merge with ErrMergeConflict.
+	RejectConflicts
+)
+
+// MergedRequest holds the values of several requests combined by Merge or MergeWith.
+type MergedRequest struct {
+	requestValues
+}
+
+/*
+Combining the values of two requests into one bindable request
+e.g. filters from the query string with the payload of a json body:
+
+	merged, err := inrequest.Merge(inrequest.Query(r), body, inrequest.PreferLast)
+*/
+func Merge(a Request, b Request, policy MergePolicy) (MergedRequest, error) {
+	result, err := mergeValues(a.ToMap(), b.ToMap(), policy, "")
+	if err != nil {
+		return mergedOf(make(RequestValue)), err
+	}
+	return mergedOf(result, redactedOf(a), redactedOf(b)), nil
+}
+
+// MergeWith combines the request with other, the values of other win for keys present in both.
+func (v requestValues) MergeWith(other Request) MergedRequest {
+	result, _ := mergeValues(v.result, other.ToMap(), PreferLast, "")
+	return mergedOf(result, v.redact, redactedOf(other))
+}
+
+// mergedOf wraps merged values, keeping the keys redacted in any of the requests redacted.
+func mergedOf(result RequestValue, redacted ...map[string]bool) MergedRequest {
+	values := valuesOf(result, nil)
+	for _, redact := range redacted {
+		for path := range redact {
+			if values.redact == nil {
+				values.redact = make(map[string]bool)
+			}
+			values.redact[path] = true
+		}
+	}
+	return MergedRequest{requestValues: values}
+}
+
+// redactedOf returns the redacted field paths of a request, none for requests of other packages.
+func redactedOf(r Request) map[string]bool {
+	if values, ok := r.(interface{ redactedPaths() map[string]bool }); ok {
+		return values.redactedPaths()
+	}
+	return nil
+}
+
+func mergeValues(a RequestValue, b RequestValue, policy MergePolicy, path string) (RequestValue, error) {
+	result := make(RequestValue, len(a)+len(b))
+	for key, value := range a {
+		result[key] = value
+	}
+	for key, value := range b {
+		current, ok := result[key]
+		if !ok {
+			result[key] = value
+			continue
+		}
+		currentMap, currentIsMap := current.(RequestValue)
+		valueMap, valueIsMap := value.(RequestValue)
+		switch {
+		case currentIsMap && valueIsMap:
+			merged, err := mergeValues(currentMap, valueMap, policy, joinPath(path, key))
+			if err != nil {
+				return nil, err
+			}
+			result[key] = merged
+		case policy == RejectConflicts:
+			return nil, fmt.Errorf("%w: %s", ErrMergeConflict, joinPath(path, key))
+		case policy == PreferLast:
+			result[key] = value
+		}
+	}
+	return result, nil
+}
+
+func (r MergedRequest) ToMap() RequestValue {
+	r.invalidate()
+	return r.result
+}
+
+// ToBind binds the merged values into model, fields are named by their `query` or `form` tag
+// and fall back to `json` or the configured tag name.
+func (r MergedRequest) ToBind(model interface{}) error {
+	return binderOf(nil, "query", "form").bind(r.result, model)
+}
+
+// ToBindValidated binds like ToBind, then runs the validator set with SetValidator.
+// Bind and validation failures are returned together as ValidationErrors.
+func (r MergedRequest) ToBindValidated(model interface{}) error {
+	return bindValidated(r, model)
+}
+
+func (r MergedRequest) ToJsonByte() ([]byte, error) {
+	jsonData, err := r.encode(r.result)
+	if err != nil {
+		return []byte{}, err
+	}
+	return append([]byte(nil), jsonData...), nil
+}
+
+func (r MergedRequest) ToJsonString() (string, error) {
+	jsonData, err := r.encode(r.result)
+	if err != nil {
+		return "", err
+	}
+	return string(jsonData), nil
+}
@@ -0,0 +1,57 @@
+package inrequest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/?page=2&name=query&filter[status]=active", strings.NewReader(`{"name":"John","filter":{"role":"admin"}}`))
+	query := Query(req)
+	body, err := Json(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should merge nested maps and prefer the last value", func(t *testing.T) {
+		merged, err := Merge(query, body, PreferLast)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := RequestValue{"page": 2, "name": "John", "filter": RequestValue{"status": "active", "role": "admin"}}
+		if !reflect.DeepEqual(merged.ToMap(), expected) {
+			t.Fatalf("expected %v, got %v", expected, merged.ToMap())
+		}
+	})
+	t.Run("should prefer the first value", func(t *testing.T) {
+		merged, err := Merge(query, body, PreferFirst)
+		if err != nil || merged.GetString("name") != "query" {
+			t.Fatalf("expected the query name, got %v %v", merged.ToMap(), err)
+		}
+	})
+	t.Run("should reject conflicting keys", func(t *testing.T) {
+		if _, err := Merge(query, body, RejectConflicts); !errors.Is(err, ErrMergeConflict) {
+			t.Fatalf("expected merge conflict, got %v", err)
+		}
+	})
+	t.Run("should bind the merged values", func(t *testing.T) {
+		var search struct {
+			Page   int    `query:"page"`
+			Name   string `json:"name"`
+			Filter struct {
+				Status string `json:"status"`
+				Role   string `json:"role"`
+			} `json:"filter"`
+		}
+		if err := query.MergeWith(body).ToBind(&search); err != nil {
+			t.Fatal(err)
+		}
+		if search.Page != 2 || search.Name != "John" || search.Filter.Status != "active" || search.Filter.Role != "admin" {
+			t.Fatalf("unexpected bind %+v", search)
+		}
+	})
+}
@@ -0,0 +1,49 @@
+package inrequest
+
+import (
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// Metrics observes every body parse, e.g. to export payload sizes and parse latency per endpoint.
+// ObserveParse receives the media type of the request, the time spent parsing, the body bytes read
+// before any Content-Encoding is undone, and the number of uploaded files. Failed parses are observed too.
+type Metrics interface {
+	ObserveParse(contentType string, dur time.Duration, bytes int64, files int)
+}
+
+// parseBody runs the body parser, reporting the parse to the Metrics of options.
+func parseBody(r *http.Request, options *Options, accepts []string, parse bodyParser) (RequestValue, error) {
+	if options.Metrics == nil {
+		return runParser(r, options, accepts, parse)
+	}
+	start := time.Now()
+	counter := &metricsReader{}
+	if r.Body != nil {
+		counter.ReadCloser = r.Body
+		r.Body = counter
+	}
+	value, err := runParser(r, options, accepts, parse)
+	files := 0
+	if r.MultipartForm != nil {
+		for _, headers := range r.MultipartForm.File {
+			files += len(headers)
+		}
+	}
+	options.Metrics.ObserveParse(requestMediaType(r), time.Since(start), atomic.LoadInt64(&counter.n), files)
+	return value, err
+}
+
+// metricsReader counts the body bytes read, safely for a parser abandoned after a timeout.
+type metricsReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (m *metricsReader) Read(p []byte) (int, error) {
+	n, err := m.ReadCloser.Read(p)
+	atomic.AddInt64(&m.n, int64(n))
+	return n, err
+}
@@ -0,0 +1,62 @@
+package inrequest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type recordedParse struct {
+	contentType string
+	dur         time.Duration
+	bytes       int64
+	files       int
+}
+
+type recordingMetrics struct {
+	parses []recordedParse
+}
+
+func (m *recordingMetrics) ObserveParse(contentType string, dur time.Duration, bytes int64, files int) {
+	m.parses = append(m.parses, recordedParse{contentType, dur, bytes, files})
+}
+
+func TestMetrics(t *testing.T) {
+	t.Run("should observe the size of a json body", func(t *testing.T) {
+		metrics := &recordingMetrics{}
+		body := `{"name":"John"}`
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+		if _, err := Json(req, WithMetrics(metrics)); err != nil {
+			t.Fatal(err)
+		}
+		if len(metrics.parses) != 1 {
+			t.Fatalf("expected one observed parse, got %d", len(metrics.parses))
+		}
+		if parse := metrics.parses[0]; parse.contentType != "application/json" || parse.bytes != int64(len(body)) || parse.files != 0 {
+			t.Fatalf("unexpected observation %+v", parse)
+		}
+	})
+	t.Run("should count uploaded files", func(t *testing.T) {
+		metrics := &recordingMetrics{}
+		if _, err := FormDataE(newUploadRequest(t, "avatar", "me.png", "png"), WithMetrics(metrics)); err != nil {
+			t.Fatal(err)
+		}
+		if parse := metrics.parses[0]; parse.contentType != "multipart/form-data" || parse.files != 1 || parse.bytes == 0 {
+			t.Fatalf("unexpected observation %+v", parse)
+		}
+	})
+	t.Run("should observe failed parses", func(t *testing.T) {
+		metrics := &recordingMetrics{}
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":`))
+		req.Header.Set("Content-Type", "application/json")
+		if _, err := Json(req, WithMetrics(metrics)); err == nil {
+			t.Fatal("expected a decode error")
+		}
+		if len(metrics.parses) != 1 {
+			t.Fatalf("expected one observed parse, got %d", len(metrics.parses))
+		}
+	})
+}
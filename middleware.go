@@ -0,0 +1,43 @@
+package inrequest
+
+import (
+	"context"
+	"net/http"
+)
+
+type contextKey struct{}
+
+/*
+Parsing every request once with Parse before next runs, handlers and inner middleware read the result with FromContext
+instead of reading the body again:
+
+	http.Handle("/users", inrequest.Middleware(usersHandler))
+
+A request that cannot be parsed is answered with a json error and never reaches next,
+413 when it is too large, 415 for an unsupported Content-Type and 400 otherwise
+*/
+func Middleware(next http.Handler) http.Handler {
+	return middleware(next, nil)
+}
+
+// Middleware parses like the package Middleware, under the parser options.
+func (p *Parser) Middleware(next http.Handler) http.Handler {
+	return middleware(next, p.with(nil))
+}
+
+func middleware(next http.Handler, opts []Option) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request, err := Parse(r, opts...)
+		if err != nil {
+			writeError(w, errorStatus(err), err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, request)))
+	})
+}
+
+// FromContext returns the request parsed by Middleware.
+func FromContext(ctx context.Context) (Request, bool) {
+	request, ok := ctx.Value(contextKey{}).(Request)
+	return request, ok
+}
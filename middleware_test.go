@@ -0,0 +1,49 @@
+package inrequest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	t.Run("should store the parsed request in the context", func(t *testing.T) {
+		var name string
+		handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			request, ok := FromContext(r.Context())
+			if !ok {
+				t.Fatal("expected a parsed request in the context")
+			}
+			name = request.GetString("name")
+		}))
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"John"}`))
+		req.Header.Set("Content-Type", "application/json")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		if name != "John" {
+			t.Fatalf("expected John, got %q", name)
+		}
+	})
+	t.Run("should answer unparsable requests without calling next", func(t *testing.T) {
+		handler := New(Options{MaxBodySize: 4}).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Fatal("expected next not to run")
+		}))
+		for contentType, status := range map[string]int{
+			"application/json": http.StatusRequestEntityTooLarge,
+			"text/plain":       http.StatusUnsupportedMediaType,
+		} {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"John"}`))
+			req.Header.Set("Content-Type", contentType)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if w.Code != status {
+				t.Fatalf("expected status %d for %s, got %d", status, contentType, w.Code)
+			}
+		}
+	})
+	t.Run("should report no request outside the middleware", func(t *testing.T) {
+		if _, ok := FromContext(httptest.NewRequest(http.MethodGet, "/", nil).Context()); ok {
+			t.Fatal("expected no request")
+		}
+	})
+}
@@ -0,0 +1,253 @@
+package inrequest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"time"
+)
+
+const maxDecodeDepth = 1000
+
+var msgpackMediaTypes = []string{"application/msgpack", "application/x-msgpack", "application/vnd.msgpack"}
+
+var errDecodeDepth = errors.New("maximum nesting depth exceeded")
+
+/*
+Decoding the msgpack body into map, the body must hold a map at its top level
+e.g. {"name": "John", "tags": ["a", "b"]} encoded as msgpack gives the same RequestValue
+as its json counterpart, integers are kept as int and timestamps become time.Time
+*/
+func parseMsgpack(r *http.Request) (RequestValue, error) {
+	decoder := msgpackDecoder{reader: bufio.NewReader(r.Body)}
+	value, err := decoder.decode(0)
+	if err != nil {
+		return make(RequestValue), err
+	}
+	result, ok := value.(RequestValue)
+	if !ok {
+		return make(RequestValue), fmt.Errorf("msgpack: expected a map at top level, got %T", value)
+	}
+	return result, nil
+}
+
+type msgpackDecoder struct {
+	reader *bufio.Reader
+}
+
+func (d *msgpackDecoder) decode(depth int) (interface{}, error) {
+	if depth > maxDecodeDepth {
+		return nil, errDecodeDepth
+	}
+	code, err := d.reader.ReadByte()
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	switch {
+	case code <= 0x7f:
+		return int(code), nil
+	case code >= 0xe0:
+		return int(int8(code)), nil
+	case code >= 0x80 && code <= 0x8f:
+		return d.decodeMap(int(code&0x0f), depth)
+	case code >= 0x90 && code <= 0x9f:
+		return d.decodeArray(int(code&0x0f), depth)
+	case code >= 0xa0 && code <= 0xbf:
+		return d.decodeString(int(code & 0x1f))
+	}
+
+	switch code {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.readLength(code - 0xc4)
+		if err != nil {
+			return nil, err
+		}
+		return d.readBytes(n)
+	case 0xc7, 0xc8, 0xc9:
+		n, err := d.readLength(code - 0xc7)
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeExt(n)
+	case 0xca:
+		bits, err := d.readUint(4)
+		return float64(math.Float32frombits(uint32(bits))), err
+	case 0xcb:
+		bits, err := d.readUint(8)
+		return math.Float64frombits(bits), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		n, err := d.readUint(1 << (code - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		if n > math.MaxInt64 {
+			return n, nil
+		}
+		return int(n), nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (code - 0xd0)
+		n, err := d.readUint(size)
+		if err != nil {
+			return nil, err
+		}
+		shift := uint(64 - size*8)
+		return int(int64(n<<shift) >> shift), nil
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.decodeExt(1 << (code - 0xd4))
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.readLength(code - 0xd9)
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeString(n)
+	case 0xdc, 0xdd:
+		n, err := d.readLength(code - 0xdc + 1)
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeArray(n, depth)
+	case 0xde, 0xdf:
+		n, err := d.readLength(code - 0xde + 1)
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeMap(n, depth)
+	}
+	return nil, fmt.Errorf("msgpack: invalid code 0x%x", code)
+}
+
+func (d *msgpackDecoder) decodeMap(n int, depth int) (interface{}, error) {
+	result := make(RequestValue, minInt(n, 64))
+	for i := 0; i < n; i++ {
+		key, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		value, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		result[mapKeyString(key)] = value
+	}
+	return result, nil
+}
+
+func (d *msgpackDecoder) decodeArray(n int, depth int) (interface{}, error) {
+	result := make([]interface{}, 0, minInt(n, 64))
+	for i := 0; i < n; i++ {
+		value, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, value)
+	}
+	return result, nil
+}
+
+func (d *msgpackDecoder) decodeString(n int) (interface{}, error) {
+	data, err := d.readBytes(n)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// decodeExt reads an extension value. Only the timestamp extension (type -1) is understood.
+func (d *msgpackDecoder) decodeExt(n int) (interface{}, error) {
+	extType, err := d.reader.ReadByte()
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	data, err := d.readBytes(n)
+	if err != nil {
+		return nil, err
+	}
+	if int8(extType) != -1 {
+		return nil, fmt.Errorf("msgpack: unsupported extension type %d", int8(extType))
+	}
+	switch n {
+	case 4:
+		return time.Unix(int64(binary.BigEndian.Uint32(data)), 0).UTC(), nil
+	case 8:
+		value := binary.BigEndian.Uint64(data)
+		return time.Unix(int64(value&0x3ffffffff), int64(value>>34)).UTC(), nil
+	case 12:
+		nsec := binary.BigEndian.Uint32(data[:4])
+		sec := int64(binary.BigEndian.Uint64(data[4:]))
+		return time.Unix(sec, int64(nsec)).UTC(), nil
+	}
+	return nil, fmt.Errorf("msgpack: invalid timestamp length %d", n)
+}
+
+// readLength reads a big-endian length of 1, 2 or 4 bytes selected by sizeCode 0, 1 or 2.
+func (d *msgpackDecoder) readLength(sizeCode byte) (int, error) {
+	n, err := d.readUint(1 << sizeCode)
+	return int(n), err
+}
+
+func (d *msgpackDecoder) readUint(size int) (uint64, error) {
+	return readUint(d.reader, size)
+}
+
+func (d *msgpackDecoder) readBytes(n int) ([]byte, error) {
+	return readBytes(d.reader, int64(n))
+}
+
+// readUint reads a big-endian unsigned integer of size bytes.
+func readUint(reader io.Reader, size int) (uint64, error) {
+	var buf [8]byte
+	if _, err := io.ReadFull(reader, buf[:size]); err != nil {
+		return 0, unexpectedEOF(err)
+	}
+	var n uint64
+	for _, b := range buf[:size] {
+		n = n<<8 | uint64(b)
+	}
+	return n, nil
+}
+
+// readBytes reads n bytes without trusting n for the allocation size,
+// so a forged length cannot allocate more than the body actually holds.
+func readBytes(reader io.Reader, n int64) ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, reader, n); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return buf.Bytes(), nil
+}
+
+func mapKeyString(key interface{}) string {
+	if value, ok := key.(string); ok {
+		return value
+	}
+	if value, ok := scalarString(key); ok {
+		return value
+	}
+	return fmt.Sprint(key)
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
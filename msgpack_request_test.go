@@ -0,0 +1,94 @@
+package inrequest
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMsgpack(t *testing.T) {
+	// {"name":"John","age":31,"score":-5,"ratio":0.5,"active":true,"nick":nil,"tags":["a","b"],"big":70000,"at":timestamp32(1700000000)}
+	body := []byte{
+		0x89,
+		0xa4, 'n', 'a', 'm', 'e', 0xa4, 'J', 'o', 'h', 'n',
+		0xa3, 'a', 'g', 'e', 0x1f,
+		0xa5, 's', 'c', 'o', 'r', 'e', 0xfb,
+		0xa5, 'r', 'a', 't', 'i', 'o', 0xcb, 0x3f, 0xe0, 0, 0, 0, 0, 0, 0,
+		0xa6, 'a', 'c', 't', 'i', 'v', 'e', 0xc3,
+		0xa4, 'n', 'i', 'c', 'k', 0xc0,
+		0xa4, 't', 'a', 'g', 's', 0x92, 0xa1, 'a', 0xa1, 'b',
+		0xa3, 'b', 'i', 'g', 0xce, 0x00, 0x01, 0x11, 0x70,
+		0xa2, 'a', 't', 0xd6, 0xff, 0x65, 0x53, 0xf1, 0x00,
+	}
+
+	t.Run("should decode msgpack into map", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/msgpack")
+		result, err := Msgpack(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		target := RequestValue{
+			"name":   "John",
+			"age":    31,
+			"score":  -5,
+			"ratio":  0.5,
+			"active": true,
+			"nick":   nil,
+			"tags":   []interface{}{"a", "b"},
+			"big":    70000,
+			"at":     time.Unix(1700000000, 0).UTC(),
+		}
+		if !reflect.DeepEqual(result.ToMap(), target) {
+			t.Fatalf("Failed decoding msgpack, expected %v, got %v", target, result.ToMap())
+		}
+	})
+	t.Run("should be detected by Parse", func(t *testing.T) {
+		type User struct {
+			Name string   `json:"name"`
+			Age  int      `json:"age"`
+			Tags []string `json:"tags"`
+		}
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-msgpack")
+		result, err := Parse(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		user := User{}
+		if err = result.ToBind(&user); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(user, User{Name: "John", Age: 31, Tags: []string{"a", "b"}}) {
+			t.Fatalf("unexpected user %+v", user)
+		}
+	})
+	t.Run("should bind a timestamp into a time field", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/msgpack")
+		result, err := Msgpack(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		event := struct {
+			At time.Time `json:"at"`
+		}{}
+		if err = result.ToBind(&event); err != nil {
+			t.Fatal(err)
+		}
+		if !event.At.Equal(time.Unix(1700000000, 0)) {
+			t.Fatalf("expected at %v, got %v", time.Unix(1700000000, 0), event.At)
+		}
+	})
+	t.Run("should reject truncated and forged lengths", func(t *testing.T) {
+		for _, payload := range [][]byte{body[:20], {0x81, 0xa1, 'a', 0xdb, 0xff, 0xff, 0xff, 0xff}, {0x92, 0x01}} {
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(payload))
+			if _, err := Msgpack(req); err == nil {
+				t.Fatalf("expected error for % x", payload)
+			}
+		}
+	})
+}
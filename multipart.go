@@ -0,0 +1,534 @@
+package inrequest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"unicode"
+)
+
+// defaultMaxMemory is the memory cap of WithInMemoryUploads when no MaxMemory is set, as for http.Request.FormFile.
+const defaultMaxMemory = 32 << 20
+
+// maxValueBytes is the memory allowed on top of MaxMemory for the values of a form, like the 10MB allowance of multipart.Reader.ReadForm.
+const maxValueBytes = 10 << 20
+
+// maxParts, partOverhead and fileHeaderSize bound the parts of a form like multipart.Reader.ReadForm does.
+const (
+	maxParts       = 1000
+	partOverhead   = 200
+	fileHeaderSize = 100
+)
+
+// sniffLen is the number of bytes http.DetectContentType considers.
+const sniffLen = 512
+
+// formParts keeps what the values of a multipart form lose, see WithPartHeaders and WithJsonParts.
+type formParts struct {
+	// headers holds the MIME headers of every part by form name, in the order the parts arrived.
+	headers map[string][]textproto.MIMEHeader
+	// json holds the decoded values of the parts declaring a json Content-Type by form name.
+	json map[string][]interface{}
+}
+
+// newFormParts returns the formParts options record, nil when they record none.
+func newFormParts(options *Options) *formParts {
+	if !options.PartHeaders && !options.JsonParts {
+		return nil
+	}
+	return &formParts{headers: make(map[string][]textproto.MIMEHeader), json: make(map[string][]interface{})}
+}
+
+// headersOf returns the headers recorded for field, parts may be nil.
+func (p *formParts) headersOf(field string) []textproto.MIMEHeader {
+	if p == nil {
+		return nil
+	}
+	return p.headers[field]
+}
+
+// properties lists the decoded json parts like valuesProperties lists values.
+func (p *formParts) properties() []GroupRequestProperty {
+	if p == nil {
+		return nil
+	}
+	var forms []GroupRequestProperty
+	for name, values := range p.json {
+		if !strings.Contains(name, "[]") && (strings.Contains(name, "[") || len(values) == 1) {
+			forms = append(forms, GroupRequestProperty{Path: name, Value: values[0]})
+			continue
+		}
+		for i, value := range values {
+			forms = append(forms, GroupRequestProperty{Path: indexedKey(name, i), Value: value})
+		}
+	}
+	return forms
+}
+
+// isJsonPart reports whether a part declares application/json or a +json media type.
+func isJsonPart(header textproto.MIMEHeader) bool {
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	return err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"))
+}
+
+/*
+Reading a multipart body part by part under the upload limits
+e.g. with WithMaxFileSize("avatar", 1<<20) a 5MB avatar stops the parse after 1MB is read,
+instead of buffering the whole file before its size is known
+*/
+func readMultipartLimited(r *http.Request, options *Options, parts *formParts) (*multipart.Form, error) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	form := &multipart.Form{Value: make(map[string][]string), File: make(map[string][]*multipart.FileHeader)}
+	limits := newUploadLimits(options)
+	budget := newFormBudget(options)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			if options.TempDir != "" {
+				// The server only removes the files ReadForm stored once the request is done.
+				context.AfterFunc(r.Context(), func() { removeForm(form) })
+			}
+			return form, nil
+		}
+		if err == nil {
+			err = budget.part(part)
+		}
+		if err != nil {
+			removeForm(form)
+			return nil, err
+		}
+		name := part.FormName()
+		if name == "" {
+			continue
+		}
+		if parts != nil && options.PartHeaders {
+			parts.headers[name] = append(parts.headers[name], part.Header)
+		}
+		if part.FileName() == "" {
+			value, err := budget.readValue(part)
+			if err != nil {
+				removeForm(form)
+				return nil, err
+			}
+			if parts != nil && options.JsonParts && isJsonPart(part.Header) {
+				var decoded interface{}
+				if err = json.Unmarshal([]byte(value), &decoded); err != nil {
+					removeForm(form)
+					return nil, fmt.Errorf("json part %q: %w", name, err)
+				}
+				parts.json[name] = append(parts.json[name], decoded)
+				continue
+			}
+			form.Value[name] = append(form.Value[name], value)
+			continue
+		}
+
+		body, limit, err := limits.open(name, part)
+		if err != nil {
+			removeForm(form)
+			return nil, err
+		}
+		header, size, err := readFilePart(part.Header, body, limit, budget.fileMemory, options.TempDir)
+		if err == errFileTooLarge {
+			err = limits.done(name, size, limit)
+		}
+		if err == nil {
+			err = budget.file(part, size)
+		}
+		if err != nil {
+			if header != nil {
+				removeFile(header)
+			}
+			removeForm(form)
+			return nil, err
+		}
+		limits.done(name, size, limit)
+		header.Filename = options.filename(part)
+		form.File[name] = append(form.File[name], header)
+		if err = options.scanFile(r.Context(), name, header); err != nil {
+			removeForm(form)
+			return nil, err
+		}
+	}
+}
+
+// scanFile runs the scanner set with WithFileScanner over a stored upload.
+func (o *Options) scanFile(ctx context.Context, name string, header *multipart.FileHeader) error {
+	if o.FileScanner == nil {
+		return nil
+	}
+	file, err := openFile(header)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err = o.FileScanner(ctx, header, file); err != nil {
+		return &FileRejectedError{Field: name, Filename: header.Filename, Err: err}
+	}
+	return nil
+}
+
+// memoryCap is the number of bytes of files kept in memory under WithInMemoryUploads, zero otherwise.
+func (o *Options) memoryCap() int64 {
+	switch {
+	case !o.InMemoryUploads:
+		return 0
+	case o.MaxMemory > 0:
+		return o.MaxMemory
+	}
+	return defaultMaxMemory
+}
+
+// fileMemory is the maxMemory files are stored with, spilling into temporary files only when allowed.
+func (o *Options) fileMemory() int64 {
+	if o.InMemoryUploads {
+		return math.MaxInt64 >> 1
+	}
+	return o.MaxMemory
+}
+
+/*
+Accounting for the memory and parts of one multipart body the way multipart.Reader.ReadForm does
+e.g. with MaxMemory 0 three 5MB values go past the 10MB the values of a form may hold together,
+and files share MaxMemory instead of each being allowed that much
+*/
+type formBudget struct {
+	// parts is the number of parts left.
+	parts int
+	// memory is the number of bytes values, in-memory files and part overhead may still take.
+	memory int64
+	// fileMemory is the number of bytes of file content that may still be kept in memory.
+	fileMemory int64
+}
+
+func newFormBudget(options *Options) *formBudget {
+	fileMemory := options.fileMemory()
+	memory := fileMemory + maxValueBytes
+	if memory < 0 {
+		memory = math.MaxInt64 - 1
+	}
+	return &formBudget{parts: maxParts, memory: memory, fileMemory: fileMemory}
+}
+
+// part accounts for the name and map entry of a part, failing past the part count.
+func (b *formBudget) part(part *multipart.Part) error {
+	if b.parts <= 0 {
+		return multipart.ErrMessageTooLarge
+	}
+	b.parts--
+	b.memory -= int64(len(part.FormName())) + partOverhead
+	if b.memory < 0 {
+		return multipart.ErrMessageTooLarge
+	}
+	return nil
+}
+
+// readValue reads a non-file part within the memory left.
+func (b *formBudget) readValue(part *multipart.Part) (string, error) {
+	value, err := io.ReadAll(io.LimitReader(part, b.memory+1))
+	b.memory -= int64(len(value))
+	if err == nil && b.memory < 0 {
+		err = multipart.ErrMessageTooLarge
+	}
+	return string(value), err
+}
+
+// file accounts for a stored file of size bytes, which readFilePart kept in memory when it fit in fileMemory.
+func (b *formBudget) file(part *multipart.Part, size int64) error {
+	b.memory -= fileHeaderSize + partOverhead
+	for key, values := range part.Header {
+		b.memory -= int64(len(key))
+		for _, value := range values {
+			b.memory -= int64(len(value))
+		}
+	}
+	if size <= b.fileMemory {
+		b.fileMemory -= size
+		b.memory -= size
+	}
+	if b.memory < 0 {
+		return multipart.ErrMessageTooLarge
+	}
+	return nil
+}
+
+// removeFile deletes the temporary file of an upload no form holds yet.
+func removeFile(header *multipart.FileHeader) {
+	removeForm(&multipart.Form{File: map[string][]*multipart.FileHeader{"": {header}}})
+}
+
+// uploadLimits applies the upload policy of options to the file parts of one body.
+type uploadLimits struct {
+	options *Options
+	total   int64
+	files   map[string]int
+}
+
+func newUploadLimits(options *Options) *uploadLimits {
+	return &uploadLimits{options: options, files: make(map[string]int)}
+}
+
+// open checks a file part against the count and type policy, returning its content
+// and the number of bytes it may hold, negative without limit.
+func (u *uploadLimits) open(name string, part *multipart.Part) (io.Reader, int64, error) {
+	path := fieldPath(name)
+	u.files[path]++
+	if max, ok := u.options.MaxFiles[path]; ok && u.files[path] > max {
+		return nil, 0, fmt.Errorf("%w: %s holds more than %d", ErrTooManyFiles, name, max)
+	}
+	body, err := u.options.checkFilePart(name, part)
+	if err != nil {
+		return nil, 0, err
+	}
+	limit := u.options.maxFileSize(name)
+	if max := u.options.MaxTotalUploadSize; max > 0 && (limit < 0 || max-u.total < limit) {
+		limit = max - u.total
+	}
+	if max := u.options.memoryCap(); max > 0 && (limit < 0 || max-u.total < limit) {
+		limit = max - u.total
+	}
+	return body, limit, nil
+}
+
+// done accounts for a file of size bytes read under limit, reporting a file past the limit.
+func (u *uploadLimits) done(name string, size int64, limit int64) error {
+	if limit >= 0 && size > limit {
+		if max := u.options.maxFileSize(name); max >= 0 && size > max {
+			return &FileTooLargeError{Field: name, Size: size, Max: max}
+		}
+		if max := u.options.MaxTotalUploadSize; max > 0 && u.total+size > max {
+			return &FileTooLargeError{Field: name, Size: u.total + size, Max: max, Total: true}
+		}
+		return &RequestTooLargeError{Limit: "memory", Max: u.options.memoryCap()}
+	}
+	u.total += size
+	return nil
+}
+
+var errFileTooLarge = errors.New("file too large")
+
+/*
+Storing a single file part the way multipart.Reader.ReadForm does, in memory or in a temporary file of dir,
+by streaming it through a one part multipart body that stops once more than limit bytes are read.
+A negative limit means no limit, an empty dir means os.TempDir
+*/
+func readFilePart(header textproto.MIMEHeader, body io.Reader, limit int64, maxMemory int64, dir string) (*multipart.FileHeader, int64, error) {
+	if dir != "" {
+		return spillFilePart(header, body, limit, maxMemory, dir)
+	}
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+	counter := &countingReader{Reader: body}
+	done := make(chan error, 1)
+	go func() {
+		var src io.Reader = counter
+		if limit >= 0 {
+			src = io.LimitReader(counter, limit+1)
+		}
+		w, err := writer.CreatePart(header)
+		if err == nil {
+			_, err = io.Copy(w, src)
+		}
+		if err == nil && limit >= 0 && counter.n > limit {
+			err = errFileTooLarge
+		}
+		if err == nil {
+			err = writer.Close()
+		}
+		pw.CloseWithError(err)
+		done <- err
+	}()
+
+	form, err := multipart.NewReader(pr, writer.Boundary()).ReadForm(maxMemory)
+	pr.Close()
+	if copyErr := <-done; copyErr == errFileTooLarge {
+		if form != nil {
+			removeForm(form)
+		}
+		return nil, counter.n, errFileTooLarge
+	}
+	if err != nil {
+		return nil, counter.n, err
+	}
+	for _, headers := range form.File {
+		return headers[0], counter.n, nil
+	}
+	return nil, counter.n, io.ErrUnexpectedEOF
+}
+
+/*
+Storing a file part over maxMemory in a temporary file of dir, as ReadForm only writes to os.TempDir
+e.g. with WithTempDir("/mnt/uploads") a 50MB video lands in /mnt/uploads/multipart-123456.
+The file is recorded in storedFiles, smaller parts are kept in memory like ReadForm does
+*/
+func spillFilePart(header textproto.MIMEHeader, body io.Reader, limit int64, maxMemory int64, dir string) (*multipart.FileHeader, int64, error) {
+	counter := &countingReader{Reader: body}
+	var src io.Reader = counter
+	if limit >= 0 {
+		src = io.LimitReader(counter, limit+1)
+	}
+	if maxMemory == math.MaxInt64 {
+		maxMemory--
+	}
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, src, maxMemory+1); err != nil && err != io.EOF {
+		return nil, counter.n, err
+	}
+	if limit >= 0 && counter.n > limit {
+		return nil, counter.n, errFileTooLarge
+	}
+	if int64(buf.Len()) <= maxMemory {
+		fileHeader, err := memoryFilePart(header, buf.Bytes())
+		return fileHeader, counter.n, err
+	}
+
+	file, err := os.CreateTemp(dir, "multipart-")
+	if err != nil {
+		return nil, counter.n, err
+	}
+	_, err = io.Copy(file, io.MultiReader(&buf, src))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && limit >= 0 && counter.n > limit {
+		err = errFileTooLarge
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return nil, counter.n, err
+	}
+	fileHeader := &multipart.FileHeader{Header: header, Size: counter.n}
+	storedFiles.Store(fileHeader, file.Name())
+	return fileHeader, counter.n, nil
+}
+
+// memoryFilePart gives content a header opening it from memory, built by ReadForm from a one part body.
+func memoryFilePart(header textproto.MIMEHeader, content []byte) (*multipart.FileHeader, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	w, err := writer.CreatePart(header)
+	if err == nil {
+		_, err = w.Write(content)
+	}
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+	form, err := multipart.NewReader(&body, writer.Boundary()).ReadForm(int64(len(content)))
+	if err != nil {
+		return nil, err
+	}
+	for _, headers := range form.File {
+		return headers[0], nil
+	}
+	return nil, io.ErrUnexpectedEOF
+}
+
+/*
+Applying the upload policy of field to a file part, returning the reader of the whole part
+e.g. with WithAllowedMimeTypes("photos", "image/png") a photo whose first 512 bytes sniff as
+"text/html" fails with a *FileTypeError, whatever Content-Type the part declares
+*/
+func (o *Options) checkFilePart(field string, part *multipart.Part) (io.Reader, error) {
+	filename := o.filename(part)
+	if allowed, ok := o.AllowedExtensions[fieldPath(field)]; ok {
+		if ext := strings.ToLower(path.Ext(filename)); !containsString(allowed, ext) {
+			return nil, &FileTypeError{Field: field, Filename: filename, Type: ext}
+		}
+	}
+	allowed, ok := o.AllowedMimeTypes[fieldPath(field)]
+	if !ok {
+		return part, nil
+	}
+	buffered := bufio.NewReaderSize(part, sniffLen)
+	head, err := buffered.Peek(sniffLen)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	mediaType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	if !mimeTypeAllowed(mediaType, allowed) {
+		return nil, &FileTypeError{Field: field, Filename: filename, Type: mediaType}
+	}
+	return buffered, nil
+}
+
+// mimeTypeAllowed matches a media type against allowed types, "image/*" accepts every image.
+func mimeTypeAllowed(mediaType string, allowed []string) bool {
+	for _, allow := range allowed {
+		if allow == mediaType || strings.HasSuffix(allow, "/*") && strings.HasPrefix(mediaType, allow[:len(allow)-1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// filename is the client filename of a file part, sanitized under WithSanitizedFilenames.
+func (o *Options) filename(part *multipart.Part) string {
+	if o.SanitizeFilenames {
+		return sanitizeFilename(part.FileName())
+	}
+	return part.FileName()
+}
+
+/*
+Making a client supplied filename safe to use as a file name
+e.g. "..\\..\\etc/passwd" becomes "passwd", "UTF-8”na%C3%AFve.txt" becomes "naïve.txt"
+and control characters are dropped
+*/
+func sanitizeFilename(name string) string {
+	if i := strings.Index(name, "''"); i > 0 && (strings.EqualFold(name[:i], "utf-8") || strings.EqualFold(name[:i], "us-ascii")) {
+		if decoded, err := url.PathUnescape(name[i+2:]); err == nil {
+			name = decoded
+		}
+	}
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name)
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.TrimSpace(strings.ReplaceAll(name, "..", ""))
+	if name == "/" || name == "." {
+		return ""
+	}
+	return name
+}
+
+// sanitizeFilenames sanitizes the filename of every uploaded file of form.
+func sanitizeFilenames(form *multipart.Form) {
+	for _, headers := range form.File {
+		for _, header := range headers {
+			header.Filename = sanitizeFilename(header.Filename)
+		}
+	}
+}
+
+type countingReader struct {
+	io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.Reader.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,871 @@
+package inrequest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The array index and depth limits of node-qs, applied by WithQsCompat unless set otherwise.
+const (
+	qsArrayLimit = 20
+	qsDepth      = 5
+)
+
+// DefaultMaxArrayIndex is the largest index accepted in keys such as items[42] unless WithMaxArrayIndex says otherwise.
+const DefaultMaxArrayIndex = 10000
+
+// Options holds the settings used while parsing a request and rendering its result.
+type Options struct {
+	// OmitFiles excludes uploaded files from ToMap, ToJsonByte and ToJsonString.
+	// ToBind still receives them.
+	OmitFiles bool
+
+	// RedactedKeys lists the field paths whose values ToJsonByte, ToJsonString and Dump replace with "***",
+	// slice indexes are left out of the path. ToMap and ToBind still receive the real values.
+	RedactedKeys []string
+
+	// ParseTimeout bounds how long reading and decoding the body may take.
+	// Zero means no limit besides the request context.
+	ParseTimeout time.Duration
+
+	// MaxContentLength rejects requests declaring a larger Content-Length
+	// before any body bytes are read. Zero means no limit.
+	MaxContentLength int64
+
+	// MaxBodySize fails parsing once more than this many body bytes are read,
+	// whatever the declared Content-Length. Zero means no limit.
+	MaxBodySize int64
+
+	// Logger receives the conditions parsing and binding otherwise swallow, e.g. the error behind
+	// the empty result of FormData or a request key matching no field. Nil logs nothing.
+	Logger *slog.Logger
+
+	// Metrics observes the duration, size and uploaded files of every body parse.
+	Metrics Metrics
+
+	// MaxKeys fails parsing when the request holds more values, counting every
+	// leaf of the parsed map, e.g. "a=1&b[]=2&b[]=3" holds 3. Zero means no limit.
+	MaxKeys int
+
+	// StrictContentType rejects requests whose Content-Type does not match
+	// the entry point, e.g. a multipart body sent to Json.
+	StrictContentType bool
+
+	// Preflight runs before the body is read with the detected media type.
+	// Returning an error aborts parsing.
+	Preflight func(r *http.Request, mediaType string) error
+
+	// MaxDecompressedSize caps the size of a body after undoing its Content-Encoding.
+	// Zero means DefaultMaxDecompressedSize, a negative value means no limit.
+	MaxDecompressedSize int64
+
+	// PathParams reads a path parameter by name for BindRequest.
+	// Nil means Request.PathValue as filled by the Go 1.22 http.ServeMux.
+	PathParams func(r *http.Request, name string) string
+
+	// DisallowUnknownFields makes ToBind report request keys matching no field of the model.
+	DisallowUnknownFields bool
+
+	// Location interprets the dates and times without zone ToBind parses, UTC when nil, see WithLocation.
+	Location *time.Location
+
+	// SnakeCaseFields makes ToBind match untagged fields to snake_case keys, e.g. first_name to FirstName.
+	SnakeCaseFields bool
+
+	// UseNumber makes JsonBind decode the numbers held by interface{} fields as json.Number instead of float64.
+	UseNumber bool
+
+	// TagName is the struct tag binders read in place of `json`.
+	// Empty means the name set with SetTagName, `json` by default.
+	TagName string
+
+	// MaxMemory is the number of bytes of multipart file parts kept in memory,
+	// larger parts are stored in temporary files. Zero stores every file part on disk.
+	MaxMemory int64
+
+	// TempDir is the directory of the temporary files of uploads over MaxMemory. Empty means os.TempDir.
+	// Files stored there are opened through UploadedFile, a multipart.FileHeader cannot open them.
+	TempDir string
+
+	// FileScanner inspects every uploaded file once it is stored, before the parse returns.
+	FileScanner FileScanner
+
+	// MaxBindFileSize caps the uploads ToBind reads into []byte fields. Zero means no cap.
+	MaxBindFileSize int64
+
+	// InMemoryUploads keeps every uploaded file in memory, never in a temporary file.
+	// Uploads over MaxMemory together, 32MB when unset, fail the parse.
+	InMemoryUploads bool
+
+	// MaxFileSizes limits the size of the files uploaded under the given form field paths,
+	// slice indexes are left out of the path. Exceeding files stop the parse as soon as
+	// the limit is read.
+	MaxFileSizes map[string]int64
+
+	// MaxFiles limits the number of files uploaded under the given form field paths,
+	// e.g. "photos" counts photos[0], photos[1] and repeated photos parts together.
+	MaxFiles map[string]int
+
+	// AllowedMimeTypes lists the content types accepted for the files uploaded under
+	// the given form field paths, sniffed from the file content with http.DetectContentType.
+	AllowedMimeTypes map[string][]string
+
+	// AllowedExtensions lists the lower case extensions, with their dot, accepted
+	// for the files uploaded under the given form field paths.
+	AllowedExtensions map[string][]string
+
+	// SanitizeFilenames strips directories, ".." and control characters from the
+	// names of uploaded files, and decodes RFC 5987 encoded names.
+	SanitizeFilenames bool
+
+	// MaxTotalUploadSize limits the combined size of every uploaded file. Zero means no limit.
+	MaxTotalUploadSize int64
+
+	// NoTypeConversion keeps form and query values as strings instead of
+	// converting numbers, e.g. "12" stays "12".
+	NoTypeConversion bool
+
+	// CommaSeparatedArrays makes ToBind split a single form or query value bound into a slice field
+	// on commas, e.g. "ids=1,2,3" fills []int{1, 2, 3}.
+	CommaSeparatedArrays bool
+
+	// Transformers rewrite the parsed values at the given dot paths in order, see WithTransformer.
+	// Slice indexes are left out of the path.
+	Transformers map[string][]ValueTransformer
+
+	// FieldTypes forces the type of the values at the given dot paths, overriding
+	// the conversion of every other value. Slice indexes are left out of the path.
+	FieldTypes map[string]FieldType
+
+	// TrueTokens and FalseTokens list the form and query values converted into booleans,
+	// e.g. "on" sent by checkboxes. Without them booleans stay strings.
+	TrueTokens  []string
+	FalseTokens []string
+
+	// NullTokens lists the literal values standing for null, e.g. "null", "nil" or "".
+	// Form and query values equal to one become nil, and ToBind leaves pointer and
+	// sql.Null* fields nil or invalid for them. Nil means "null" for ToBind only,
+	// an empty slice disables null handling.
+	NullTokens []string
+
+	// MaxArrayIndex is the largest index accepted in keys such as items[42].
+	// Zero means DefaultMaxArrayIndex, a negative value means no limit.
+	MaxArrayIndex int
+
+	// QsCompat expands form and query keys the way node-qs and Rails do: dots are part of the key,
+	// values stay strings, an index past MaxArrayIndex, 20 unless set, or a key mixing indexes
+	// and names keeps a map instead of failing, and keys nest MaxDepth levels, 5 unless set.
+	QsCompat bool
+
+	// LiteralDotKeys keeps form and query keys such as "user.name" as they are instead of expanding them like "user[name]".
+	LiteralDotKeys bool
+
+	// DuplicateKeys decides which values of a plain key sent several times are kept,
+	// Combine, the default, keeps them all. Bracket keys such as "tags[]" are not affected.
+	DuplicateKeys DuplicatePolicy
+
+	// Numbers decides what integers past the int64 range and exponents without fraction give,
+	// NumberAsString by default.
+	Numbers NumberPolicy
+
+	// PartHeaders records the MIME headers of every multipart part, read with FormRequest.PartHeaders.
+	PartHeaders bool
+
+	// JsonParts decodes multipart value parts declaring a json Content-Type into nested values.
+	JsonParts bool
+
+	// JsonValues decodes form and query values holding a json object or array into nested values.
+	JsonValues bool
+
+	// BeforeBind hooks change a copy of the values before ToBind binds them, in order.
+	BeforeBind []BeforeBindHook
+
+	// AfterBind hooks post-process the model once ToBind bound it, in order.
+	AfterBind []AfterBindHook
+
+	// ctx is the context handed to converters, see RegisterConverterCtx and WithContext.
+	ctx context.Context
+
+	// MergeQuery folds the query string into FormData results, body values win over query values of the same key.
+	MergeQuery bool
+
+	// PreserveOrder records the order the keys of json bodies and query strings arrived in,
+	// kept by ToOrderedMap and the JSON methods.
+	PreserveOrder bool
+
+	// MixedKeys decides what a key mixing indexes and names gives, DropNamedKeys by default,
+	// KeepMixedMap under QsCompat and RejectMixedKeys under StrictKeys unless set otherwise.
+	MixedKeys MixedKeyPolicy
+
+	// StrictKeys fails form and query parsing with ErrMalformedKey for keys with unbalanced brackets,
+	// empty segments other than a pushed "tags[]", a plain key conflicting with nested keys,
+	// or indexes and names mixed under one key, instead of guessing a shape.
+	StrictKeys bool
+
+	// SparseArrays keeps items at their index, filling gaps with nil,
+	// instead of compacting items[0] and items[5] into two items.
+	SparseArrays bool
+
+	// MaxDepth limits how deep bracket keys of forms and query strings nest.
+	// Segments past the limit stay together as one literal key. Zero means no limit.
+	MaxDepth int
+
+	// CsvDelimiter separates csv columns. Zero means a comma.
+	CsvDelimiter rune
+
+	// CsvNoHeader reads the first csv record as data instead of column names.
+	CsvNoHeader bool
+}
+
+type Option func(*Options)
+
+// WithOmitFiles drops file entries from the map and JSON output.
+func WithOmitFiles() Option {
+	return func(o *Options) {
+		o.OmitFiles = true
+	}
+}
+
+// WithRedactedKeys hides the values at the given paths from the JSON output and Dump, e.g. to log payloads safely
+// with WithRedactedKeys("password", "card.number", "items[][token]"). ToBind still receives the real values.
+func WithRedactedKeys(keys ...string) Option {
+	return func(o *Options) {
+		o.RedactedKeys = append(o.RedactedKeys, keys...)
+	}
+}
+
+// WithParseTimeout limits the time spent reading and decoding the request body.
+// A parse that takes longer fails with a *ParseError wrapping ErrParseTimeout.
+func WithParseTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.ParseTimeout = d
+	}
+}
+
+// WithMaxContentLength rejects requests whose declared Content-Length exceeds n bytes.
+func WithMaxContentLength(n int64) Option {
+	return func(o *Options) {
+		o.MaxContentLength = n
+	}
+}
+
+// WithMaxBodySize fails parsing with a *RequestTooLargeError once the body exceeds n bytes.
+func WithMaxBodySize(n int64) Option {
+	return func(o *Options) {
+		o.MaxBodySize = n
+	}
+}
+
+// WithMaxKeys fails parsing with a *RequestTooLargeError when the request holds more than n values.
+func WithMaxKeys(n int) Option {
+	return func(o *Options) {
+		o.MaxKeys = n
+	}
+}
+
+// WithLogger logs swallowed conditions to logger, failures hidden by FormData and Query at warn level,
+// dropped values and unknown fields at debug level.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *Options) {
+		o.Logger = logger
+	}
+}
+
+// WithMetrics reports every body parse to m, e.g. a Prometheus histogram per endpoint.
+func WithMetrics(m Metrics) Option {
+	return func(o *Options) {
+		o.Metrics = m
+	}
+}
+
+// WithStrictContentType rejects requests whose Content-Type does not match the entry point.
+func WithStrictContentType() Option {
+	return func(o *Options) {
+		o.StrictContentType = true
+	}
+}
+
+// WithPreflight registers a check that runs before any body bytes are read.
+func WithPreflight(fn func(r *http.Request, mediaType string) error) Option {
+	return func(o *Options) {
+		o.Preflight = fn
+	}
+}
+
+// WithMaxDecompressedSize limits how large a compressed body may expand, protecting against zip bombs.
+func WithMaxDecompressedSize(n int64) Option {
+	return func(o *Options) {
+		o.MaxDecompressedSize = n
+	}
+}
+
+// WithPathParams makes BindRequest read path parameters through fn, e.g. chi.URLParam.
+func WithPathParams(fn func(r *http.Request, name string) string) Option {
+	return func(o *Options) {
+		o.PathParams = fn
+	}
+}
+
+// WithLocation makes ToBind read dates and times without zone, e.g. "2024-03-01" or "2024-03-01 09:30:00",
+// in loc instead of UTC. A `tz:"Europe/Paris"` tag overrides it for a field and the fields nested in it.
+func WithLocation(loc *time.Location) Option {
+	return func(o *Options) {
+		o.Location = loc
+	}
+}
+
+// WithSnakeCaseFields makes ToBind fill untagged fields from snake_case keys, e.g. first_name into FirstName
+// and user_id into UserID, for legacy forms naming fields differently than the Go structs. Tagged fields keep their name.
+func WithSnakeCaseFields() Option {
+	return func(o *Options) {
+		o.SnakeCaseFields = true
+	}
+}
+
+// WithDisallowUnknownFields makes ToBind fail on keys the model has no field for, e.g. a mistyped "emial".
+func WithDisallowUnknownFields() Option {
+	return func(o *Options) {
+		o.DisallowUnknownFields = true
+	}
+}
+
+// WithUseNumber makes JsonBind keep numbers bound into interface{} fields as json.Number, e.g. for exact ids.
+func WithUseNumber() Option {
+	return func(o *Options) {
+		o.UseNumber = true
+	}
+}
+
+// WithTagName makes ToBind read the given struct tag in place of `json`, e.g. WithTagName("api").
+func WithTagName(name string) Option {
+	return func(o *Options) {
+		o.TagName = name
+	}
+}
+
+// WithMaxMemory keeps multipart file parts up to n bytes in memory instead of temporary files.
+func WithMaxMemory(n int64) Option {
+	return func(o *Options) {
+		o.MaxMemory = n
+	}
+}
+
+// WithTempDir stores the uploads over MaxMemory of this call in dir instead of the directory of SetTempDir or os.TempDir,
+// e.g. WithTempDir("/mnt/videos") for a route receiving large videos.
+func WithTempDir(dir string) Option {
+	return func(o *Options) {
+		o.TempDir = dir
+	}
+}
+
+// WithMaxFileSize fails parsing with a *FileTooLargeError when a file uploaded under field exceeds n bytes,
+// e.g. WithMaxFileSize("attachments[][file]", 5<<20) limits every attachment.
+func WithMaxFileSize(field string, n int64) Option {
+	return func(o *Options) {
+		if o.MaxFileSizes == nil {
+			o.MaxFileSizes = make(map[string]int64)
+		}
+		o.MaxFileSizes[fieldPath(field)] = n
+	}
+}
+
+// WithMaxFiles fails parsing with ErrTooManyFiles as soon as more than n files are uploaded under field.
+func WithMaxFiles(field string, n int) Option {
+	return func(o *Options) {
+		if o.MaxFiles == nil {
+			o.MaxFiles = make(map[string]int)
+		}
+		o.MaxFiles[fieldPath(field)] = n
+	}
+}
+
+// WithAllowedMimeTypes fails parsing with a *FileTypeError when a file uploaded under field is of another type,
+// e.g. WithAllowedMimeTypes("photos", "image/png", "image/jpeg"). The type is sniffed from the file content,
+// the Content-Type declared by the client is ignored. "image/*" accepts every image type.
+func WithAllowedMimeTypes(field string, types ...string) Option {
+	return func(o *Options) {
+		if o.AllowedMimeTypes == nil {
+			o.AllowedMimeTypes = make(map[string][]string)
+		}
+		for _, t := range types {
+			o.AllowedMimeTypes[fieldPath(field)] = append(o.AllowedMimeTypes[fieldPath(field)], strings.ToLower(t))
+		}
+	}
+}
+
+// WithAllowedExtensions fails parsing with a *FileTypeError when a file uploaded under field has another extension,
+// e.g. WithAllowedExtensions("resume", ".pdf", "docx"). Extensions match case-insensitively.
+func WithAllowedExtensions(field string, extensions ...string) Option {
+	return func(o *Options) {
+		if o.AllowedExtensions == nil {
+			o.AllowedExtensions = make(map[string][]string)
+		}
+		for _, ext := range extensions {
+			ext = strings.ToLower(ext)
+			if !strings.HasPrefix(ext, ".") {
+				ext = "." + ext
+			}
+			o.AllowedExtensions[fieldPath(field)] = append(o.AllowedExtensions[fieldPath(field)], ext)
+		}
+	}
+}
+
+// WithSanitizedFilenames makes the names of uploaded files safe to use on disk,
+// e.g. "../../etc/passwd" becomes "passwd".
+func WithSanitizedFilenames() Option {
+	return func(o *Options) {
+		o.SanitizeFilenames = true
+	}
+}
+
+// WithMaxTotalUploadSize fails parsing with a *FileTooLargeError once the uploaded files exceed n bytes together.
+func WithMaxTotalUploadSize(n int64) Option {
+	return func(o *Options) {
+		o.MaxTotalUploadSize = n
+	}
+}
+
+// FileScanner inspects an uploaded file, e.g. for malware or content moderation. Returning an error rejects the file.
+type FileScanner func(ctx context.Context, header *multipart.FileHeader, file io.Reader) error
+
+// WithFileScanner runs scan over every file of a multipart form while it is parsed. A rejected file fails the parse
+// with a *FileRejectedError and every stored upload is removed. FormStream leaves scanning to its callbacks.
+func WithFileScanner(scan FileScanner) Option {
+	return func(o *Options) {
+		o.FileScanner = scan
+	}
+}
+
+// WithMaxBindFileSize makes ToBind report uploads over n bytes bound into []byte fields
+// as a *BindError wrapping a *FileTooLargeError, instead of reading them into memory.
+func WithMaxBindFileSize(n int64) Option {
+	return func(o *Options) {
+		o.MaxBindFileSize = n
+	}
+}
+
+// WithInMemoryUploads keeps uploaded files in memory only, for read-only file systems. Files larger than
+// MaxMemory together, 32MB when unset, fail with a *RequestTooLargeError instead of spilling into temporary files.
+func WithInMemoryUploads() Option {
+	return func(o *Options) {
+		o.InMemoryUploads = true
+	}
+}
+
+// WithoutTypeConversion keeps form and query values as the strings they were sent as.
+func WithoutTypeConversion() Option {
+	return func(o *Options) {
+		o.NoTypeConversion = true
+	}
+}
+
+// WithCommaSeparatedArrays binds comma separated values into slice fields, e.g. ?ids=1,2,3 into []int{1, 2, 3}
+// and ?tags=a,b into []string{"a", "b"}. Values bound into other fields keep their commas.
+func WithCommaSeparatedArrays() Option {
+	return func(o *Options) {
+		o.CommaSeparatedArrays = true
+	}
+}
+
+// WithFieldType converts the value at path into t, e.g. WithFieldType("zip", String)
+// keeps "01234" and "10001" as strings. Bracket paths such as "items[zip]" are accepted.
+func WithFieldType(path string, t FieldType) Option {
+	return func(o *Options) {
+		if o.FieldTypes == nil {
+			o.FieldTypes = make(map[string]FieldType)
+		}
+		o.FieldTypes[fieldPath(path)] = t
+	}
+}
+
+/*
+WithTransformer rewrites the values at path while parsing, after their type conversion
+e.g. WithTransformer("users[*].email", lowercase) lowercases the email of every user.
+"[*]" and "[0]" match any slice index, several transformers on one path run in the order they were added
+*/
+func WithTransformer(path string, fn ValueTransformer) Option {
+	return func(o *Options) {
+		if o.Transformers == nil {
+			o.Transformers = make(map[string][]ValueTransformer)
+		}
+		key := transformPath(path)
+		o.Transformers[key] = append(o.Transformers[key], fn)
+	}
+}
+
+// WithBoolTokens converts the given form and query values into booleans,
+// e.g. WithBoolTokens([]string{"on", "yes", "1"}, []string{"off", "no", "0"}).
+func WithBoolTokens(trueTokens []string, falseTokens []string) Option {
+	return func(o *Options) {
+		o.TrueTokens = trueTokens
+		o.FalseTokens = falseTokens
+	}
+}
+
+// WithNullTokens sets the literal values standing for null, e.g. WithNullTokens("null", "nil", "").
+// Calling it without tokens turns null handling off, "null" then binds as a plain string.
+func WithNullTokens(tokens ...string) Option {
+	return func(o *Options) {
+		o.NullTokens = append([]string{}, tokens...)
+	}
+}
+
+// WithMaxArrayIndex limits the index accepted in keys such as items[42]. FormDataE, QueryE and Parse
+// fail with ErrArrayIndexTooLarge beyond it, Query keeps such keys in a map instead of a slice.
+func WithMaxArrayIndex(n int) Option {
+	return func(o *Options) {
+		o.MaxArrayIndex = n
+	}
+}
+
+// WithDuplicateKeys keeps the values of a repeated plain key by policy, e.g. WithDuplicateKeys(LastWins)
+// gives {"sort": "b"} for "sort=a&sort=b" like most frameworks do.
+func WithDuplicateKeys(policy DuplicatePolicy) Option {
+	return func(o *Options) {
+		o.DuplicateKeys = policy
+	}
+}
+
+// WithNumberPolicy sets what integers past the int64 range and exponents such as "1e10" give, which stay strings
+// by default while "10000000000" becomes an int, e.g. WithNumberPolicy(NumberAsFloat) parses both into float64.
+func WithNumberPolicy(policy NumberPolicy) Option {
+	return func(o *Options) {
+		o.Numbers = policy
+	}
+}
+
+// WithPartHeaders records the MIME headers of every multipart part, values included,
+// e.g. FormDataE(r, WithPartHeaders()) then PartHeaders("meta")[0].Get("Content-Type").
+func WithPartHeaders() Option {
+	return func(o *Options) {
+		o.PartHeaders = true
+	}
+}
+
+// WithJsonParts decodes multipart value parts sent with Content-Type application/json, or a +json type,
+// e.g. a "meta" part holding {"tags": ["a"]} gives {"meta": {"tags": ["a"]}} instead of a string.
+// A part that is not valid json fails parsing.
+func WithJsonParts() Option {
+	return func(o *Options) {
+		o.JsonParts = true
+	}
+}
+
+// WithJsonValues decodes form and query values holding a json object or array, as frontends send stringified filters,
+// e.g. "filters={"status":"active"}" gives {"filters": {"status": "active"}}. Values that are not valid json stay strings.
+func WithJsonValues() Option {
+	return func(o *Options) {
+		o.JsonValues = true
+	}
+}
+
+// WithContext hands ctx to the converters registered with RegisterConverterCtx, e.g. for FromValues
+// which has no request. Entry points given an *http.Request otherwise use its context.
+func WithContext(ctx context.Context) Option {
+	return func(o *Options) {
+		o.ctx = ctx
+	}
+}
+
+// WithBeforeBind adds a hook changing the values before ToBind binds them, e.g. trimming every string.
+// It receives a copy, ToMap and the JSON methods keep the parsed values.
+func WithBeforeBind(hook BeforeBindHook) Option {
+	return func(o *Options) {
+		o.BeforeBind = append(o.BeforeBind, hook)
+	}
+}
+
+// WithAfterBind adds a hook post-processing the model once ToBind bound it, e.g. stamping audit fields.
+func WithAfterBind(hook AfterBindHook) Option {
+	return func(o *Options) {
+		o.AfterBind = append(o.AfterBind, hook)
+	}
+}
+
+// WithQueryMerged makes FormData and FormDataE also read the query string, e.g. a POST to "/items?page=2"
+// with body "name=John" gives {"page": 2, "name": "John"}. A key sent in both keeps the body value.
+func WithQueryMerged() Option {
+	return func(o *Options) {
+		o.MergeQuery = true
+	}
+}
+
+// WithPreserveOrder keeps json body and query string keys in the order they arrived, in ToOrderedMap and
+// the JSON methods such as ToJsonString, e.g. for recomputing a signature or logging the received payload.
+// Form bodies are read into url.Values by net/http, which does not keep the order.
+func WithPreserveOrder() Option {
+	return func(o *Options) {
+		o.PreserveOrder = true
+	}
+}
+
+// WithMixedKeys sets what a key mixing indexes and names such as "items[0]=a&items[total]=3" gives,
+// e.g. WithMixedKeys(RejectMixedKeys) fails instead of binding ["a"] into a slice field.
+func WithMixedKeys(policy MixedKeyPolicy) Option {
+	return func(o *Options) {
+		o.MixedKeys = policy
+	}
+}
+
+// WithQsCompat parses form and query keys like node-qs and Rails, so payloads built by those ecosystems
+// round-trip identically, e.g. "a[]=1&a[]=2&a[2][b]=3" gives {"a": ["1", "2", {"b": "3"}]},
+// "a[100]=x" gives {"a": {"100": "x"}} and "user.name=John" keeps the key "user.name".
+func WithQsCompat() Option {
+	return func(o *Options) {
+		o.QsCompat = true
+	}
+}
+
+// WithLiteralDotKeys keeps dots in form and query keys, e.g. "user.name=John" gives {"user.name": "John"}
+// instead of {"user": {"name": "John"}}. WithQsCompat keeps dots in keys too.
+func WithLiteralDotKeys() Option {
+	return func(o *Options) {
+		o.LiteralDotKeys = true
+	}
+}
+
+// WithStrictKeys rejects malformed form and query keys such as "a[b", "a[][]" or "a[0]=1&a[name]=2"
+// with a *ParseError wrapping ErrMalformedKey.
+func WithStrictKeys() Option {
+	return func(o *Options) {
+		o.StrictKeys = true
+	}
+}
+
+// WithSparseArrays keeps items at their index, e.g. items[2]=x gives [nil, nil, "x"].
+func WithSparseArrays() Option {
+	return func(o *Options) {
+		o.SparseArrays = true
+	}
+}
+
+// WithMaxDepth limits bracket key nesting, e.g. with 2 "a[b][c][d]=1" gives {"a": {"b": {"c": {"[d]": 1}}}}.
+func WithMaxDepth(n int) Option {
+	return func(o *Options) {
+		o.MaxDepth = n
+	}
+}
+
+// WithCsvDelimiter sets the column separator used by Csv, e.g. ';' or '\t'.
+func WithCsvDelimiter(delimiter rune) Option {
+	return func(o *Options) {
+		o.CsvDelimiter = delimiter
+	}
+}
+
+// WithoutCsvHeader makes Csv key columns by their index instead of a header row.
+func WithoutCsvHeader() Option {
+	return func(o *Options) {
+		o.CsvNoHeader = true
+	}
+}
+
+// optionsOf passes already resolved options on to another entry point.
+func optionsOf(options *Options) Option {
+	return func(o *Options) {
+		*o = options.clone()
+	}
+}
+
+// tagName is the struct tag to bind with, options may be nil.
+func (o *Options) tagName() string {
+	if o != nil && o.TagName != "" {
+		return o.TagName
+	}
+	return defaultTagName()
+}
+
+// decodesJson reports whether JsonRequest binds with encoding/json, the binder handles other tag names,
+// snake_case keys and times without zone.
+func (o *Options) decodesJson() bool {
+	return o.tagName() == "json" && (o == nil || !o.SnakeCaseFields && o.Location == nil)
+}
+
+func (o *Options) maxArrayIndex() int {
+	switch {
+	case o != nil && o.QsCompat && o.MaxArrayIndex == 0:
+		return qsArrayLimit
+	case o == nil || o.MaxArrayIndex == 0:
+		return DefaultMaxArrayIndex
+	case o.MaxArrayIndex < 0:
+		return math.MaxInt
+	}
+	return o.MaxArrayIndex
+}
+
+// maxFileSize is the size limit of the files uploaded under field, negative without limit.
+func (o *Options) maxFileSize(field string) int64 {
+	if n, ok := o.MaxFileSizes[fieldPath(field)]; ok {
+		return n
+	}
+	return -1
+}
+
+// limitsUploads reports whether multipart bodies have to be read part by part.
+func (o *Options) limitsUploads() bool {
+	return len(o.MaxFileSizes) > 0 || o.MaxTotalUploadSize > 0 || len(o.MaxFiles) > 0 ||
+		len(o.AllowedMimeTypes) > 0 || len(o.AllowedExtensions) > 0 || o.InMemoryUploads || o.FileScanner != nil ||
+		o.PartHeaders || o.JsonParts || o.TempDir != ""
+}
+
+// log reports a swallowed condition to the configured logger, options may be nil.
+func (o *Options) log(level slog.Level, msg string, args ...any) {
+	if o == nil || o.Logger == nil {
+		return
+	}
+	o.Logger.Log(context.Background(), level, msg, args...)
+}
+
+func (o *Options) maxDepth() int {
+	switch {
+	case o == nil:
+		return 0
+	case o.QsCompat && o.MaxDepth == 0:
+		return qsDepth
+	}
+	return o.MaxDepth
+}
+
+// qsCompat reports whether keys are expanded like node-qs and Rails do, options may be nil.
+func (o *Options) qsCompat() bool {
+	return o != nil && o.QsCompat
+}
+
+// dotKeys reports whether dots separate the segments of form and query keys, options may be nil.
+func (o *Options) dotKeys() bool {
+	return o == nil || !o.LiteralDotKeys && !o.QsCompat
+}
+
+// keySegments splits a form or query key into its path segments, on dots too unless LiteralDotKeys is set.
+func (o *Options) keySegments(key string, buf []string) []string {
+	if o.dotKeys() {
+		return pathSegments(key, buf)
+	}
+	return bracketSegments(key, buf)
+}
+
+// duplicateKeys returns the policy for repeated plain keys, options may be nil.
+func (o *Options) duplicateKeys() DuplicatePolicy {
+	if o == nil {
+		return Combine
+	}
+	return o.DuplicateKeys
+}
+
+// mixedKeys returns the policy for keys mixing indexes and names, options may be nil.
+func (o *Options) mixedKeys() MixedKeyPolicy {
+	switch {
+	case o == nil:
+		return DropNamedKeys
+	case o.MixedKeys != DropNamedKeys:
+		return o.MixedKeys
+	case o.StrictKeys:
+		return RejectMixedKeys
+	case o.QsCompat:
+		return KeepMixedMap
+	}
+	return DropNamedKeys
+}
+
+// strictKeys reports whether malformed keys fail parsing, options may be nil.
+func (o *Options) strictKeys() bool {
+	return o != nil && o.StrictKeys
+}
+
+var (
+	defaultsMu sync.RWMutex
+	defaults   Options
+)
+
+// Configure sets the options every entry point starts from, options passed to a call are applied on top,
+// e.g. Configure(Options{MaxMemory: 8 << 20, MaxBodySize: 64 << 20}). Call it at start-up.
+func Configure(options Options) {
+	defaultsMu.Lock()
+	defer defaultsMu.Unlock()
+	defaults = options.clone()
+}
+
+// SetDefaultMaxMemory sets the MaxMemory every entry point starts from, see WithMaxMemory.
+func SetDefaultMaxMemory(n int64) {
+	defaultsMu.Lock()
+	defer defaultsMu.Unlock()
+	defaults.MaxMemory = n
+}
+
+// SetTempDir makes multipart parsing store the uploads over MaxMemory in dir, e.g. a dedicated volume.
+// It sets the TempDir every entry point starts from and leaves os.TempDir alone. It fails when dir is not a directory.
+func SetTempDir(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("inrequest: %s is not a directory", dir)
+	}
+	defaultsMu.Lock()
+	defer defaultsMu.Unlock()
+	defaults.TempDir = dir
+	return nil
+}
+
+// clone copies o, leaving no map or slice shared with the copy.
+func (o Options) clone() Options {
+	o.FieldTypes = cloneMap(o.FieldTypes)
+	o.Transformers = cloneMap(o.Transformers)
+	for path, chain := range o.Transformers {
+		o.Transformers[path] = append([]ValueTransformer(nil), chain...)
+	}
+	o.MaxFileSizes = cloneMap(o.MaxFileSizes)
+	o.MaxFiles = cloneMap(o.MaxFiles)
+	o.AllowedMimeTypes = cloneMap(o.AllowedMimeTypes)
+	o.AllowedExtensions = cloneMap(o.AllowedExtensions)
+	o.RedactedKeys = append([]string(nil), o.RedactedKeys...)
+	o.BeforeBind = append([]BeforeBindHook(nil), o.BeforeBind...)
+	o.AfterBind = append([]AfterBindHook(nil), o.AfterBind...)
+	for field, types := range o.AllowedMimeTypes {
+		o.AllowedMimeTypes[field] = append([]string(nil), types...)
+	}
+	for field, extensions := range o.AllowedExtensions {
+		o.AllowedExtensions[field] = append([]string(nil), extensions...)
+	}
+	return o
+}
+
+func cloneMap[K comparable, V any](m map[K]V) map[K]V {
+	if m == nil {
+		return nil
+	}
+	clone := make(map[K]V, len(m))
+	for k, v := range m {
+		clone[k] = v
+	}
+	return clone
+}
+
+func newOptions(opts []Option) *Options {
+	defaultsMu.RLock()
+	options := defaults.clone()
+	defaultsMu.RUnlock()
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &options
+}
+
+// requestOptions is newOptions for an entry point parsing r, its context reaches the converters unless WithContext set one.
+func requestOptions(r *http.Request, opts []Option) *Options {
+	options := newOptions(opts)
+	if options.ctx == nil {
+		options.ctx = r.Context()
+	}
+	return options
+}
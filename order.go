@@ -0,0 +1,194 @@
+package inrequest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// OrderedMap holds request values in the order their keys arrived, see WithPreserveOrder.
+// Nested maps are *OrderedMap too, and it encodes to JSON in Keys order.
+type OrderedMap struct {
+	Keys   []string
+	Values map[string]interface{}
+}
+
+// Get returns the value of key.
+func (m *OrderedMap) Get(key string) (interface{}, bool) {
+	value, ok := m.Values[key]
+	return value, ok
+}
+
+func (m *OrderedMap) MarshalJSON() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+	for i, key := range m.Keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(m.Values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+/*
+Recording the order keys arrived in, with the order of every nested map
+e.g. {"b": 1, "a": {"d": 2, "c": 3}} records [b a] with [d c] under "a".
+Array items and index keys share one nested order, as items usually hold the same keys
+*/
+type keyOrder struct {
+	keys     []string
+	children map[string]*keyOrder
+	items    *keyOrder
+}
+
+// child records key and returns the order of its value.
+func (o *keyOrder) child(key string) *keyOrder {
+	if child, ok := o.children[key]; ok {
+		return child
+	}
+	if o.children == nil {
+		o.children = make(map[string]*keyOrder)
+	}
+	child := &keyOrder{}
+	if key == "" || isDigits(key) {
+		child = o.item()
+	}
+	o.keys = append(o.keys, key)
+	o.children[key] = child
+	return child
+}
+
+// item returns the order shared by the array items.
+func (o *keyOrder) item() *keyOrder {
+	if o.items == nil {
+		o.items = &keyOrder{}
+	}
+	return o.items
+}
+
+/*
+Converting value into *OrderedMap values following order, which may be nil
+e.g. keys missing from order, such as values added after parsing, follow in sorted order like encoding/json sorts them
+*/
+func orderedValue(value interface{}, order *keyOrder) interface{} {
+	switch v := value.(type) {
+	case RequestValue:
+		result := &OrderedMap{Keys: make([]string, 0, len(v)), Values: make(map[string]interface{}, len(v))}
+		if order != nil {
+			for _, key := range order.keys {
+				if item, ok := v[key]; ok {
+					result.Keys = append(result.Keys, key)
+					result.Values[key] = orderedValue(item, order.children[key])
+				}
+			}
+		}
+		rest := make([]string, 0, len(v)-len(result.Keys))
+		for key := range v {
+			if _, ok := result.Values[key]; !ok {
+				rest = append(rest, key)
+			}
+		}
+		sort.Strings(rest)
+		for _, key := range rest {
+			result.Keys = append(result.Keys, key)
+			result.Values[key] = orderedValue(v[key], nil)
+		}
+		return result
+	case []interface{}:
+		var items *keyOrder
+		if order != nil {
+			items = order.items
+		}
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = orderedValue(item, items)
+		}
+		return result
+	}
+	return value
+}
+
+// orderedJsonParser is parseJson recording the order of the keys into order.
+func orderedJsonParser(order *keyOrder) bodyParser {
+	return func(r *http.Request) (RequestValue, error) {
+		value, err := decodeOrdered(json.NewDecoder(r.Body), order)
+		if err != nil {
+			return nil, err
+		}
+		result, ok := value.(RequestValue)
+		if !ok && value != nil {
+			return nil, errors.New("json: cannot unmarshal a non object body into a map")
+		}
+		return result, nil
+	}
+}
+
+// decodeOrdered decodes the next JSON value from dec like json.Unmarshal into an interface{} does.
+func decodeOrdered(dec *json.Decoder, order *keyOrder) (interface{}, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		result := make(RequestValue)
+		for dec.More() {
+			token, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, _ := token.(string)
+			if result[key], err = decodeOrdered(dec, order.child(key)); err != nil {
+				return nil, err
+			}
+		}
+		_, err = dec.Token()
+		return result, err
+	case json.Delim('['):
+		result := make([]interface{}, 0)
+		for dec.More() {
+			item, err := decodeOrdered(dec, order.item())
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, item)
+		}
+		_, err = dec.Token()
+		return result, err
+	}
+	return token, nil
+}
+
+// queryOrder records the order of the keys of a raw query string, split into segments like the values are.
+func queryOrder(rawQuery string, options *Options) *keyOrder {
+	order := &keyOrder{}
+	var buf [8]string
+	for _, part := range strings.Split(rawQuery, "&") {
+		name, _, _ := strings.Cut(part, "=")
+		key, err := url.QueryUnescape(name)
+		if err != nil || key == "" {
+			continue
+		}
+		node := order
+		for _, segment := range limitSegments(options.keySegments(key, buf[:0]), options.maxDepth()) {
+			node = node.child(segment)
+		}
+	}
+	return order
+}
@@ -0,0 +1,61 @@
+package inrequest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPreserveOrder(t *testing.T) {
+	body := `{"zeta":1,"alpha":{"mid":true,"end":null,"begin":"x"},"items":[{"sku":"b","qty":2},{"qty":1,"sku":"a"}],"beta":[]}`
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		return req
+	}
+
+	t.Run("should encode json keys in the order they arrived", func(t *testing.T) {
+		result, err := Json(newRequest(), WithPreserveOrder())
+		if err != nil {
+			t.Fatal(err)
+		}
+		target := `{"zeta":1,"alpha":{"mid":true,"end":null,"begin":"x"},"items":[{"sku":"b","qty":2},{"sku":"a","qty":1}],"beta":[]}`
+		if encoded, _ := result.ToJsonString(); encoded != target {
+			t.Fatalf("expected %s, got %s", target, encoded)
+		}
+		if keys := result.ToOrderedMap().Keys; !reflect.DeepEqual(keys, []string{"zeta", "alpha", "items", "beta"}) {
+			t.Fatalf("unexpected ordered keys %v", keys)
+		}
+	})
+	t.Run("should decode the same values as without the option", func(t *testing.T) {
+		ordered, _ := Json(newRequest(), WithPreserveOrder())
+		plain, _ := Json(newRequest())
+		if !reflect.DeepEqual(ordered.ToMap(), plain.ToMap()) {
+			t.Fatalf("expected %v, got %v", plain.ToMap(), ordered.ToMap())
+		}
+	})
+	t.Run("should sort keys without the option", func(t *testing.T) {
+		result, _ := Json(newRequest())
+		if keys := result.ToOrderedMap().Keys; !reflect.DeepEqual(keys, []string{"alpha", "beta", "items", "zeta"}) {
+			t.Fatalf("unexpected sorted keys %v", keys)
+		}
+	})
+	t.Run("should keep query keys in the order they arrived", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?sort=name&user[zip]=1&user[city]=Paris&page=2", nil)
+		encoded, _ := Query(req, WithPreserveOrder()).ToJsonString()
+		if target := `{"sort":"name","user":{"zip":1,"city":"Paris"},"page":2}`; encoded != target {
+			t.Fatalf("expected %s, got %s", target, encoded)
+		}
+	})
+	t.Run("should report a malformed body", func(t *testing.T) {
+		for _, body := range []string{`{"a":`, `[1,2]`} {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			if _, err := Json(req, WithPreserveOrder()); err == nil {
+				t.Fatalf("expected an error for %s", body)
+			}
+		}
+	})
+}
@@ -0,0 +1,77 @@
+package inrequest
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// ParserFunc parses a request into a Request under the options given to Parse or Middleware.
+type ParserFunc func(r *http.Request, opts ...Option) (Request, error)
+
+var (
+	parsersMu sync.RWMutex
+	parsers   = map[string]ParserFunc{}
+)
+
+// RegisterParser makes Parse dispatch requests of contentType to fn,
+// e.g. RegisterParser("application/vnd.company+json", parseCompanyJson).
+// Registered parsers take precedence over the built-in ones and receive the options of the call.
+func RegisterParser(contentType string, fn ParserFunc) {
+	parsersMu.Lock()
+	defer parsersMu.Unlock()
+	parsers[strings.ToLower(contentType)] = fn
+}
+
+func registeredParser(mediaType string) (ParserFunc, bool) {
+	parsersMu.RLock()
+	defer parsersMu.RUnlock()
+	fn, ok := parsers[mediaType]
+	return fn, ok
+}
+
+/*
+Parsing the request according to its Content-Type
+e.g. multipart/form-data and application/x-www-form-urlencoded are read as FormData,
+application/json as Json, application/xml as Xml, application/msgpack as Msgpack, application/cbor as Cbor and a request without body as Query,
+parsers added with RegisterParser are consulted first
+*/
+func Parse(r *http.Request, opts ...Option) (Request, error) {
+	mediaType := requestMediaType(r)
+	if fn, ok := registeredParser(mediaType); ok {
+		return fn(r, opts...)
+	}
+	switch {
+	case mediaTypeAccepted(mediaType, formMediaTypes):
+		return FormDataE(r, opts...)
+	case mediaTypeAccepted(mediaType, jsonMediaTypes):
+		return Json(r, opts...)
+	case mediaTypeAccepted(mediaType, xmlMediaTypes):
+		return Xml(r, opts...)
+	case mediaTypeAccepted(mediaType, msgpackMediaTypes):
+		return Msgpack(r, opts...)
+	case mediaTypeAccepted(mediaType, cborMediaTypes):
+		return Cbor(r, opts...)
+	case mediaType == "" && !hasBody(r):
+		return QueryE(r, opts...)
+	}
+	return nil, &ParseError{Err: ErrUnsupportedMediaType}
+}
+
+// ParseInto parses the request into model. On failure it writes the response of WriteError,
+// e.g. a 413 for an oversized body, and returns false, so handlers can simply return.
+func ParseInto(w http.ResponseWriter, r *http.Request, model interface{}, opts ...Option) bool {
+	request, err := Parse(r, opts...)
+	if err == nil {
+		err = request.ToBind(model)
+	}
+	if err != nil {
+		WriteError(w, err)
+		return false
+	}
+	return true
+}
+
+func hasBody(r *http.Request) bool {
+	return r.Body != nil && r.Body != http.NoBody && r.ContentLength != 0
+}
@@ -0,0 +1,181 @@
+package inrequest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("should detect the request type from its content type", func(t *testing.T) {
+		jsonReq := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"John"}`))
+		jsonReq.Header.Set("Content-Type", "application/json")
+		formReq := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("name=John"))
+		formReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		queryReq := httptest.NewRequest(http.MethodGet, "/?name=John", nil)
+
+		cases := map[string]*http.Request{
+			"json":       jsonReq,
+			"urlencoded": formReq,
+			"multipart":  newMultipartRequest(t, map[string]string{"name": "John"}, nil),
+			"query":      queryReq,
+		}
+		for name, req := range cases {
+			result, err := Parse(req)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if result.ToMap()["name"] != "John" {
+				t.Fatalf("%s: expected name John, got %v", name, result.ToMap())
+			}
+		}
+	})
+	t.Run("should reject unknown content types", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("John"))
+		req.Header.Set("Content-Type", "text/plain")
+
+		if _, err := Parse(req); !IsParseError(err) {
+			t.Fatalf("expected parse error, got %v", err)
+		}
+	})
+}
+
+func TestParseInto(t *testing.T) {
+	type User struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+
+	t.Run("should bind the request into the model", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"John","age":31}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		user := User{}
+
+		if !ParseInto(w, req, &user) {
+			t.Fatalf("expected success, got response %s", w.Body.String())
+		}
+		if user.Name != "John" || user.Age != 31 {
+			t.Fatalf("unexpected user %v", user)
+		}
+	})
+	t.Run("should write a bad request response on failure", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"John","age":`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		user := User{}
+
+		if ParseInto(w, req, &user) {
+			t.Fatal("expected failure")
+		}
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("expected status 400, got %d", w.Code)
+		}
+		var body map[string]string
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body["error"] == "" {
+			t.Fatalf("expected json error body, got %s", w.Body.String())
+		}
+	})
+	t.Run("should answer with the status matching the error", func(t *testing.T) {
+		for _, test := range []struct {
+			contentType string
+			body        string
+			opts        []Option
+			status      int
+		}{
+			{"application/json", `{"name":"John","age":"old"}`, nil, http.StatusUnprocessableEntity},
+			{"application/json", `{"name":"John","age":31}`, []Option{WithMaxBodySize(4)}, http.StatusRequestEntityTooLarge},
+			{"text/plain", `John`, nil, http.StatusUnsupportedMediaType},
+		} {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(test.body))
+			req.Header.Set("Content-Type", test.contentType)
+			w := httptest.NewRecorder()
+			user := User{}
+
+			if ParseInto(w, req, &user, test.opts...) {
+				t.Fatalf("expected failure for %s", test.body)
+			}
+			if w.Code != test.status {
+				t.Fatalf("expected status %d for %s, got %d", test.status, test.body, w.Code)
+			}
+		}
+	})
+}
+
+func TestRegisterParser(t *testing.T) {
+	RegisterParser("application/vnd.company+json", func(r *http.Request, opts ...Option) (Request, error) {
+		result, err := Json(r, opts...)
+		result.result["vendor"] = "company"
+		return result, err
+	})
+	defer func() {
+		parsersMu.Lock()
+		delete(parsers, "application/vnd.company+json")
+		parsersMu.Unlock()
+	}()
+
+	t.Run("should dispatch to the registered parser", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"John"}`))
+		req.Header.Set("Content-Type", "Application/Vnd.Company+JSON; charset=utf-8")
+		result, err := Parse(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.ToMap()["vendor"] != "company" || result.ToMap()["name"] != "John" {
+			t.Fatalf("expected registered parser result, got %v", result.ToMap())
+		}
+	})
+	t.Run("should pass the options to the registered parser", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"John"}`))
+		req.Header.Set("Content-Type", "application/vnd.company+json")
+		_, err := Parse(req, WithMaxBodySize(4))
+		var tooLarge *RequestTooLargeError
+		if !errors.As(err, &tooLarge) || tooLarge.Limit != "body" {
+			t.Fatalf("expected the body limit to apply, got %v", err)
+		}
+	})
+	t.Run("should keep built-in parsers for other types", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"John"}`))
+		req.Header.Set("Content-Type", "application/json")
+		result, err := Parse(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := result.ToMap()["vendor"]; ok {
+			t.Fatalf("unexpected registered parser result %v", result.ToMap())
+		}
+	})
+}
+
+func TestLogger(t *testing.T) {
+	newLogger := func() (*slog.Logger, *bytes.Buffer) {
+		buf := &bytes.Buffer{}
+		return slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})), buf
+	}
+
+	t.Run("should log the failure hidden by Query", func(t *testing.T) {
+		logger, buf := newLogger()
+		Query(httptest.NewRequest(http.MethodGet, "/?a=1&b=2", nil), WithMaxKeys(1), WithLogger(logger))
+		if !strings.Contains(buf.String(), "level=WARN") || !strings.Contains(buf.String(), "query parse failed") {
+			t.Fatalf("expected a warning, got %s", buf.String())
+		}
+	})
+	t.Run("should log unknown fields dropped by ToBind", func(t *testing.T) {
+		logger, buf := newLogger()
+		var user struct {
+			Name string `json:"name"`
+		}
+		Query(httptest.NewRequest(http.MethodGet, "/?name=John&role=admin", nil), WithLogger(logger)).ToBind(&user)
+		if !strings.Contains(buf.String(), "dropped unknown field") || !strings.Contains(buf.String(), "field=role") {
+			t.Fatalf("expected the dropped role field logged, got %s", buf.String())
+		}
+	})
+	t.Run("should log nothing without logger", func(t *testing.T) {
+		Query(httptest.NewRequest(http.MethodGet, "/?a=1&b=2", nil), WithMaxKeys(1))
+	})
+}
@@ -0,0 +1,58 @@
+package inrequest
+
+import "net/http"
+
+// Parser parses requests under its own options, independently of Configure and of other parsers,
+// so routes can use different limits, tag names or conversion rules side by side:
+//
+//	uploads := inrequest.New(inrequest.Options{MaxMemory: 64 << 20, MaxBodySize: 1 << 30})
+//	api := inrequest.New(inrequest.Options{TagName: "api", DisallowUnknownFields: true})
+//
+// Options passed to a method apply on top of the parser options for that call only.
+type Parser struct {
+	options Options
+}
+
+// New returns a Parser using options.
+func New(options Options) *Parser {
+	return &Parser{options: options.clone()}
+}
+
+// Options returns a copy of the parser options.
+func (p *Parser) Options() Options {
+	return p.options.clone()
+}
+
+func (p *Parser) FormData(r *http.Request, opts ...Option) FormRequest {
+	return FormData(r, p.with(opts)...)
+}
+
+func (p *Parser) FormDataE(r *http.Request, opts ...Option) (FormRequest, error) {
+	return FormDataE(r, p.with(opts)...)
+}
+
+func (p *Parser) Query(r *http.Request, opts ...Option) QueryRequest {
+	return Query(r, p.with(opts)...)
+}
+
+func (p *Parser) QueryE(r *http.Request, opts ...Option) (QueryRequest, error) {
+	return QueryE(r, p.with(opts)...)
+}
+
+func (p *Parser) Json(r *http.Request, opts ...Option) (JsonRequest, error) {
+	return Json(r, p.with(opts)...)
+}
+
+func (p *Parser) Parse(r *http.Request, opts ...Option) (Request, error) {
+	return Parse(r, p.with(opts)...)
+}
+
+// BindRequest fills model from every part of the request like the package BindRequest, under the parser options.
+func (p *Parser) BindRequest(r *http.Request, model interface{}, opts ...Option) error {
+	return BindRequest(r, model, p.with(opts)...)
+}
+
+// with puts the parser options in front of opts, replacing the package defaults.
+func (p *Parser) with(opts []Option) []Option {
+	return append([]Option{optionsOf(&p.options)}, opts...)
+}
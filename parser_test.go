@@ -0,0 +1,54 @@
+package inrequest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParser(t *testing.T) {
+	strict := New(Options{MaxKeys: 2, TagName: "api"})
+	loose := New(Options{NoTypeConversion: true})
+
+	t.Run("should parse with its own options", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?a=1&b=2&c=3", nil)
+		var tooLarge *RequestTooLargeError
+		if _, err := strict.QueryE(req); !errors.As(err, &tooLarge) {
+			t.Fatalf("expected the parser key limit, got %v", err)
+		}
+		if result := loose.Query(req).ToMap(); result["a"] != "1" {
+			t.Fatalf("expected values kept as strings, got %v", result)
+		}
+	})
+	t.Run("should bind with the parser tag name", func(t *testing.T) {
+		var user struct {
+			Name string `api:"full_name"`
+		}
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"full_name":"John"}`))
+		request, err := strict.Json(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = request.ToBind(&user); err != nil || user.Name != "John" {
+			t.Fatalf("expected John, got %q %v", user.Name, err)
+		}
+	})
+	t.Run("should ignore the package defaults", func(t *testing.T) {
+		Configure(Options{MaxKeys: 1})
+		t.Cleanup(func() { Configure(Options{}) })
+
+		req := httptest.NewRequest(http.MethodGet, "/?a=1&b=2", nil)
+		if _, err := loose.QueryE(req); err != nil {
+			t.Fatalf("expected the parser to ignore the defaults, got %v", err)
+		}
+	})
+	t.Run("should apply call options for one call only", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?zip=01234", nil)
+		loose.Query(req, WithFieldType("zip", Int))
+		if options := loose.Options(); options.FieldTypes != nil {
+			t.Fatalf("expected the parser options untouched, got %v", options.FieldTypes)
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package inrequest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// Problem is an RFC 7807 problem details document describing a parse or bind failure, see ProblemFrom.
+type Problem struct {
+	Type     string         `json:"type"`
+	Title    string         `json:"title"`
+	Status   int            `json:"status"`
+	Detail   string         `json:"detail,omitempty"`
+	Instance string         `json:"instance,omitempty"`
+	Errors   []ProblemField `json:"errors,omitempty"`
+}
+
+// ProblemField is the problem with one field, Tag names the failed rule as in FieldError.
+type ProblemField struct {
+	Field  string `json:"field"`
+	Tag    string `json:"tag,omitempty"`
+	Param  string `json:"param,omitempty"`
+	Detail string `json:"detail"`
+}
+
+/*
+Describing err as a problem document with the status WriteError would answer
+e.g. BindErrors for "age" and "email" give
+
+	{"type": "about:blank", "title": "Unprocessable Entity", "status": 422, "detail": "inrequest: bind: ...",
+	 "errors": [{"field": "age", "detail": "..."}, {"field": "email", "tag": "required", "detail": "is required"}]}
+
+Set Type and Instance on the result to point at your own documentation, a nil err gives a 500 problem
+*/
+func ProblemFrom(err error) *Problem {
+	status := errorStatus(err)
+	problem := &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Errors: problemFields(err),
+	}
+	if err != nil {
+		problem.Detail = err.Error()
+	}
+	return problem
+}
+
+// WriteProblem answers err with its problem document as application/problem+json.
+func WriteProblem(w http.ResponseWriter, err error) {
+	problem := ProblemFrom(err)
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
+}
+
+// problemFields lists the rejected fields of bind and validation errors, nil for other errors.
+func problemFields(err error) []ProblemField {
+	var fields []FieldError
+	var bindErrs BindErrors
+	var invalid ValidationErrors
+	var bindErr *BindError
+	switch {
+	case errors.As(err, &bindErrs):
+		fields = bindErrs
+	case errors.As(err, &invalid):
+		fields = invalid
+	case errors.As(err, &bindErr):
+		fields = []FieldError{{Field: bindErr.Field, Err: bindErr.Err}}
+	default:
+		return nil
+	}
+	problems := make([]ProblemField, len(fields))
+	for i, field := range fields {
+		problems[i] = ProblemField{Field: field.Field, Tag: field.Tag, Param: field.Param}
+		if field.Err != nil {
+			problems[i].Detail = field.Err.Error()
+		}
+	}
+	return problems
+}
@@ -0,0 +1,63 @@
+package inrequest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestProblem(t *testing.T) {
+	t.Run("should list the fields of bind errors", func(t *testing.T) {
+		var signup struct {
+			Age   int    `json:"age"`
+			Email string `json:"email" required:"true"`
+		}
+		req := httptest.NewRequest(http.MethodGet, "/?age=old", nil)
+		err := Query(req).ToBind(&signup)
+		if err == nil {
+			t.Fatal("expected a bind error")
+		}
+		problem := ProblemFrom(fmt.Errorf("signup: %w", err))
+		if problem.Status != http.StatusUnprocessableEntity || problem.Title != "Unprocessable Entity" || problem.Type != "about:blank" {
+			t.Fatalf("unexpected problem %+v", problem)
+		}
+		fields := make([]string, len(problem.Errors))
+		for i, field := range problem.Errors {
+			fields[i] = field.Field + ":" + field.Tag
+		}
+		if !reflect.DeepEqual(fields, []string{"age:", "email:required"}) {
+			t.Fatalf("unexpected problem fields %v", fields)
+		}
+	})
+	t.Run("should describe parse errors without fields", func(t *testing.T) {
+		problem := ProblemFrom(&ParseError{Err: ErrUnsupportedMediaType})
+		if problem.Status != http.StatusUnsupportedMediaType || problem.Errors != nil || problem.Detail == "" {
+			t.Fatalf("unexpected problem %+v", problem)
+		}
+	})
+	t.Run("should describe a nil error as a server error", func(t *testing.T) {
+		problem := ProblemFrom(nil)
+		if problem.Status != http.StatusInternalServerError || problem.Detail != "" {
+			t.Fatalf("unexpected problem %+v", problem)
+		}
+	})
+	t.Run("should write problem json", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		WriteProblem(rec, BindErrors{{Field: "name", Tag: "min", Param: "3", Err: errors.New("is too short")}})
+		if rec.Code != http.StatusUnprocessableEntity || rec.Header().Get("Content-Type") != "application/problem+json" {
+			t.Fatalf("unexpected response %d %s", rec.Code, rec.Header().Get("Content-Type"))
+		}
+		var problem Problem
+		if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+			t.Fatal(err)
+		}
+		target := []ProblemField{{Field: "name", Tag: "min", Param: "3", Detail: "is too short"}}
+		if !reflect.DeepEqual(problem.Errors, target) {
+			t.Fatalf("expected %+v, got %+v", target, problem.Errors)
+		}
+	})
+}
@@ -0,0 +1,7 @@
+module github.com/ezartsh/inrequest/protobind
+
+go 1.21
+
+require github.com/ezartsh/inrequest v1.0.0
+
+require google.golang.org/protobuf v1.30.0
@@ -0,0 +1,83 @@
+// Package protobind decodes application/x-protobuf request bodies for inrequest users.
+package protobind
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+
+	"github.com/ezartsh/inrequest"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+var mediaTypes = []string{"application/x-protobuf", "application/protobuf", "application/vnd.google.protobuf"}
+
+// ProtoRequest holds a decoded protobuf message together with its protojson map,
+// read through every accessor of inrequest.Request.
+type ProtoRequest struct {
+	inrequest.JsonRequest
+	message proto.Message
+}
+
+var _ inrequest.Request = ProtoRequest{}
+
+// Bind decodes the request body into msg, reading it under the body options of opts such as WithMaxBodySize.
+func Bind(r *http.Request, msg proto.Message, opts ...inrequest.Option) error {
+	data, err := readBody(r, opts)
+	if err != nil {
+		return err
+	}
+	if err = proto.Unmarshal(data, msg); err != nil {
+		return &inrequest.ParseError{Err: err}
+	}
+	return nil
+}
+
+// Parse decodes the request body into msg and exposes it as a RequestValue
+// keyed by the field names declared in the .proto file.
+func Parse(r *http.Request, msg proto.Message, opts ...inrequest.Option) (ProtoRequest, error) {
+	if err := Bind(r, msg, opts...); err != nil {
+		return ProtoRequest{JsonRequest: inrequest.FromMap(nil, opts...), message: msg}, err
+	}
+	jsonData, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(msg)
+	if err != nil {
+		return ProtoRequest{JsonRequest: inrequest.FromMap(nil, opts...), message: msg}, err
+	}
+	result := make(inrequest.RequestValue)
+	err = json.Unmarshal(jsonData, &result)
+	return ProtoRequest{JsonRequest: inrequest.FromMap(result, opts...), message: msg}, err
+}
+
+func (r ProtoRequest) Message() proto.Message {
+	return r.message
+}
+
+// ToBind merges the decoded message into model when it is a proto.Message of the same type,
+// otherwise it binds the protojson map like JsonRequest does.
+func (r ProtoRequest) ToBind(model interface{}) error {
+	if msg, ok := model.(proto.Message); ok && msg.ProtoReflect().Descriptor() == r.message.ProtoReflect().Descriptor() {
+		proto.Merge(msg, r.message)
+		return nil
+	}
+	return r.JsonRequest.ToBind(model)
+}
+
+func readBody(r *http.Request, opts []inrequest.Option) ([]byte, error) {
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || !accepted(mediaType) {
+			return nil, &inrequest.ParseError{Err: inrequest.ErrUnsupportedMediaType}
+		}
+	}
+	return inrequest.ReadBody(r, mediaTypes, opts...)
+}
+
+func accepted(mediaType string) bool {
+	for _, accept := range mediaTypes {
+		if mediaType == accept {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package protobind
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/ezartsh/inrequest"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/apipb"
+)
+
+func newProtoRequest(t *testing.T, msg proto.Message) *http.Request {
+	t.Helper()
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	return req
+}
+
+func TestBind(t *testing.T) {
+	t.Run("should decode the body into the message", func(t *testing.T) {
+		source := &apipb.Method{Name: "GetUser", RequestTypeUrl: "type.googleapis.com/User"}
+		target := &apipb.Method{}
+		if err := Bind(newProtoRequest(t, source), target); err != nil {
+			t.Fatal(err)
+		}
+		if !proto.Equal(source, target) {
+			t.Fatalf("expected %v, got %v", source, target)
+		}
+	})
+	t.Run("should fail once the body exceeds the size limit", func(t *testing.T) {
+		req := newProtoRequest(t, &apipb.Method{Name: "GetUser", RequestTypeUrl: "type.googleapis.com/User"})
+		req.ContentLength = -1
+		var tooLarge *inrequest.RequestTooLargeError
+		if err := Bind(req, &apipb.Method{}, inrequest.WithMaxBodySize(8)); !errors.As(err, &tooLarge) {
+			t.Fatalf("expected body too large error, got %v", err)
+		}
+	})
+	t.Run("should reject other content types", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte("{}")))
+		req.Header.Set("Content-Type", "application/json")
+		if err := Bind(req, &apipb.Method{}); !inrequest.IsParseError(err) {
+			t.Fatalf("expected parse error, got %v", err)
+		}
+	})
+}
+
+func TestParse(t *testing.T) {
+	source := &apipb.Method{Name: "GetUser", RequestTypeUrl: "type.googleapis.com/User", ResponseStreaming: true}
+	result, err := Parse(newProtoRequest(t, source), &apipb.Method{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should expose the message as map", func(t *testing.T) {
+		target := inrequest.RequestValue{
+			"name":               "GetUser",
+			"request_type_url":   "type.googleapis.com/User",
+			"response_streaming": true,
+		}
+		if !reflect.DeepEqual(result.ToMap(), target) {
+			t.Fatalf("expected %v, got %v", target, result.ToMap())
+		}
+	})
+	t.Run("should read values through the request accessors", func(t *testing.T) {
+		var request inrequest.Request = result
+		if request.GetString("name") != "GetUser" || !request.GetBool("response_streaming") || request.Has("missing") {
+			t.Fatalf("unexpected accessor values for %v", request.ToMap())
+		}
+	})
+	t.Run("should bind into plain structs and messages", func(t *testing.T) {
+		type Method struct {
+			Name      string `json:"name"`
+			Streaming bool   `json:"response_streaming"`
+		}
+		method := Method{}
+		if err := result.ToBind(&method); err != nil {
+			t.Fatal(err)
+		}
+		if method.Name != "GetUser" || !method.Streaming {
+			t.Fatalf("unexpected method %+v", method)
+		}
+		msg := &apipb.Method{}
+		if err := result.ToBind(msg); err != nil || !proto.Equal(msg, source) {
+			t.Fatalf("expected %v, got %v (%v)", source, msg, err)
+		}
+	})
+}
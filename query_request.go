@@ -1,36 +1,36 @@
 package inrequest
 
-import "encoding/json"
-
-type queryRequest struct {
-	result RequestValue
+type QueryRequest struct {
+	requestValues
+	options *Options
 }
 
-func (r queryRequest) ToMap() RequestValue {
+func (r QueryRequest) ToMap() RequestValue {
+	r.invalidate()
 	return r.result
 }
 
-func (r queryRequest) ToBind(model interface{}) error {
-	jsonData, err := json.Marshal(r.result)
-	if err != nil {
-		return err
-	}
-	if err = json.Unmarshal(jsonData, &model); err != nil {
-		return err
-	}
-	return nil
+// ToBind binds the query into model, fields are named by their `query` tag and fall back to `json` or the configured tag name.
+func (r QueryRequest) ToBind(model interface{}) error {
+	return bindHooked(r.options, r.result, model, binderOf(r.options, "query").bind)
+}
+
+// ToBindValidated binds like ToBind, then runs the validator set with SetValidator.
+// Bind and validation failures are returned together as ValidationErrors.
+func (r QueryRequest) ToBindValidated(model interface{}) error {
+	return bindValidated(r, model)
 }
 
-func (r queryRequest) ToJsonByte() ([]byte, error) {
-	jsonData, err := json.Marshal(r.result)
+func (r QueryRequest) ToJsonByte() ([]byte, error) {
+	jsonData, err := r.encode(r.result)
 	if err != nil {
 		return []byte{}, err
 	}
-	return jsonData, nil
+	return append([]byte(nil), jsonData...), nil
 }
 
-func (r queryRequest) ToJsonString() (string, error) {
-	jsonData, err := json.Marshal(r.result)
+func (r QueryRequest) ToJsonString() (string, error) {
+	jsonData, err := r.encode(r.result)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,278 @@
+package inrequest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestQueryTag(t *testing.T) {
+	type ListUsers struct {
+		PageSize int    `query:"page_size,omitempty" json:"pageSize"`
+		Sort     string `json:"sort"`
+		IDs      []int  `query:"id" json:"ids"`
+		Debug    bool   `query:"-" json:"debug"`
+		Range    struct {
+			From int `query:"gte" json:"from"`
+		} `query:"created" json:"range"`
+	}
+	req := httptest.NewRequest(http.MethodGet, "/?page_size=20&pageSize=50&sort=name&id=1&id=2&debug=true&created[gte]=10", nil)
+
+	t.Run("should name fields by query tag and fall back to json", func(t *testing.T) {
+		params := ListUsers{}
+		if err := Query(req).ToBind(&params); err != nil {
+			t.Fatal(err)
+		}
+		target := ListUsers{PageSize: 20, Sort: "name", IDs: []int{1, 2}}
+		target.Range.From = 10
+		if !reflect.DeepEqual(params, target) {
+			t.Fatalf("Failed binding query tags, expected %+v, got %+v", target, params)
+		}
+	})
+}
+
+func TestQueryOptions(t *testing.T) {
+	t.Run("should keep values as strings without type conversion", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?zip=75001&price=12.5&ids=1&ids=2", nil)
+		target := RequestValue{"zip": "75001", "price": "12.5", "ids": []interface{}{"1", "2"}}
+		if result := Query(req, WithoutTypeConversion()).ToMap(); !reflect.DeepEqual(result, target) {
+			t.Fatalf("Failed keeping strings, expected %v, got %v", target, result)
+		}
+	})
+	t.Run("should stop nesting past the max depth", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?a[b][c][d]=1&x[y]=2", nil)
+		target := RequestValue{
+			"a": RequestValue{"b": RequestValue{"c": RequestValue{"[d]": 1}}},
+			"x": RequestValue{"y": 2},
+		}
+		if result := Query(req, WithMaxDepth(2)).ToMap(); !reflect.DeepEqual(result, target) {
+			t.Fatalf("Failed limiting depth, expected %v, got %v", target, result)
+		}
+	})
+	t.Run("should convert values by their field type", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?zip=10001&age=31&items[0][sku]=0042&items[0][qty]=2&rate=3&active=true", nil)
+		result := Query(req,
+			WithFieldType("zip", String),
+			WithFieldType("items[sku]", Int),
+			WithFieldType("rate", Float),
+			WithFieldType("active", Bool),
+		).ToMap()
+		target := RequestValue{
+			"zip":    "10001",
+			"age":    31,
+			"items":  []interface{}{RequestValue{"sku": 42, "qty": 2}},
+			"rate":   float64(3),
+			"active": true,
+		}
+		if !reflect.DeepEqual(result, target) {
+			t.Fatalf("Failed converting field types, expected %v, got %v", target, result)
+		}
+	})
+	t.Run("should convert hinted fields without type conversion", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?zip=10001&age=31", nil)
+		result := Query(req, WithoutTypeConversion(), WithFieldType("age", Auto)).ToMap()
+		if !reflect.DeepEqual(result, RequestValue{"zip": "10001", "age": 31}) {
+			t.Fatalf("Failed converting hinted field, got %v", result)
+		}
+	})
+	t.Run("should convert bool tokens", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?newsletter=on&terms=YES&tracking=off&count=1&flag=1", nil)
+		result := Query(req, WithBoolTokens([]string{"on", "yes"}, []string{"off", "no"}), WithFieldType("flag", Bool)).ToMap()
+		target := RequestValue{"newsletter": true, "terms": true, "tracking": false, "count": 1, "flag": true}
+		if !reflect.DeepEqual(result, target) {
+			t.Fatalf("Failed converting bool tokens, expected %v, got %v", target, result)
+		}
+	})
+	t.Run("should compact array indexes by default", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?items[0]=a&items[5]=b", nil)
+		if result := Query(req).ToMap(); !reflect.DeepEqual(result, RequestValue{"items": []interface{}{"a", "b"}}) {
+			t.Fatalf("Failed compacting items, got %v", result)
+		}
+	})
+	t.Run("should keep dot keys literal with WithLiteralDotKeys", func(t *testing.T) {
+		result := Query(httptest.NewRequest(http.MethodGet, "/?user.name=John&items.0.price=10", nil), WithLiteralDotKeys()).ToMap()
+		target := RequestValue{"user.name": "John", "items.0.price": 10}
+		if !reflect.DeepEqual(result, target) {
+			t.Fatalf("expected dot keys kept, expected %v, got %v", target, result)
+		}
+	})
+	t.Run("should expand dot keys like bracket keys", func(t *testing.T) {
+		dots := Query(httptest.NewRequest(http.MethodGet, "/?user.name=John&items.0.price=10&items.1.price=5", nil)).ToMap()
+		brackets := Query(httptest.NewRequest(http.MethodGet, "/?user[name]=John&items[0][price]=10&items[1][price]=5", nil)).ToMap()
+		if !reflect.DeepEqual(dots, brackets) {
+			t.Fatalf("expected dot keys to expand like %v, got %v", brackets, dots)
+		}
+	})
+	t.Run("should resolve a plain key conflicting with nested keys the same way every time", func(t *testing.T) {
+		target := RequestValue{"a": RequestValue{"b": 2}}
+		for i := 0; i < 200; i++ {
+			result := Query(httptest.NewRequest(http.MethodGet, "/?a=1&a[b]=2", nil)).ToMap()
+			if !reflect.DeepEqual(result, target) {
+				t.Fatalf("run %d: expected %v, got %v", i, target, result)
+			}
+		}
+	})
+	t.Run("should push empty bracket keys in order", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?tags[]=a&tags[]=b&tags[]=c&ids[]=7&items[][id]=1&items[][id]=2", nil)
+		target := RequestValue{
+			"tags":  []interface{}{"a", "b", "c"},
+			"ids":   []interface{}{7},
+			"items": []interface{}{RequestValue{"id": 1}, RequestValue{"id": 2}},
+		}
+		if result := Query(req).ToMap(); !reflect.DeepEqual(result, target) {
+			t.Fatalf("Failed pushing items, expected %v, got %v", target, result)
+		}
+	})
+	t.Run("should keep items at their index with sparse arrays", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?items[0]=a&items[3]=b", nil)
+		target := RequestValue{"items": []interface{}{"a", nil, nil, "b"}}
+		if result := Query(req, WithSparseArrays()).ToMap(); !reflect.DeepEqual(result, target) {
+			t.Fatalf("Failed keeping sparse items, expected %v, got %v", target, result)
+		}
+	})
+	t.Run("should skip negative indexes with sparse arrays", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?items[-5]=x&tags[-1]=a&tags[1]=b", nil)
+		target := RequestValue{"items": []interface{}{}, "tags": []interface{}{nil, "b"}}
+		if result := Query(req, WithSparseArrays()).ToMap(); !reflect.DeepEqual(result, target) {
+			t.Fatalf("Failed skipping negative indexes, expected %v, got %v", target, result)
+		}
+	})
+	t.Run("should keep indexes past the maximum as map keys", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?items[2000000000]=x&tags[1]=a", nil)
+		target := RequestValue{"items": RequestValue{"2000000000": "x"}, "tags": []interface{}{nil, "a"}}
+		if result := Query(req, WithSparseArrays()).ToMap(); !reflect.DeepEqual(result, target) {
+			t.Fatalf("Failed capping indexes, expected %v, got %v", target, result)
+		}
+	})
+	t.Run("should split comma separated values into slice fields", func(t *testing.T) {
+		var filter struct {
+			IDs  []int    `query:"ids"`
+			Tags []string `query:"tags"`
+			Name string   `query:"name"`
+		}
+		req := httptest.NewRequest(http.MethodGet, "/?ids=1,2,3&tags=a,%20b&name=Smith,John", nil)
+		if err := Query(req, WithCommaSeparatedArrays()).ToBind(&filter); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(filter.IDs, []int{1, 2, 3}) || !reflect.DeepEqual(filter.Tags, []string{"a", "b"}) || filter.Name != "Smith,John" {
+			t.Fatalf("unexpected filter %+v", filter)
+		}
+		if err := Query(req).ToBind(&filter); err == nil {
+			t.Fatal("expected the comma separated ids to fail without the option")
+		}
+	})
+	t.Run("should parse keys like node-qs in qs compat mode", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?a[]=1&a[]=2&a[2][b]=3&big[100]=x&mixed[0]=b&mixed[b]=c&user.name=John&d[b][c][d][e][f][g][h]=i", nil)
+		result, err := QueryE(req, WithQsCompat())
+		if err != nil {
+			t.Fatal(err)
+		}
+		target := RequestValue{
+			"a":         []interface{}{"1", "2", RequestValue{"b": "3"}},
+			"big":       RequestValue{"100": "x"},
+			"mixed":     RequestValue{"0": "b", "b": "c"},
+			"user.name": "John",
+			"d": RequestValue{"b": RequestValue{"c": RequestValue{"d": RequestValue{"e": RequestValue{"f": RequestValue{
+				"[g][h]": "i",
+			}}}}}},
+		}
+		if got := result.ToMap(); !reflect.DeepEqual(got, target) {
+			t.Fatalf("Failed parsing qs keys, expected %v, got %v", target, got)
+		}
+	})
+	t.Run("should reject malformed keys with strict keys", func(t *testing.T) {
+		queries := []string{"a[b=1", "a[][]=x", "a[0]=1&a[name]=2", "a=1&a[b]=2", "a[b]c=1"}
+		for _, query := range queries {
+			_, err := QueryE(httptest.NewRequest(http.MethodGet, "/?"+query, nil), WithStrictKeys())
+			if !errors.Is(err, ErrMalformedKey) || !IsParseError(err) {
+				t.Fatalf("query %q: expected a parse error wrapping ErrMalformedKey, got %v", query, err)
+			}
+		}
+		req := httptest.NewRequest(http.MethodGet, "/?tags[]=a&tags[]=b&user[name]=John&items[0][id]=1", nil)
+		if _, err := QueryE(req, WithStrictKeys()); err != nil {
+			t.Fatalf("expected well formed keys to parse, got %v", err)
+		}
+	})
+	t.Run("should keep repeated plain keys by the duplicate policy", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?sort=name&sort=age&tags[]=a&tags[]=b", nil)
+		tags := []interface{}{"a", "b"}
+		policies := map[DuplicatePolicy]RequestValue{
+			Combine:   {"sort": []interface{}{"name", "age"}, "tags": tags},
+			FirstWins: {"sort": "name", "tags": tags},
+			LastWins:  {"sort": "age", "tags": tags},
+		}
+		for policy, target := range policies {
+			if result := Query(req, WithDuplicateKeys(policy)).ToMap(); !reflect.DeepEqual(result, target) {
+				t.Fatalf("policy %d: expected %v, got %v", policy, target, result)
+			}
+		}
+	})
+	t.Run("should resolve keys mixing indexes and names by the mixed key policy", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?items[0]=a&items[1]=b&items[total]=2", nil)
+		policies := map[MixedKeyPolicy]RequestValue{
+			DropNamedKeys: {"items": []interface{}{"a", "b"}},
+			KeepMixedMap:  {"items": RequestValue{"0": "a", "1": "b", "total": 2}},
+		}
+		for policy, target := range policies {
+			if result := Query(req, WithMixedKeys(policy)).ToMap(); !reflect.DeepEqual(result, target) {
+				t.Fatalf("policy %d: expected %v, got %v", policy, target, result)
+			}
+		}
+		if _, err := QueryE(req, WithMixedKeys(RejectMixedKeys)); !errors.Is(err, ErrMalformedKey) {
+			t.Fatalf("expected ErrMalformedKey, got %v", err)
+		}
+		if _, err := QueryE(req, WithStrictKeys(), WithMixedKeys(KeepMixedMap)); err != nil {
+			t.Fatalf("expected the mixed key policy to override strict keys, got %v", err)
+		}
+	})
+	t.Run("should resolve large and exponent numbers by the number policy", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?big=99999999999999999999&exp=1e10&int=10000000000&frac=2.5e3&zip=007&huge=1e400", nil)
+		policies := map[NumberPolicy]RequestValue{
+			NumberAsString: {"big": "99999999999999999999", "exp": "1e10", "int": 10000000000, "frac": 2500.0, "zip": "007", "huge": "1e400"},
+			NumberAsFloat:  {"big": 1e20, "exp": 1e10, "int": 10000000000, "frac": 2500.0, "zip": "007", "huge": "1e400"},
+		}
+		for policy, target := range policies {
+			if result := Query(req, WithNumberPolicy(policy)).ToMap(); !reflect.DeepEqual(result, target) {
+				t.Fatalf("policy %d: expected %v, got %v", policy, target, result)
+			}
+		}
+		_, err := QueryE(httptest.NewRequest(http.MethodGet, "/?count=1e10", nil), WithNumberPolicy(RejectNumber))
+		if !errors.Is(err, ErrUnrepresentableNumber) || !IsParseError(err) {
+			t.Fatalf("expected a parse error wrapping ErrUnrepresentableNumber, got %v", err)
+		}
+	})
+	t.Run("should decode json documents with json values", func(t *testing.T) {
+		query := url.Values{
+			"filters": {`{"status":"active","ids":[1,2]}`},
+			"sort":    {`["name"]`},
+			"broken":  {`{"status":`},
+			"page":    {"2"},
+		}
+		req := httptest.NewRequest(http.MethodGet, "/?"+query.Encode(), nil)
+		target := RequestValue{
+			"filters": map[string]interface{}{"status": "active", "ids": []interface{}{1.0, 2.0}},
+			"sort":    []interface{}{"name"},
+			"broken":  `{"status":`,
+			"page":    2,
+		}
+		if result := Query(req, WithJsonValues()).ToMap(); !reflect.DeepEqual(result, target) {
+			t.Fatalf("Failed decoding json values, expected %v, got %v", target, result)
+		}
+		if filters := Query(req).ToMap()["filters"]; filters != query.Get("filters") {
+			t.Fatalf("expected filters to stay a string by default, got %v", filters)
+		}
+	})
+	t.Run("should pass options through Parse", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?zip=75001", nil)
+		result, err := Parse(req, WithoutTypeConversion())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if zip := result.ToMap()["zip"]; zip != "75001" {
+			t.Fatalf("expected zip to stay a string, got %#v", zip)
+		}
+	})
+}
@@ -0,0 +1,61 @@
+package inrequest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(errorResponse{Error: errorText(status, err)})
+}
+
+/*
+Answering a parse or bind failure with a json error and the status matching err
+e.g. 413 for a *RequestTooLargeError, 415 for ErrUnsupportedMediaType or a *FileTypeError, 422 for BindErrors
+and ValidationErrors, 500 for a nil error and 400 for any other error, such as a malformed body:
+
+	req, err := inrequest.FormDataE(r)
+	if err != nil {
+		inrequest.WriteError(w, err)
+		return
+	}
+*/
+func WriteError(w http.ResponseWriter, err error) {
+	writeError(w, errorStatus(err), err)
+}
+
+// errorStatus is the HTTP status answering err, a nil err is a server error.
+func errorStatus(err error) int {
+	var tooLarge *RequestTooLargeError
+	var fileTooLarge *FileTooLargeError
+	var fileType *FileTypeError
+	var invalid ValidationErrors
+	switch {
+	case err == nil:
+		return http.StatusInternalServerError
+	case errors.Is(err, ErrUnsupportedMediaType), errors.As(err, &fileType):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, ErrContentTooLarge), errors.Is(err, ErrDecompressedTooLarge), errors.As(err, &tooLarge), errors.As(err, &fileTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case IsParseError(err):
+		return http.StatusBadRequest
+	case IsBindError(err), errors.As(err, &invalid):
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusBadRequest
+}
+
+// errorText is the message of err, the text of status when err is nil.
+func errorText(status int, err error) string {
+	if err == nil {
+		return http.StatusText(status)
+	}
+	return err.Error()
+}
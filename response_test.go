@@ -0,0 +1,45 @@
+package inrequest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWriteError(t *testing.T) {
+	cases := []struct {
+		err    error
+		status int
+	}{
+		{&ParseError{Err: &RequestTooLargeError{Limit: "content-length", Max: 10}}, http.StatusRequestEntityTooLarge},
+		{&ParseError{Err: &FileTooLargeError{Field: "avatar", Max: 10}}, http.StatusRequestEntityTooLarge},
+		{&ParseError{Err: ErrUnsupportedMediaType}, http.StatusUnsupportedMediaType},
+		{&ParseError{Err: &FileTypeError{Field: "avatar", Filename: "a.exe", Type: "application/x-msdownload"}}, http.StatusUnsupportedMediaType},
+		{&ParseError{Err: errors.New("unexpected EOF")}, http.StatusBadRequest},
+		{BindErrors{{Field: "age", Err: errors.New("not a number")}}, http.StatusUnprocessableEntity},
+		{ValidationErrors{{Field: "email", Tag: "email", Err: errors.New("invalid email")}}, http.StatusUnprocessableEntity},
+		{errors.New("other"), http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		rec := httptest.NewRecorder()
+		WriteError(rec, c.err)
+		if rec.Code != c.status {
+			t.Fatalf("error %v: expected status %d, got %d", c.err, c.status, rec.Code)
+		}
+		var body struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(rec.Body.Bytes(), &body) != nil || body.Error != c.err.Error() {
+			t.Fatalf("error %v: unexpected body %s", c.err, rec.Body.String())
+		}
+	}
+	t.Run("should answer a nil error as a server error", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		WriteError(rec, nil)
+		if rec.Code != http.StatusInternalServerError {
+			t.Fatalf("expected status 500, got %d", rec.Code)
+		}
+	})
+}
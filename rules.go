@@ -0,0 +1,230 @@
+package inrequest
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ruleTags lists the constraint tags checked by the binder, in the order they are checked.
+var ruleTags = []string{"len", "min", "max", "oneof", "enum", "regex"}
+
+// regexes caches the patterns of `regex` tags.
+var regexes sync.Map
+
+// fieldRule is one constraint tag of a struct field, e.g. `min:"3"`.
+type fieldRule struct {
+	tag   string
+	param string
+}
+
+func rulesOf(field reflect.StructField) []fieldRule {
+	var rules []fieldRule
+	for _, tag := range ruleTags {
+		if param, ok := field.Tag.Lookup(tag); ok {
+			rules = append(rules, fieldRule{tag: tag, param: param})
+		}
+	}
+	return rules
+}
+
+/*
+Checking a bound value against the rule
+e.g. `min:"3"` requires at least 3 for numbers and at least 3 characters or items
+for strings, slices and maps, `oneof:"red green"` requires one of the space separated values
+and `enum:"draft|published"` one of the pipe separated values for the value or every item of a slice
+*/
+func (rule fieldRule) check(value reflect.Value) error {
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	switch rule.tag {
+	case "len", "min", "max":
+		limit, err := strconv.ParseFloat(rule.param, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s tag %q", rule.tag, rule.param)
+		}
+		size, unit, ok := ruleSize(value)
+		if !ok {
+			return fmt.Errorf("%s tag is not supported on %s", rule.tag, value.Type())
+		}
+		switch {
+		case rule.tag == "len" && size != limit:
+			return fmt.Errorf("must have %s %s", rule.param, unit)
+		case rule.tag == "min" && size < limit:
+			if unit == "" {
+				return fmt.Errorf("must be at least %s", rule.param)
+			}
+			return fmt.Errorf("must have at least %s %s", rule.param, unit)
+		case rule.tag == "max" && size > limit:
+			if unit == "" {
+				return fmt.Errorf("must be at most %s", rule.param)
+			}
+			return fmt.Errorf("must have at most %s %s", rule.param, unit)
+		}
+	case "oneof":
+		text := fmt.Sprint(value.Interface())
+		for _, option := range strings.Fields(rule.param) {
+			if text == option {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of %s", strings.Join(strings.Fields(rule.param), ", "))
+	case "enum":
+		return checkEnum(value, strings.Split(rule.param, "|"))
+	case "regex":
+		pattern, err := compileRegex(rule.param)
+		if err != nil {
+			return fmt.Errorf("invalid regex tag %q", rule.param)
+		}
+		if !pattern.MatchString(fmt.Sprint(value.Interface())) {
+			return fmt.Errorf("must match %s", rule.param)
+		}
+	}
+	return nil
+}
+
+// checkEnum reports the value, or the first item of a slice, missing from allowed along with the allowed values.
+func checkEnum(value reflect.Value, allowed []string) error {
+	if value.Kind() == reflect.Slice || value.Kind() == reflect.Array {
+		for i := 0; i < value.Len(); i++ {
+			if err := checkEnum(value.Index(i), allowed); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	text := fmt.Sprint(value.Interface())
+	for _, option := range allowed {
+		if text == option {
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of %s, got %q", strings.Join(allowed, ", "), text)
+}
+
+// ruleSize measures value for len, min and max: numbers by value,
+// strings by characters and collections by items.
+func ruleSize(value reflect.Value) (float64, string, bool) {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), "", true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), "", true
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), "", true
+	case reflect.String:
+		return float64(utf8.RuneCountInString(value.String())), "characters", true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(value.Len()), "items", true
+	}
+	return 0, "", false
+}
+
+func compileRegex(pattern string) (*regexp.Regexp, error) {
+	if compiled, ok := regexes.Load(pattern); ok {
+		return compiled.(*regexp.Regexp), nil
+	}
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	regexes.Store(pattern, compiled)
+	return compiled, nil
+}
+
+// isRequired reports whether the field is tagged `required:"true"`.
+func isRequired(field reflect.StructField) bool {
+	required, _ := strconv.ParseBool(field.Tag.Get("required"))
+	return required
+}
+
+func requiredError(path string) FieldError {
+	return FieldError{Field: path, Tag: "required", Err: errors.New("is required")}
+}
+
+// checkRules records a FieldError for every rule of the field the bound value breaks.
+func (b *binder) checkRules(rules []fieldRule, value reflect.Value, path string) {
+	for _, rule := range rules {
+		if err := rule.check(value); err != nil {
+			b.errs = append(b.errs, FieldError{Field: path, Tag: rule.tag, Param: rule.param, Err: err})
+		}
+	}
+}
+
+/*
+Checking the rules of a model bound by another decoder, e.g. encoding/json,
+only fields present in values are checked like the binder does
+*/
+func (b *binder) check(values RequestValue, model interface{}) error {
+	dst := reflect.ValueOf(model)
+	if dst.Kind() != reflect.Ptr || dst.IsNil() {
+		return nil
+	}
+	b.checkValue(dst.Elem(), values, "")
+	return b.result(nil)
+}
+
+func (b *binder) checkValue(dst reflect.Value, src interface{}, path string) {
+	for dst.Kind() == reflect.Ptr {
+		if dst.IsNil() {
+			return
+		}
+		dst = dst.Elem()
+	}
+	switch dst.Kind() {
+	case reflect.Struct:
+		values, ok := src.(RequestValue)
+		if !ok {
+			return
+		}
+		b.checkFields(dst, values, path)
+		if b.strict {
+			b.checkUnknown(dst.Type(), values, path)
+		}
+	case reflect.Slice, reflect.Array:
+		items, ok := src.([]interface{})
+		if !ok {
+			return
+		}
+		for i := 0; i < dst.Len() && i < len(items); i++ {
+			b.checkValue(dst.Index(i), items[i], joinPath(path, strconv.Itoa(i)))
+		}
+	}
+}
+
+func (b *binder) checkFields(dst reflect.Value, values RequestValue, path string) {
+	for _, field := range b.plan(dst.Type()) {
+		target := dst.Field(field.index)
+		switch {
+		case field.prefix != "":
+			b.checkValue(target, valuesWithPrefix(values, field.prefix), joinPath(path, field.name))
+		case field.embedded:
+			if target.Kind() == reflect.Ptr {
+				if target.IsNil() {
+					continue
+				}
+				target = target.Elem()
+			}
+			b.checkFields(target, values, path)
+		default:
+			value, ok := lookupKey(values, field.name)
+			if !ok {
+				if field.required {
+					b.errs = append(b.errs, requiredError(joinPath(path, field.name)))
+				}
+				continue
+			}
+			b.checkRules(field.rules, target, joinPath(path, field.name))
+			b.checkValue(target, value, joinPath(path, field.name))
+		}
+	}
+}
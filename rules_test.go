@@ -0,0 +1,85 @@
+package inrequest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBindRules(t *testing.T) {
+	type Address struct {
+		Zip string `json:"zip" len:"5"`
+	}
+	type Signup struct {
+		Username string   `json:"username" min:"3" max:"12" regex:"^[a-z0-9_]+$"`
+		Age      int      `json:"age" min:"18" max:"130"`
+		Plan     string   `json:"plan" oneof:"free pro"`
+		Tags     []string `json:"tags" max:"2"`
+		Address  Address  `json:"address"`
+		Nickname *string  `json:"nickname" min:"2"`
+	}
+
+	t.Run("should accept values within their constraints", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?username=john_doe&age=31&plan=pro&tags=a&tags=b&address[zip]=75001", nil)
+		signup := Signup{}
+		if err := Query(req).ToBind(&signup); err != nil {
+			t.Fatal(err)
+		}
+	})
+	t.Run("should report every broken constraint", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?username=Jo&age=12&plan=gold&tags=a&tags=b&tags=c&address[zip]=123&nickname=x", nil)
+		err := Query(req).ToBind(&Signup{})
+		var errs BindErrors
+		if !errors.As(err, &errs) {
+			t.Fatalf("expected bind errors, got %v", err)
+		}
+		var got []string
+		for _, field := range errs.Fields() {
+			got = append(got, field.Field+":"+field.Tag)
+		}
+		target := []string{"username:min", "username:regex", "age:min", "plan:oneof", "tags:max", "address.zip:len", "nickname:min"}
+		if !reflect.DeepEqual(got, target) {
+			t.Fatalf("Failed checking constraints, expected %v, got %v", target, got)
+		}
+	})
+	t.Run("should name the field and the allowed values of enums", func(t *testing.T) {
+		var post struct {
+			Status string   `json:"status" enum:"draft|published|archived"`
+			Labels []string `json:"labels" enum:"news|blog"`
+		}
+		req := httptest.NewRequest(http.MethodGet, "/?status=archive&labels=news&labels=ads", nil)
+		err := Query(req).ToBind(&post)
+		var errs BindErrors
+		if !errors.As(err, &errs) || len(errs) != 2 {
+			t.Fatalf("expected two enum errors, got %v", err)
+		}
+		if errs[0].Field != "status" || errs[0].Tag != "enum" || errs[0].Err.Error() != `must be one of draft, published, archived, got "archive"` {
+			t.Fatalf("unexpected status error %+v", errs[0])
+		}
+		if errs[1].Field != "labels" || errs[1].Err.Error() != `must be one of news, blog, got "ads"` {
+			t.Fatalf("unexpected labels error %+v", errs[1])
+		}
+	})
+	t.Run("should only check fields present in the request", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?plan=free", nil)
+		if err := Query(req).ToBind(&Signup{}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	t.Run("should check json bodies", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"username":"john","age":7}`))
+		req.Header.Set("Content-Type", "application/json")
+		result, err := Json(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = result.ToBind(&Signup{})
+		var errs BindErrors
+		if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Field != "age" || errs[0].Param != "18" {
+			t.Fatalf("expected a min error on age, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package inrequest
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+)
+
+/*
+Expanding form values that did not arrive with an *http.Request, e.g. read from a message queue
+e.g. url.Values{"user[name]": {"John"}, "tags[0]": {"a"}} gives {"user": {"name": "John"}, "tags": ["a"]}.
+Values over the key or array index limits are reported as a *ParseError like QueryE does
+*/
+func FromValues(values url.Values, opts ...Option) (FormRequest, error) {
+	options := newOptions(opts)
+	result, err := expandValues(values, options)
+	return FormRequest{requestValues: valuesOf(result, options), options: options}, err
+}
+
+// FromMap wraps already decoded values, e.g. a json message payload, binding them like a json body.
+// The map is used as is, not copied.
+func FromMap(values map[string]interface{}, opts ...Option) JsonRequest {
+	options := newOptions(opts)
+	if values == nil {
+		values = make(RequestValue)
+	}
+	return JsonRequest{requestValues: valuesOf(values, options), options: options}
+}
+
+/*
+Parsing a body read from r as Parse does for a request with that Content-Type
+e.g. FromReader(msg.Body, "application/json") or FromReader(file, "multipart/form-data; boundary=xyz")
+*/
+func FromReader(r io.Reader, contentType string, opts ...Option) (Request, error) {
+	req, err := http.NewRequest(http.MethodPost, "/", r)
+	if err != nil {
+		return nil, &ParseError{Err: err}
+	}
+	if req.ContentLength == 0 && req.Body != nil && req.Body != http.NoBody {
+		req.ContentLength = -1
+	}
+	req.Header.Set("Content-Type", contentType)
+	return Parse(req, opts...)
+}
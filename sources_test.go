@@ -0,0 +1,57 @@
+package inrequest
+
+import (
+	"errors"
+	"io"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSources(t *testing.T) {
+	type User struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+	}
+
+	t.Run("should expand bracket keys of url values", func(t *testing.T) {
+		req, err := FromValues(url.Values{"user[name]": {"John"}, "user[tags][0]": {"a"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := RequestValue{"user": RequestValue{"name": "John", "tags": []interface{}{"a"}}}
+		if !reflect.DeepEqual(req.ToMap(), expected) {
+			t.Fatalf("expected %v, got %v", expected, req.ToMap())
+		}
+	})
+	t.Run("should report url values over the key limit", func(t *testing.T) {
+		_, err := FromValues(url.Values{"a": {"1"}, "b": {"2"}}, WithMaxKeys(1))
+		var tooLarge *RequestTooLargeError
+		if !errors.As(err, &tooLarge) {
+			t.Fatalf("expected a *RequestTooLargeError, got %v", err)
+		}
+	})
+	t.Run("should bind a decoded map", func(t *testing.T) {
+		user := User{}
+		if err := FromMap(map[string]interface{}{"name": "John", "tags": []interface{}{"a"}}).ToBind(&user); err != nil {
+			t.Fatal(err)
+		}
+		if user.Name != "John" || len(user.Tags) != 1 {
+			t.Fatalf("unexpected user %v", user)
+		}
+	})
+	t.Run("should parse a reader by its content type", func(t *testing.T) {
+		body := io.MultiReader(strings.NewReader(`{"name":`), strings.NewReader(`"John"}`))
+		req, err := FromReader(body, "application/json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if req.GetString("name") != "John" {
+			t.Fatalf("expected John, got %v", req.ToMap())
+		}
+		if _, err := FromReader(strings.NewReader("name"), "text/plain"); !errors.Is(err, ErrUnsupportedMediaType) {
+			t.Fatalf("expected ErrUnsupportedMediaType, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,68 @@
+package inrequest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ValueTransformer rewrites a parsed value, e.g. lowercasing an email. An error fails parsing with a *ParseError.
+type ValueTransformer func(value interface{}) (interface{}, error)
+
+// transformPath is the path transformers are keyed by, e.g. "users[*].email" and "users[0][email]" become "users.email".
+func transformPath(path string) string {
+	segments := strings.Split(fieldPath(path), ".")
+	kept := segments[:0]
+	for _, segment := range segments {
+		if segment != "*" {
+			kept = append(kept, segment)
+		}
+	}
+	return strings.Join(kept, ".")
+}
+
+// transform runs the transformers of options, which may be nil, over the parsed values in place.
+func (o *Options) transform(values RequestValue) error {
+	if o == nil || len(o.Transformers) == 0 {
+		return nil
+	}
+	_, err := transformValue(values, "", o.Transformers)
+	return err
+}
+
+/*
+Running the transformers registered for the dot path of value
+e.g. with a transformer for "users.email" the value at users.0.email is replaced by its result.
+Slice items are transformed one by one, maps without a transformer of their own are walked into
+*/
+func transformValue(value interface{}, path string, transformers map[string][]ValueTransformer) (interface{}, error) {
+	if items, ok := value.([]interface{}); ok {
+		for i, item := range items {
+			transformed, err := transformValue(item, path+"."+strconv.Itoa(i), transformers)
+			if err != nil {
+				return value, err
+			}
+			items[i] = transformed
+		}
+		return items, nil
+	}
+	if chain, ok := transformers[withoutIndexes(path)]; ok {
+		for _, fn := range chain {
+			var err error
+			if value, err = fn(value); err != nil {
+				return value, fmt.Errorf("transform %s: %w", strings.TrimPrefix(path, "."), err)
+			}
+		}
+		return value, nil
+	}
+	if object, ok := value.(RequestValue); ok {
+		for key, child := range object {
+			transformed, err := transformValue(child, path+"."+key, transformers)
+			if err != nil {
+				return value, err
+			}
+			object[key] = transformed
+		}
+	}
+	return value, nil
+}
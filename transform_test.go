@@ -0,0 +1,68 @@
+package inrequest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTransformer(t *testing.T) {
+	lowercase := func(value interface{}) (interface{}, error) {
+		if s, ok := value.(string); ok {
+			return strings.ToLower(s), nil
+		}
+		return value, nil
+	}
+	digits := func(value interface{}) (interface{}, error) {
+		s, ok := value.(string)
+		if !ok {
+			return value, nil
+		}
+		return strings.Map(func(r rune) rune {
+			if r < '0' || r > '9' {
+				return -1
+			}
+			return r
+		}, s), nil
+	}
+
+	t.Run("should transform the values at a wildcard path", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?users[0][email]=A@X.IO&users[1][email]=B@X.IO&email=C@X.IO", nil)
+		result := Query(req, WithTransformer("users[*].email", lowercase)).ToMap()
+		users := result["users"].([]interface{})
+		if users[0].(RequestValue)["email"] != "a@x.io" || users[1].(RequestValue)["email"] != "b@x.io" {
+			t.Fatalf("expected lowercased user emails, got %v", users)
+		}
+		if result["email"] != "C@X.IO" {
+			t.Fatalf("expected other paths to be kept, got %v", result["email"])
+		}
+	})
+	t.Run("should chain the transformers of a path on json bodies", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"contact": {"phone": "(555) 123-4567"}, "tags": ["A", "B"]}`))
+		req.Header.Set("Content-Type", "application/json")
+		prefix := func(value interface{}) (interface{}, error) {
+			return "+1" + value.(string), nil
+		}
+		request, err := Json(req, WithTransformer("contact.phone", digits), WithTransformer("contact[phone]", prefix), WithTransformer("tags", lowercase))
+		if err != nil {
+			t.Fatal(err)
+		}
+		result := request.ToMap()
+		if phone := result["contact"].(RequestValue)["phone"]; phone != "+15551234567" {
+			t.Fatalf("expected the transformers to run in order, got %v", phone)
+		}
+		if tags := result["tags"].([]interface{}); tags[0] != "a" || tags[1] != "b" {
+			t.Fatalf("expected every tag to be transformed, got %v", tags)
+		}
+	})
+	t.Run("should fail parsing on a transformer error", func(t *testing.T) {
+		invalid := errors.New("not an email")
+		req := httptest.NewRequest(http.MethodGet, "/?email=nope", nil)
+		_, err := QueryE(req, WithTransformer("email", func(interface{}) (interface{}, error) { return nil, invalid }))
+		if !IsParseError(err) || !errors.Is(err, invalid) || !strings.Contains(err.Error(), "email") {
+			t.Fatalf("expected a parse error wrapping the transformer error, got %v", err)
+		}
+	})
+}
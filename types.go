@@ -1,5 +1,14 @@
 package inrequest
 
+import (
+	"io"
+	"mime/multipart"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
 type RequestValue = map[string]interface{}
 
 type (
@@ -9,3 +18,107 @@ type (
 		Value interface{}
 	}
 )
+
+// Request is implemented by every parsed request type.
+type Request interface {
+	// ToMap returns the parsed values. The map is shared with the request, changes to it
+	// show in later calls such as ToBind, use ToMapCopy for a map safe to modify.
+	ToMap() RequestValue
+	ToMapCopy() RequestValue
+	ToOrderedMap() *OrderedMap
+	Get(path string) (interface{}, bool)
+	Has(path string) bool
+	IsEmpty() bool
+	Keys() []string
+	Only(paths ...string) RequestValue
+	Except(paths ...string) RequestValue
+	Filter(keep func(key string, value interface{}) bool) RequestValue
+	Flatten() RequestValue
+	Dump(w io.Writer, redact ...string) error
+	DebugString(redact ...string) string
+	ToURLValues() url.Values
+	ToMultipart(w *multipart.Writer) error
+	GetString(path string, def ...string) string
+	GetInt(path string, def ...int) int
+	GetInt64(path string, def ...int64) int64
+	GetFloat(path string, def ...float64) float64
+	GetBool(path string, def ...bool) bool
+	GetTime(path string, def ...time.Time) time.Time
+	GetStringSlice(path string, def ...string) []string
+	ToBind(model interface{}) error
+	ToJsonByte() ([]byte, error)
+	ToJsonString() (string, error)
+}
+
+// DuplicatePolicy decides which values of a repeated plain key such as "sort" are kept, see WithDuplicateKeys.
+type DuplicatePolicy int
+
+const (
+	// Combine keeps every value as an array, "sort=a&sort=b" gives {"sort": ["a", "b"]}.
+	Combine DuplicatePolicy = iota
+	// FirstWins keeps the first value, "sort=a&sort=b" gives {"sort": "a"}.
+	FirstWins
+	// LastWins keeps the last value, "sort=a&sort=b" gives {"sort": "b"}.
+	LastWins
+)
+
+// MixedKeyPolicy decides what a key holding both indexes and names such as items[0] and items[total] gives,
+// see WithMixedKeys.
+type MixedKeyPolicy int
+
+const (
+	// DropNamedKeys promotes the key to an array of its indexed values, dropping the named ones.
+	DropNamedKeys MixedKeyPolicy = iota
+	// KeepMixedMap keeps a map, {"items": {"0": "a", "total": 3}}.
+	KeepMixedMap
+	// RejectMixedKeys fails parsing with ErrMalformedKey.
+	RejectMixedKeys
+)
+
+// NumberPolicy decides what an integer past the int64 range or an exponent without fraction such as "1e10"
+// gives, see WithNumberPolicy. Values with a fraction such as "2.5e3" always become float64.
+type NumberPolicy int
+
+const (
+	// NumberAsString keeps the value as a string.
+	NumberAsString NumberPolicy = iota
+	// NumberAsFloat parses the value into a float64, "1e10" gives 1e+10.
+	NumberAsFloat
+	// RejectNumber fails parsing with ErrUnrepresentableNumber.
+	RejectNumber
+)
+
+// FieldType is the type a form or query value is converted into, see WithFieldType.
+type FieldType int
+
+const (
+	// Auto converts the value like any other, e.g. "12" into 12.
+	Auto FieldType = iota
+	String
+	Int
+	Float
+	Bool
+)
+
+// convert turns value into the field type, a value that does not parse stays a string.
+func (t FieldType) convert(value string) interface{} {
+	switch t {
+	case String:
+		return value
+	case Int:
+		if n, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+			return n
+		}
+	case Float:
+		if f, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+			return f
+		}
+	case Bool:
+		if b, err := strconv.ParseBool(strings.TrimSpace(value)); err == nil {
+			return b
+		}
+	default:
+		return actualTypeOf(value)
+	}
+	return value
+}
@@ -0,0 +1,140 @@
+package inrequest
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// union is an interface type bound into the variant named by its discriminator key.
+type union struct {
+	discriminator string
+	variants      map[string]reflect.Type
+}
+
+var (
+	unionsMu sync.RWMutex
+	unions   = map[reflect.Type]union{}
+	// binderTypes caches which model types need the binder, see needsBinder. RegisterUnion resets it.
+	binderTypes = map[reflect.Type]bool{}
+)
+
+/*
+RegisterUnion makes ToBind fill fields of the interface type T with the variant named by the discriminator key
+e.g. for payment methods sent as {"type": "card", "number": "4242..."} or {"type": "bank", "iban": "FR76..."}:
+
+	inrequest.RegisterUnion[PaymentMethod]("type", map[string]reflect.Type{
+		"card": reflect.TypeOf(Card{}),
+		"bank": reflect.TypeOf(Bank{}),
+	})
+
+The variant must implement T, or else a pointer to it, which is then stored. An unknown or missing discriminator fails the field with ErrUnknownVariant
+*/
+func RegisterUnion[T any](discriminator string, variants map[string]reflect.Type) {
+	copied := make(map[string]reflect.Type, len(variants))
+	for name, variant := range variants {
+		copied[name] = variant
+	}
+	unionsMu.Lock()
+	defer unionsMu.Unlock()
+	unions[reflect.TypeOf((*T)(nil)).Elem()] = union{discriminator: discriminator, variants: copied}
+	binderTypes = map[reflect.Type]bool{}
+}
+
+func unionFor(t reflect.Type) (union, bool) {
+	if t.Kind() != reflect.Interface {
+		return union{}, false
+	}
+	unionsMu.RLock()
+	defer unionsMu.RUnlock()
+	u, ok := unions[t]
+	return u, ok
+}
+
+/*
+Binding values into a new variant of the union held by dst
+e.g. {"type": "bank", "iban": "FR76..."} fills a Bank and stores it in a PaymentMethod field, as a *Bank when only the pointer implements it
+*/
+func (b *binder) bindUnion(dst reflect.Value, src interface{}, path string, u union) error {
+	values, ok := src.(RequestValue)
+	if !ok {
+		return typeError(dst, src, path)
+	}
+	raw, _ := lookupKey(values, u.discriminator)
+	name, _ := scalarString(raw)
+	variant, ok := u.variants[name]
+	if !ok {
+		return &BindError{Field: joinPath(path, u.discriminator), Err: fmt.Errorf("%w %q", ErrUnknownVariant, name)}
+	}
+	target := reflect.New(variant)
+	if !target.Type().Implements(dst.Type()) && !variant.Implements(dst.Type()) {
+		return typeError(dst, src, path)
+	}
+	if variant.Kind() == reflect.Struct && !b.knownKey(variant, u.discriminator) {
+		values = withoutKey(values, u.discriminator)
+	}
+	if err := b.bindValue(target.Elem(), values, path); err != nil {
+		return err
+	}
+	if variant.Implements(dst.Type()) {
+		dst.Set(target.Elem())
+	} else {
+		dst.Set(target)
+	}
+	return nil
+}
+
+// withoutKey copies values without key, so a variant holding no discriminator field does not report it as unknown.
+func withoutKey(values RequestValue, key string) RequestValue {
+	copied := make(RequestValue, len(values))
+	for k, value := range values {
+		if k != key {
+			copied[k] = value
+		}
+	}
+	return copied
+}
+
+// needsBinder reports whether binding into t may fill a registered union or a field tagged tz,
+// which encoding/json cannot decode.
+func needsBinder(t reflect.Type) bool {
+	if t == nil {
+		return false
+	}
+	unionsMu.RLock()
+	needs, cached := binderTypes[t]
+	unionsMu.RUnlock()
+	if cached {
+		return needs
+	}
+	needs = typeNeedsBinder(t, map[reflect.Type]bool{})
+	unionsMu.Lock()
+	binderTypes[t] = needs
+	unionsMu.Unlock()
+	return needs
+}
+
+func typeNeedsBinder(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	switch t.Kind() {
+	case reflect.Interface:
+		_, ok := unionFor(t)
+		return ok
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return typeNeedsBinder(t.Elem(), seen)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" && !field.Anonymous {
+				continue
+			}
+			if field.Tag.Get("tz") != "" || typeNeedsBinder(field.Type, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
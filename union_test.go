@@ -0,0 +1,75 @@
+package inrequest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testPayment interface {
+	method() string
+}
+
+type testCard struct {
+	Number string `json:"number"`
+}
+
+func (testCard) method() string { return "card" }
+
+type testBank struct {
+	Type string `json:"type"`
+	Iban string `json:"iban"`
+}
+
+func (*testBank) method() string { return "bank" }
+
+func TestBindUnion(t *testing.T) {
+	RegisterUnion[testPayment]("type", map[string]reflect.Type{
+		"card": reflect.TypeOf(testCard{}),
+		"bank": reflect.TypeOf(testBank{}),
+	})
+	type Checkout struct {
+		Payment  testPayment   `json:"payment"`
+		Fallback []testPayment `json:"fallback"`
+	}
+
+	t.Run("should bind the variant named by the discriminator", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?payment[type]=card&payment[number]=4242&fallback[0][type]=bank&fallback[0][iban]=FR76", nil)
+		checkout := Checkout{}
+		if err := Query(req, WithDisallowUnknownFields()).ToBind(&checkout); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(checkout.Payment, testCard{Number: "4242"}) {
+			t.Fatalf("expected a card, got %#v", checkout.Payment)
+		}
+		if len(checkout.Fallback) != 1 || !reflect.DeepEqual(checkout.Fallback[0], &testBank{Type: "bank", Iban: "FR76"}) {
+			t.Fatalf("expected a bank pointer, got %#v", checkout.Fallback)
+		}
+	})
+	t.Run("should bind unions of json bodies", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"payment": {"type": "bank", "iban": "DE89"}}`))
+		req.Header.Set("Content-Type", "application/json")
+		result, err := Json(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkout := Checkout{}
+		if err = result.ToBind(&checkout); err != nil {
+			t.Fatal(err)
+		}
+		if checkout.Payment == nil || checkout.Payment.method() != "bank" {
+			t.Fatalf("expected a bank, got %#v", checkout.Payment)
+		}
+	})
+	t.Run("should report an unknown variant", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?payment[type]=cash", nil)
+		err := Query(req).ToBind(&Checkout{})
+		var errs BindErrors
+		if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Field != "payment.type" || !errors.Is(errs[0].Err, ErrUnknownVariant) {
+			t.Fatalf("expected an unknown variant error on payment.type, got %v", err)
+		}
+	})
+}
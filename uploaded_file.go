@@ -0,0 +1,152 @@
+package inrequest
+
+import (
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"io/fs"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// UploadedFile is a file uploaded in a multipart form. ToBind fills fields of type UploadedFile,
+// *UploadedFile or []UploadedFile from the files of a form, e.g.
+//
+//	type Profile struct {
+//		Avatar inrequest.UploadedFile   `form:"avatar"`
+//		Photos []inrequest.UploadedFile `form:"photos"`
+//	}
+type UploadedFile struct {
+	*multipart.FileHeader
+}
+
+var (
+	uploadedFileType = reflect.TypeOf(UploadedFile{})
+	bytesType        = reflect.TypeOf([]byte(nil))
+	fileType         = reflect.TypeOf((*multipart.File)(nil)).Elem()
+)
+
+// storedFiles maps the uploads stored in the directory of SetTempDir or WithTempDir to their file,
+// a multipart.FileHeader only opens the files ReadForm stored in os.TempDir.
+var storedFiles sync.Map
+
+// Open opens the content, from memory, from the temporary file of ReadForm or from the configured temp directory.
+// Uploads stored in a temp directory set with SetTempDir or WithTempDir are opened through UploadedFile only.
+func (f UploadedFile) Open() (multipart.File, error) {
+	return openFile(f.FileHeader)
+}
+
+// openFile opens an upload wherever it is stored.
+func openFile(header *multipart.FileHeader) (multipart.File, error) {
+	if name, ok := storedFiles.Load(header); ok {
+		return os.Open(name.(string))
+	}
+	return header.Open()
+}
+
+// removeForm deletes the temporary files of form, those of ReadForm and those stored in a temp directory.
+func removeForm(form *multipart.Form) error {
+	var firstErr error
+	for _, headers := range form.File {
+		for _, header := range headers {
+			name, ok := storedFiles.LoadAndDelete(header)
+			if !ok {
+				continue
+			}
+			if err := os.Remove(name.(string)); err != nil && !errors.Is(err, fs.ErrNotExist) && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	if err := form.RemoveAll(); err != nil && firstErr == nil {
+		firstErr = err
+	}
+	return firstErr
+}
+
+// Ext is the lower case extension of the filename with its dot, e.g. ".pdf".
+func (f UploadedFile) Ext() string {
+	return strings.ToLower(filepath.Ext(f.Filename))
+}
+
+// DetectedContentType sniffs the content type from the first 512 bytes with http.DetectContentType,
+// unlike the Content-Type header it is not chosen by the client.
+func (f UploadedFile) DetectedContentType() (string, error) {
+	file, err := f.Open()
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	return http.DetectContentType(head[:n]), nil
+}
+
+// Checksum hashes the content with the hash newHash returns and encodes the sum in hex,
+// e.g. Checksum(sha256.New).
+func (f UploadedFile) Checksum(newHash func() hash.Hash) (string, error) {
+	file, err := f.Open()
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	h := newHash()
+	if _, err = io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Bytes reads the whole content.
+func (f UploadedFile) Bytes() ([]byte, error) {
+	file, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+// Save writes the content to path.
+func (f UploadedFile) Save(path string) error {
+	return saveFile(f.FileHeader, path)
+}
+
+/*
+Binding an uploaded file into the fields taking files besides *multipart.FileHeader
+e.g. an UploadedFile field, a []byte field receiving the content, or an io.ReadCloser
+field receiving the opened file, which the caller closes
+*/
+func (b *binder) bindFile(dst reflect.Value, header *multipart.FileHeader, path string) (bool, error) {
+	switch {
+	case dst.Type() == uploadedFileType:
+		dst.Set(reflect.ValueOf(UploadedFile{FileHeader: header}))
+	case dst.Type() == bytesType:
+		if b.maxFileBytes > 0 && header.Size > b.maxFileBytes {
+			return true, &BindError{Field: path, Err: &FileTooLargeError{Field: path, Size: header.Size, Max: b.maxFileBytes}}
+		}
+		content, err := UploadedFile{FileHeader: header}.Bytes()
+		if err != nil {
+			return true, &BindError{Field: path, Err: err}
+		}
+		dst.SetBytes(content)
+	case dst.Kind() == reflect.Interface && fileType.Implements(dst.Type()):
+		file, err := openFile(header)
+		if err != nil {
+			return true, &BindError{Field: path, Err: err}
+		}
+		dst.Set(reflect.ValueOf(file))
+	default:
+		return false, nil
+	}
+	return true, nil
+}
@@ -0,0 +1,95 @@
+package inrequest
+
+import (
+	"crypto/sha256"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUploadedFile(t *testing.T) {
+	type Profile struct {
+		Name   string         `form:"name"`
+		Avatar UploadedFile   `form:"avatar"`
+		Resume *UploadedFile  `form:"resume"`
+		Photos []UploadedFile `form:"photos"`
+	}
+	png := "\x89PNG\r\n\x1a\n" + "pixels"
+	req := newMultipartRequest(t, map[string]string{"name": "John"}, map[string]string{
+		"avatar":    png,
+		"resume":    "resume content",
+		"photos[0]": "first",
+		"photos[1]": "second",
+	})
+	var profile Profile
+	if err := FormData(req).ToBind(&profile); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should bind uploaded files", func(t *testing.T) {
+		if profile.Avatar.FileHeader == nil || profile.Resume == nil || len(profile.Photos) != 2 {
+			t.Fatalf("expected every upload to be bound, got %+v", profile)
+		}
+		if profile.Avatar.Size != int64(len(png)) || profile.Resume.Ext() != ".txt" {
+			t.Fatalf("unexpected metadata %+v %+v", profile.Avatar, profile.Resume)
+		}
+	})
+	t.Run("should sniff the content type", func(t *testing.T) {
+		contentType, err := profile.Avatar.DetectedContentType()
+		if err != nil || contentType != "image/png" {
+			t.Fatalf("expected image/png, got %q %v", contentType, err)
+		}
+	})
+	t.Run("should hash and read the content", func(t *testing.T) {
+		sum, err := profile.Photos[1].Checksum(sha256.New)
+		if err != nil || sum != "16367aacb67a4a017c8da8ab95682ccb390863780f7114dda0a0e0c55644c7c4" {
+			t.Fatalf("unexpected checksum %q %v", sum, err)
+		}
+		if content, err := profile.Photos[0].Bytes(); err != nil || string(content) != "first" {
+			t.Fatalf("expected first, got %q %v", content, err)
+		}
+	})
+	t.Run("should save the content", func(t *testing.T) {
+		dst := filepath.Join(t.TempDir(), "resume.txt")
+		if err := profile.Resume.Save(dst); err != nil {
+			t.Fatal(err)
+		}
+		if content, _ := os.ReadFile(dst); string(content) != "resume content" {
+			t.Fatalf("expected saved resume, got %q", content)
+		}
+	})
+}
+
+func TestBindFileContent(t *testing.T) {
+	type Import struct {
+		Avatar []byte        `form:"avatar"`
+		Rows   io.ReadCloser `form:"rows"`
+	}
+	files := map[string]string{"avatar": "avatar content", "rows": "id,name\n1,John\n"}
+
+	t.Run("should bind file content into bytes and readers", func(t *testing.T) {
+		var model Import
+		if err := FormData(newMultipartRequest(t, nil, files)).ToBind(&model); err != nil {
+			t.Fatal(err)
+		}
+		defer model.Rows.Close()
+		rows, _ := io.ReadAll(model.Rows)
+		if string(model.Avatar) != files["avatar"] || string(rows) != files["rows"] {
+			t.Fatalf("unexpected content %q %q", model.Avatar, rows)
+		}
+	})
+	t.Run("should report files over the bind size cap", func(t *testing.T) {
+		var model Import
+		err := FormData(newMultipartRequest(t, nil, files), WithMaxBindFileSize(4)).ToBind(&model)
+
+		var tooLarge *FileTooLargeError
+		if !IsBindError(err) || !errors.As(err, &tooLarge) || tooLarge.Field != "avatar" {
+			t.Fatalf("expected file too large bind error, got %v", err)
+		}
+		if model.Rows != nil {
+			model.Rows.Close()
+		}
+	})
+}
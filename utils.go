@@ -1,6 +1,10 @@
 package inrequest
 
 import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"mime/multipart"
 	"reflect"
 	"sort"
 	"strconv"
@@ -8,82 +12,453 @@ import (
 )
 
 /*
-transforming key value path into map
-e.g. "path.to.this" : "value"
-transform into :
-
-	["path"] : {
-		["to"] : {
-			["this"] : "value"
-		}
-	}
+Fixing value to the actual value type
+e.g. RequestValue with key of numbers are transformed into slice of interface / []interface{},
+a map whose largest index exceeds the configured maximum is kept as a map and reported
 */
-func transformDotPathToMap(target *RequestValue, dotPath string, value interface{}) {
-	paths := strings.Split(dotPath, ".")
-	t := *target
-	if len(paths) == 1 {
-		t[dotPath] = value
-		return
-	}
-	if _, ok := t[paths[0]]; !ok {
-		t[paths[0]] = make(RequestValue)
-	}
-	delete(t, dotPath)
-	if query, ok := t[paths[0]].(RequestValue); ok {
-		transformDotPathToMap(&query, strings.Join(paths[1:], "."), value)
-	}
+func fixValueToActualType(target *RequestValue, options *Options) error {
+	return fixValuesAt(target, "", options)
 }
 
-/*
-Fixing value to the actual value type
-e.g. RequestValue with key of numbers are transformed into slice of interface / []interface{}
-*/
-func fixValueToActualType(target *RequestValue) {
+func fixValuesAt(target *RequestValue, path string, options *Options) error {
+	var firstErr error
 	t := *target
 	for keyT, v := range t {
-		if reflect.TypeOf(v).Kind() == reflect.Map {
+		if v != nil && reflect.TypeOf(v).Kind() == reflect.Map {
 			if vMap, ok := v.(RequestValue); ok {
-				fixValueToActualType(&vMap)
-				var arrMap []interface{}
+				if err := fixValuesAt(&vMap, joinPath(path, keyT), options); err != nil && firstErr == nil {
+					firstErr = err
+				}
 				keys := make([]int, 0, len(vMap))
 				for key := range vMap {
 					if intKey, err := strconv.Atoi(key); err == nil {
 						keys = append(keys, intKey)
 					}
 				}
-				sort.Ints(keys)
-				for _, key := range keys {
-					arrMap = append(arrMap, vMap[strconv.Itoa(key)])
+				if len(keys) == 0 {
+					continue
 				}
-				if len(arrMap) > 0 {
-					t[keyT] = arrMap
-				}
-			}
-		} else {
-			if value, ok := v.(string); ok {
-				if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
-					if strings.Contains(value, ".") {
-						t[keyT] = floatValue
-					} else {
-						if intValue, err := strconv.Atoi(value); err == nil {
-							if len([]rune(value)) > 0 && string(value[0]) == "0" {
-								continue
-							}
-							t[keyT] = intValue
+				if len(keys) != len(vMap) {
+					switch options.mixedKeys() {
+					case KeepMixedMap:
+						continue
+					case RejectMixedKeys:
+						if firstErr == nil {
+							firstErr = fmt.Errorf("%w %q: mixes indexes and names", ErrMalformedKey, joinPath(path, keyT))
 						}
-					}
-				} else if intValue, err := strconv.Atoi(value); err == nil {
-					if len([]rune(value)) > 0 && string(value[0]) == "0" {
 						continue
 					}
-					t[keyT] = intValue
+					options.log(slog.LevelDebug, "inrequest: dropped named keys mixed with indexes", "key", joinPath(path, keyT))
+				}
+				sort.Ints(keys)
+				if last := keys[len(keys)-1]; last > options.maxArrayIndex() {
+					if firstErr == nil && !options.qsCompat() {
+						firstErr = fmt.Errorf("%w: %s", ErrArrayIndexTooLarge, joinPath(joinPath(path, keyT), strconv.Itoa(last)))
+					}
+					continue
 				}
+				t[keyT] = options.sliceOf(vMap, keys)
+			}
+		} else if value, ok := v.(string); ok {
+			converted, err := options.convertValue(joinPath(path, keyT), value)
+			if err != nil && firstErr == nil {
+				firstErr = err
 			}
+			t[keyT] = converted
+		}
+	}
+	return firstErr
+}
+
+// sliceOf lists the values of the sorted numeric keys, compacted unless sparse arrays are enabled.
+// Sparse arrays skip negative indexes, e.g. items[-5].
+func (o *Options) sliceOf(values RequestValue, keys []int) []interface{} {
+	if o == nil || !o.SparseArrays {
+		arrMap := make([]interface{}, 0, len(keys))
+		for _, key := range keys {
+			arrMap = append(arrMap, values[strconv.Itoa(key)])
+		}
+		return arrMap
+	}
+	arrMap := make([]interface{}, max(keys[len(keys)-1]+1, 0))
+	for _, key := range keys {
+		if key >= 0 {
+			arrMap[key] = values[strconv.Itoa(key)]
+		}
+	}
+	return arrMap
+}
+
+/*
+Converting a raw string into the type it represents
+e.g. "12" becomes 12, "12.5" becomes 12.5, while "012" and "0" stay strings
+*/
+func actualTypeOf(value string) interface{} {
+	if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+		if strings.Contains(value, ".") {
+			return floatValue
+		}
+		if intValue, err := strconv.Atoi(value); err == nil {
+			if len(value) > 0 && value[0] == '0' {
+				return value
+			}
+			return intValue
+		}
+	}
+	return value
+}
+
+/*
+Converting a raw value at the dot path according to options, which may be nil
+e.g. with WithFieldType("items.zip", String) the value of items[0][zip] stays a string.
+The error reports a number rejected by WithNumberPolicy(RejectNumber), the value then stays a string
+*/
+func (o *Options) convertValue(path string, value string) (interface{}, error) {
+	if o == nil {
+		return actualTypeOf(value), nil
+	}
+	if o.NullTokens != nil && containsString(o.NullTokens, value) {
+		return nil, nil
+	}
+	if fieldType, ok := o.FieldTypes[withoutIndexes(path)]; ok {
+		if b, ok := o.boolToken(value); ok && (fieldType == Bool || fieldType == Auto) {
+			return b, nil
+		}
+		return fieldType.convert(value), nil
+	}
+	if o.JsonValues {
+		if decoded, ok := jsonDocument(value); ok {
+			return decoded, nil
+		}
+	}
+	if o.NoTypeConversion || o.QsCompat {
+		return value, nil
+	}
+	if b, ok := o.boolToken(value); ok {
+		return b, nil
+	}
+	if o.Numbers != NumberAsString && unrepresentableNumber(value) {
+		if o.Numbers == RejectNumber {
+			return value, fmt.Errorf("%w: %s", ErrUnrepresentableNumber, path)
+		}
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f, nil
+		}
+	}
+	return actualTypeOf(value), nil
+}
+
+// jsonDocument decodes a value holding a json object or array, e.g. `{"status":"active"}`, other values are not documents.
+func jsonDocument(value string) (interface{}, bool) {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" || trimmed[0] != '{' && trimmed[0] != '[' {
+		return nil, false
+	}
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(trimmed), &decoded); err != nil {
+		return nil, false
+	}
+	return decoded, true
+}
+
+/*
+Reporting whether value is an integer past the int range or an exponent without fraction, which actualTypeOf keeps as strings
+e.g. "99999999999999999999", "1e10" and "-2E-3" are, while "2.5e3", "007" and "1e" are not
+*/
+func unrepresentableNumber(value string) bool {
+	digits := trimSign(value)
+	if digits == "" || digits[0] == '0' {
+		return false
+	}
+	if i := strings.IndexAny(digits, "eE"); i >= 0 {
+		return isDigits(digits[:i]) && isDigits(trimSign(digits[i+1:]))
+	}
+	if !isDigits(digits) {
+		return false
+	}
+	_, err := strconv.Atoi(value)
+	return err != nil
+}
+
+// trimSign drops a single leading "+" or "-".
+func trimSign(s string) string {
+	if s != "" && (s[0] == '+' || s[0] == '-') {
+		return s[1:]
+	}
+	return s
+}
+
+// isDigits reports whether s is a non empty run of ascii digits.
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
 		}
 	}
+	return s != ""
 }
 
+// boolToken reports the boolean a value stands for under WithBoolTokens, tokens match case-insensitively.
+func (o *Options) boolToken(value string) (bool, bool) {
+	value = strings.TrimSpace(value)
+	for _, token := range o.TrueTokens {
+		if strings.EqualFold(value, token) {
+			return true, true
+		}
+	}
+	for _, token := range o.FalseTokens {
+		if strings.EqualFold(value, token) {
+			return false, true
+		}
+	}
+	return false, false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutIndexes drops the slice indexes of a dot path, e.g. "items.0.zip" and "items..zip" become "items.zip".
+func withoutIndexes(path string) string {
+	paths := strings.Split(path, ".")
+	kept := paths[:0]
+	for _, segment := range paths {
+		if _, err := strconv.Atoi(segment); err != nil && segment != "" {
+			kept = append(kept, segment)
+		}
+	}
+	return strings.Join(kept, ".")
+}
+
+// fieldPath is the dot path options are keyed by, e.g. "items[0][zip]" and "items[][zip]" become "items.zip".
+func fieldPath(key string) string {
+	return withoutIndexes(replaceBracketKeyIntoDotKey(key))
+}
+
+// bracketReplacer turns bracket keys into dot keys, see replaceBracketKeyIntoDotKey.
+var bracketReplacer = strings.NewReplacer("]", "", "[", ".")
+
 func replaceBracketKeyIntoDotKey(key string) string {
-	replacer := strings.NewReplacer("]", "", "[", ".")
-	return strings.Trim(replacer.Replace(key), ".")
+	return strings.Trim(bracketReplacer.Replace(key), ".")
+}
+
+/*
+Describing why a key is malformed for WithStrictKeys, or "" when it is well formed
+e.g. "a[b" has unbalanced brackets, "a[0][]" an empty segment and "a[b]c" text after a closing bracket.
+Empty dot segments such as "a..b" count when dots separate segments
+*/
+func malformedKey(key string, dots bool) string {
+	open, closed := false, false
+	start := 0
+	for i := 0; i < len(key); i++ {
+		switch c := key[i]; {
+		case open && c == ']':
+			if i == start {
+				return "empty segment"
+			}
+			open, closed = false, true
+		case open && c == '[', !open && c == ']':
+			return "unbalanced brackets"
+		case open:
+		case c == '[':
+			if i == start && !closed {
+				return "empty segment"
+			}
+			open, closed, start = true, false, i+1
+		case closed && !(dots && c == '.'):
+			return "text after closing bracket"
+		case dots && c == '.':
+			if i == start && !closed {
+				return "empty segment"
+			}
+			closed, start = false, i+1
+		}
+	}
+	switch {
+	case open:
+		return "unbalanced brackets"
+	case !closed && start == len(key):
+		return "empty segment"
+	}
+	return ""
+}
+
+/*
+Splitting a bracket or dot key into its path segments in a single pass, appending them to buf
+e.g. "data[users][0][profile]" gives ["data", "users", "0", "profile"] as substrings of the key,
+so no string is allocated. The segments are those of replaceBracketKeyIntoDotKey split on dots,
+keys where "]" does not end a segment, such as "a]b", take that slower path
+*/
+func pathSegments(key string, buf []string) []string {
+	return splitKey(key, buf, true)
+}
+
+// bracketSegments splits a key on its brackets only, dots stay part of the segments as in node-qs and Rails,
+// e.g. "user.name[first]" gives ["user.name", "first"].
+func bracketSegments(key string, buf []string) []string {
+	return splitKey(key, buf, false)
+}
+
+func splitKey(key string, buf []string, dots bool) []string {
+	segments := buf
+	start := 0
+	for i := 0; i < len(key); i++ {
+		switch {
+		case key[i] == '[' || dots && key[i] == '.':
+			segments = append(segments, key[start:i])
+			start = i + 1
+		case key[i] == ']':
+			if i+1 < len(key) && key[i+1] != '[' && !(dots && key[i+1] == '.') {
+				return append(buf, slowSegments(key, dots)...)
+			}
+			segments = append(segments, key[start:i])
+			start = i + 2
+			i++
+		}
+	}
+	if start <= len(key) {
+		segments = append(segments, key[start:])
+	}
+	found := segments[len(buf):]
+	for len(found) > 1 && found[0] == "" {
+		found = found[1:]
+	}
+	for len(found) > 1 && found[len(found)-1] == "" {
+		found = found[:len(found)-1]
+	}
+	return append(segments[:len(buf)], found...)
+}
+
+// keyReplacer separates the segments of a bracket key with NUL bytes, leaving dots alone.
+var keyReplacer = strings.NewReplacer("]", "", "[", "\x00")
+
+// slowSegments splits keys whose brackets do not end a segment by dropping every "]".
+func slowSegments(key string, dots bool) []string {
+	if dots {
+		return strings.Split(replaceBracketKeyIntoDotKey(key), ".")
+	}
+	return strings.Split(strings.Trim(keyReplacer.Replace(key), "\x00"), "\x00")
+}
+
+/*
+Keeping at most maxDepth levels below the root key, the remaining segments stay together as one key
+e.g. ["a", "b", "c", "d"] with max depth 2 becomes ["a", "b", "c", "[d]"]
+*/
+func limitSegments(segments []string, maxDepth int) []string {
+	if maxDepth <= 0 || len(segments) <= maxDepth+1 {
+		return segments
+	}
+	rest := "[" + strings.Join(segments[maxDepth+1:], "][") + "]"
+	return append(segments[:maxDepth+1], rest)
+}
+
+/*
+Storing value at the path segments, nested values win over a plain value at the same key whatever order they come in
+e.g. "a=1&a[b]=2" gives {"a": {"b": 2}}. It reports false when a plain value is dropped for a nested one
+*/
+func setSegments(target RequestValue, segments []string, value interface{}) bool {
+	kept := true
+	t := target
+	for ; len(segments) > 1; segments = segments[1:] {
+		child, ok := t[segments[0]].(RequestValue)
+		if !ok {
+			if _, exists := t[segments[0]]; exists {
+				kept = false
+			}
+			child = make(RequestValue)
+			t[segments[0]] = child
+		}
+		t = child
+	}
+	if _, ok := t[segments[0]].(RequestValue); ok {
+		return false
+	}
+	t[segments[0]] = value
+	return kept
+}
+
+/*
+Finding the value at a bracket or dot path
+e.g. "attachments[1][file]" and "attachments.1.file" both find the second attachment file
+*/
+func valueAt(values RequestValue, path string) (interface{}, bool) {
+	var value interface{} = values
+	var buf [8]string
+	for _, key := range pathSegments(path, buf[:0]) {
+		switch v := value.(type) {
+		case RequestValue:
+			item, ok := v[key]
+			if !ok {
+				return nil, false
+			}
+			value = item
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, false
+			}
+			value = v[index]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// filesAt lists the files uploaded at path, a single file or a slice of files.
+func filesAt(values RequestValue, path string) []*multipart.FileHeader {
+	value, _ := valueAt(values, path)
+	switch v := value.(type) {
+	case *multipart.FileHeader:
+		return []*multipart.FileHeader{v}
+	case []interface{}:
+		var files []*multipart.FileHeader
+		for _, item := range v {
+			if file, ok := item.(*multipart.FileHeader); ok {
+				files = append(files, file)
+			}
+		}
+		return files
+	}
+	return nil
+}
+
+/*
+Copying the map while leaving out every uploaded file
+e.g. attachments[0][file] is removed, attachments[0][title] is kept
+*/
+func withoutFiles(target RequestValue) RequestValue {
+	result := make(RequestValue, len(target))
+	for key, v := range target {
+		if value, ok := valueWithoutFiles(v); ok {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+func valueWithoutFiles(v interface{}) (interface{}, bool) {
+	switch value := v.(type) {
+	case *multipart.FileHeader:
+		return nil, false
+	case RequestValue:
+		return withoutFiles(value), true
+	case []interface{}:
+		var values []interface{}
+		for _, item := range value {
+			if itemValue, ok := valueWithoutFiles(item); ok {
+				values = append(values, itemValue)
+			}
+		}
+		if values == nil {
+			return nil, false
+		}
+		return values, true
+	}
+	return v, true
 }
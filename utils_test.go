@@ -2,34 +2,53 @@ package inrequest
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 )
 
-// TestTransformDotPathToMap calls inrequest.transformDotPathToMap
-func TestTransformDotPathToMap(t *testing.T) {
-	source := RequestValue{
-		"index.0":    0,
-		"index.1":    1,
-		"my.name.is": "elon",
-	}
-	target := RequestValue{
-		"index": []interface{}{0, 1},
-		"my": RequestValue{
-			"name": RequestValue{
-				"is": "elon",
+func TestSetSegments(t *testing.T) {
+	t.Run("should nest values at their path segments", func(t *testing.T) {
+		source := map[string]interface{}{
+			"index.0":    0,
+			"index.1":    1,
+			"my.name.is": "elon",
+		}
+		target := RequestValue{
+			"index": []interface{}{0, 1},
+			"my": RequestValue{
+				"name": RequestValue{
+					"is": "elon",
+				},
 			},
-		},
-	}
-
-	for key, value := range source {
-		transformDotPathToMap(&source, key, value)
-	}
+		}
 
-	fixValueToActualType(&source)
+		result := make(RequestValue)
+		for key, value := range source {
+			if !setSegments(result, strings.Split(key, "."), value) {
+				t.Fatalf("expected %s to be stored", key)
+			}
+		}
+		fixValueToActualType(&result, nil)
 
-	if !reflect.DeepEqual(source, target) {
-		t.Fatalf("Failed to tranform dot path to map interface %v, %v", source, target)
-	}
+		if !reflect.DeepEqual(result, target) {
+			t.Fatalf("Failed to nest path segments, expected %v, got %v", target, result)
+		}
+	})
+	t.Run("should keep the nested value over a plain value in either order", func(t *testing.T) {
+		target := RequestValue{"name": RequestValue{"first": "John"}}
+		plainFirst := RequestValue{"name": "John"}
+		if setSegments(plainFirst, []string{"name", "first"}, "John") {
+			t.Fatal("expected the plain value to be reported dropped")
+		}
+		nestedFirst := make(RequestValue)
+		setSegments(nestedFirst, []string{"name", "first"}, "John")
+		if setSegments(nestedFirst, []string{"name"}, "John") {
+			t.Fatal("expected the plain value to be reported dropped")
+		}
+		if !reflect.DeepEqual(plainFirst, target) || !reflect.DeepEqual(nestedFirst, target) {
+			t.Fatalf("expected %v in both orders, got %v and %v", target, plainFirst, nestedFirst)
+		}
+	})
 }
 
 // TestReplaceBracketKeyIntoDotKey calls inrequest.replaceBracketKeyIntoDotKey
@@ -47,3 +66,68 @@ func TestReplaceBracketKeyIntoDotKey(t *testing.T) {
 		}
 	}
 }
+
+func TestPathSegments(t *testing.T) {
+	t.Run("should split keys like replaceBracketKeyIntoDotKey", func(t *testing.T) {
+		keys := []string{
+			"", "name", "path[to][this]", "path[0][to]", "[0][to]", "a[]", "a[][b]", "a.b[c]", "a]b", "a[b]c",
+			"[]", "..a..", "a[b].c", "data[users][0][profile][settings]",
+		}
+		for _, key := range keys {
+			expected := strings.Split(replaceBracketKeyIntoDotKey(key), ".")
+			if segments := pathSegments(key, nil); !reflect.DeepEqual(segments, expected) {
+				t.Fatalf("key %q: expected %q, got %q", key, expected, segments)
+			}
+		}
+	})
+	t.Run("should not allocate for bracket keys", func(t *testing.T) {
+		var buf [8]string
+		allocs := testing.AllocsPerRun(100, func() {
+			pathSegments("data[users][0][profile][settings]", buf[:0])
+		})
+		if allocs != 0 {
+			t.Fatalf("expected no allocation, got %v", allocs)
+		}
+	})
+}
+
+func TestMalformedKey(t *testing.T) {
+	t.Run("should describe malformed keys", func(t *testing.T) {
+		keys := map[string]string{
+			"name":    "",
+			"a[b][0]": "",
+			"a[b].c":  "",
+			"a.b":     "",
+			"a[b":     "unbalanced brackets",
+			"a]b":     "unbalanced brackets",
+			"a[b[c]]": "unbalanced brackets",
+			"a[0][]":  "empty segment",
+			"[a]":     "empty segment",
+			"a..b":    "empty segment",
+			"a[b]c":   "text after closing bracket",
+		}
+		for key, expected := range keys {
+			if reason := malformedKey(key, true); reason != expected {
+				t.Fatalf("key %q: expected %q, got %q", key, expected, reason)
+			}
+		}
+	})
+	t.Run("should keep dots literal without dot segments", func(t *testing.T) {
+		if reason := malformedKey("a..b[c]", false); reason != "" {
+			t.Fatalf("expected a literal dot key to be well formed, got %q", reason)
+		}
+	})
+}
+
+func BenchmarkMapValues(b *testing.B) {
+	properties := []GroupRequestProperty{
+		{Path: "data[users][0][profile][settings][theme]", Value: "dark"},
+		{Path: "data[users][0][profile][name]", Value: "John"},
+		{Path: "data[users][1][profile][name]", Value: "Jane"},
+		{Path: "data[meta][page]", Value: "2"},
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		mapValuesOf(properties, nil)
+	}
+}
@@ -0,0 +1,129 @@
+package inrequest
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// StructValidator validates a bound model, e.g. *validator.Validate from github.com/go-playground/validator.
+type StructValidator interface {
+	Struct(model interface{}) error
+}
+
+var (
+	validatorMu     sync.RWMutex
+	structValidator StructValidator
+)
+
+// SetValidator makes ToBindValidated run v on every model after binding,
+// e.g. SetValidator(validator.New()).
+func SetValidator(v StructValidator) {
+	validatorMu.Lock()
+	defer validatorMu.Unlock()
+	structValidator = v
+}
+
+func currentValidator() StructValidator {
+	validatorMu.RLock()
+	defer validatorMu.RUnlock()
+	return structValidator
+}
+
+// Validatable is implemented by models checking rules across their fields, e.g. a password and its confirmation.
+// ToBind calls Validate once binding succeeded.
+type Validatable interface {
+	Validate() error
+}
+
+/*
+Running the Validate method of model, if it has one
+e.g. a Signup whose Validate returns errors.New("passwords do not match") gives
+ValidationErrors{{Tag: "validate", Err: ...}}. Bind and validation errors are returned as they are,
+so Validate can name the fields it rejects
+*/
+func validateModel(model interface{}) error {
+	v, ok := model.(Validatable)
+	if !ok {
+		return nil
+	}
+	err := v.Validate()
+	var invalid ValidationErrors
+	if err == nil || IsBindError(err) || errors.As(err, &invalid) {
+		return err
+	}
+	if fields, ok := validationFieldErrors(err); ok {
+		return fields
+	}
+	return ValidationErrors{{Tag: "validate", Err: err}}
+}
+
+// validatorFieldError matches the field errors of go-playground/validator without importing it.
+type validatorFieldError interface {
+	error
+	Namespace() string
+	Tag() string
+	Param() string
+}
+
+/*
+Binding the request into model, then validating it with the validator set by SetValidator
+e.g. a wrong "age" value and a missing required "name" give
+ValidationErrors{{Field: "age", Err: ...}, {Field: "Name", Tag: "required", Err: ...}}
+*/
+func bindValidated(request Request, model interface{}) error {
+	var errs ValidationErrors
+	if err := request.ToBind(model); err != nil {
+		var bindErr *BindError
+		var bindErrs BindErrors
+		var invalid ValidationErrors
+		switch {
+		case errors.As(err, &bindErrs):
+			errs = append(errs, bindErrs...)
+		case errors.As(err, &invalid):
+			errs = append(errs, invalid...)
+		case errors.As(err, &bindErr) && bindErr.Field != "":
+			errs = append(errs, FieldError{Field: bindErr.Field, Err: bindErr.Err})
+		default:
+			return err
+		}
+	}
+	if v := currentValidator(); v != nil {
+		if err := v.Struct(model); err != nil {
+			fields, ok := validationFieldErrors(err)
+			if !ok {
+				return err
+			}
+			errs = append(errs, fields...)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// validationFieldErrors converts a slice of validator field errors, such as validator.ValidationErrors.
+func validationFieldErrors(err error) (ValidationErrors, bool) {
+	if errs, ok := err.(ValidationErrors); ok {
+		return errs, true
+	}
+	value := reflect.ValueOf(err)
+	if value.Kind() != reflect.Slice {
+		return nil, false
+	}
+	errs := make(ValidationErrors, 0, value.Len())
+	for i := 0; i < value.Len(); i++ {
+		fieldErr, ok := value.Index(i).Interface().(validatorFieldError)
+		if !ok {
+			return nil, false
+		}
+		field := fieldErr.Namespace()
+		if i := strings.Index(field, "."); i >= 0 {
+			field = field[i+1:]
+		}
+		errs = append(errs, FieldError{Field: field, Tag: fieldErr.Tag(), Param: fieldErr.Param(), Err: fieldErr})
+	}
+	return errs, true
+}
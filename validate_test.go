@@ -0,0 +1,157 @@
+package inrequest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// testFieldError mimics validator.FieldError.
+type testFieldError struct {
+	namespace string
+	tag       string
+}
+
+func (e testFieldError) Error() string     { return e.namespace + " failed on " + e.tag }
+func (e testFieldError) Namespace() string { return e.namespace }
+func (e testFieldError) Tag() string       { return e.tag }
+func (e testFieldError) Param() string     { return "" }
+
+// testValidationErrors mimics validator.ValidationErrors.
+type testValidationErrors []validatorFieldError
+
+func (e testValidationErrors) Error() string { return "validation failed" }
+
+type testRequiredValidator struct{}
+
+func (testRequiredValidator) Struct(model interface{}) error {
+	value := reflect.ValueOf(model).Elem()
+	var errs testValidationErrors
+	for i := 0; i < value.NumField(); i++ {
+		if value.Field(i).IsZero() && value.Type().Field(i).Tag.Get("validate") == "required" {
+			errs = append(errs, testFieldError{namespace: value.Type().Name() + "." + value.Type().Field(i).Name, tag: "required"})
+		}
+	}
+	if errs == nil {
+		return nil
+	}
+	return errs
+}
+
+type testSignup struct {
+	Name  string `json:"name" validate:"required"`
+	Email string `json:"email" validate:"required"`
+	Age   int    `json:"age"`
+}
+
+func TestToBindValidated(t *testing.T) {
+	SetValidator(testRequiredValidator{})
+	defer SetValidator(nil)
+
+	t.Run("should pass valid models", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?name=John&email=john@example.com&age=31", nil)
+		signup := testSignup{}
+		if err := Query(req).ToBindValidated(&signup); err != nil {
+			t.Fatal(err)
+		}
+	})
+	t.Run("should combine bind and validation errors per field", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?name=John&age=old", nil)
+		err := Query(req).ToBindValidated(&testSignup{})
+		var errs ValidationErrors
+		if !errors.As(err, &errs) {
+			t.Fatalf("expected validation errors, got %v", err)
+		}
+		fields := errs.Fields()
+		if len(fields) != 2 || fields[0].Field != "age" || fields[0].Tag != "" || fields[1].Field != "Email" || fields[1].Tag != "required" {
+			t.Fatalf("unexpected field errors %+v", fields)
+		}
+	})
+}
+
+type testPasswordChange struct {
+	Password     string `json:"password"`
+	Confirmation string `json:"password_confirmation"`
+}
+
+func (p testPasswordChange) Validate() error {
+	if p.Password != p.Confirmation {
+		return ValidationErrors{{Field: "password_confirmation", Tag: "eqfield", Param: "password", Err: errors.New("does not match")}}
+	}
+	return nil
+}
+
+type testPeriod struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+func (p *testPeriod) Validate() error {
+	if p.Start >= p.End {
+		return errors.New("start must be before end")
+	}
+	return nil
+}
+
+// testSourcedPeriod is testPeriod read from headers and cookies.
+type testSourcedPeriod struct {
+	Start int `in:"header" header:"X-Start" cookie:"start"`
+	End   int `in:"cookie" header:"X-End" cookie:"end"`
+}
+
+func (p *testSourcedPeriod) Validate() error {
+	return (&testPeriod{Start: p.Start, End: p.End}).Validate()
+}
+
+func TestValidatable(t *testing.T) {
+	t.Run("should call Validate after binding", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?password=secret&password_confirmation=secrte", nil)
+		err := Query(req).ToBind(&testPasswordChange{})
+		var errs ValidationErrors
+		if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Field != "password_confirmation" || errs[0].Tag != "eqfield" {
+			t.Fatalf("expected the password confirmation to be rejected, got %v", err)
+		}
+		req = httptest.NewRequest(http.MethodGet, "/?password=secret&password_confirmation=secret", nil)
+		if err = Query(req).ToBind(&testPasswordChange{}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	t.Run("should turn plain errors into validation errors", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?start=5&end=2", nil)
+		err := Query(req).ToBind(&testPeriod{})
+		var errs ValidationErrors
+		if !errors.As(err, &errs) || errs[0].Tag != "validate" || errs[0].Err.Error() != "start must be before end" {
+			t.Fatalf("expected a validation error, got %v", err)
+		}
+		if status := errorStatus(err); status != http.StatusUnprocessableEntity {
+			t.Fatalf("expected 422, got %d", status)
+		}
+	})
+	t.Run("should call Validate after binding headers, cookies and whole requests", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Start", "5")
+		req.Header.Set("X-End", "2")
+		req.AddCookie(&http.Cookie{Name: "start", Value: "5"})
+		req.AddCookie(&http.Cookie{Name: "end", Value: "2"})
+		binds := map[string]func(model *testSourcedPeriod) error{
+			"header":  func(model *testSourcedPeriod) error { return Headers(req).ToBind(model) },
+			"cookie":  func(model *testSourcedPeriod) error { return Cookies(req).ToBind(model) },
+			"request": func(model *testSourcedPeriod) error { return BindRequest(req, model) },
+		}
+		for name, bind := range binds {
+			var errs ValidationErrors
+			if err := bind(&testSourcedPeriod{}); !errors.As(err, &errs) {
+				t.Fatalf("expected %s binding to be validated, got %v", name, err)
+			}
+		}
+	})
+	t.Run("should not call Validate when binding fails", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?start=soon&end=2", nil)
+		err := Query(req).ToBind(&testPeriod{})
+		if !IsBindError(err) {
+			t.Fatalf("expected the bind error alone, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,184 @@
+package inrequest
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidSignature is reported when a webhook signature is missing or does not match the body.
+	ErrInvalidSignature = errors.New("invalid signature")
+	// ErrSignatureExpired is reported when the timestamp of a signed webhook is outside the tolerance.
+	ErrSignatureExpired = errors.New("signature timestamp outside tolerance")
+)
+
+// defaultTolerance is the age a signed webhook timestamp may have when no tolerance is given.
+const defaultTolerance = 5 * time.Minute
+
+// rawBody is a request body read by RawBody, it can be read again by every later RawBody call.
+type rawBody struct {
+	*bytes.Reader
+	data []byte
+}
+
+func (b *rawBody) Close() error {
+	return nil
+}
+
+/*
+Reading the whole request body while keeping it readable for the parsers
+e.g. a signature check followed by Json both see the body:
+
+	body, err := inrequest.RawBody(r)
+	// verify body
+	req, err := inrequest.Json(r)
+
+The body is read once, later calls return the same bytes. It is bounded by WithMaxBodySize, 32MB unless set,
+a larger body fails with a *ParseError wrapping a *RequestTooLargeError
+*/
+func RawBody(r *http.Request, opts ...Option) ([]byte, error) {
+	if body, ok := r.Body.(*rawBody); ok {
+		body.Reset(body.data)
+		return body.data, nil
+	}
+	var data []byte
+	if r.Body != nil && r.Body != http.NoBody {
+		limit := newOptions(opts).MaxBodySize
+		if limit <= 0 {
+			limit = defaultMaxMemory
+		}
+		if r.ContentLength > limit {
+			return nil, &ParseError{Err: &RequestTooLargeError{Limit: "body", Max: limit}}
+		}
+		var err error
+		data, err = io.ReadAll(&maxBodyReader{ReadCloser: r.Body, remaining: limit, max: limit})
+		r.Body.Close()
+		if err != nil {
+			_, err = parseResultOf(nil, err)
+			return nil, err
+		}
+	}
+	r.Body = &rawBody{Reader: bytes.NewReader(data), data: data}
+	r.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	return data, nil
+}
+
+/*
+Checking the hex encoded HMAC of the raw body sent in header
+e.g. VerifyHMAC(r, "X-Signature", secret, sha256.New) for "X-Signature: 5257a8..." or "X-Signature: sha256=5257a8...".
+The body stays readable for the parsers and is bounded by the options of RawBody
+*/
+func VerifyHMAC(r *http.Request, header string, secret []byte, hashFn func() hash.Hash, opts ...Option) error {
+	body, err := RawBody(r, opts...)
+	if err != nil {
+		return err
+	}
+	signature := r.Header.Get(header)
+	if i := strings.IndexByte(signature, '='); i >= 0 {
+		signature = signature[i+1:]
+	}
+	if !validSignature(hashFn, secret, body, signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// VerifyGitHub checks the X-Hub-Signature-256 header of a GitHub webhook.
+func VerifyGitHub(r *http.Request, secret []byte, opts ...Option) error {
+	return VerifyHMAC(r, "X-Hub-Signature-256", secret, sha256.New, opts...)
+}
+
+/*
+Checking the Stripe-Signature header of a Stripe webhook
+e.g. "t=1492774577,v1=5257a8..." signs "1492774577." followed by the body. Any v1 signature may match,
+the timestamp has to be within tolerance of now, five minutes when zero. A negative tolerance skips the timestamp check
+*/
+func VerifyStripe(r *http.Request, secret []byte, tolerance time.Duration, opts ...Option) error {
+	body, err := RawBody(r, opts...)
+	if err != nil {
+		return err
+	}
+	var timestamp string
+	var signatures []string
+	for _, item := range strings.Split(r.Header.Get("Stripe-Signature"), ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(item), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	if err = checkTimestamp(timestamp, tolerance); err != nil {
+		return err
+	}
+	payload := append([]byte(timestamp+"."), body...)
+	for _, signature := range signatures {
+		if validSignature(sha256.New, secret, payload, signature) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+/*
+Checking the X-Slack-Signature header of a Slack request
+e.g. "v0=a2114d..." signs "v0:" followed by the X-Slack-Request-Timestamp header, ":" and the body.
+The timestamp has to be within tolerance of now, five minutes when zero. A negative tolerance skips the timestamp check
+*/
+func VerifySlack(r *http.Request, secret []byte, tolerance time.Duration, opts ...Option) error {
+	body, err := RawBody(r, opts...)
+	if err != nil {
+		return err
+	}
+	timestamp := r.Header.Get("X-Slack-Request-Timestamp")
+	if err = checkTimestamp(timestamp, tolerance); err != nil {
+		return err
+	}
+	signature := strings.TrimPrefix(r.Header.Get("X-Slack-Signature"), "v0=")
+	payload := append([]byte("v0:"+timestamp+":"), body...)
+	if !validSignature(sha256.New, secret, payload, signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// checkTimestamp checks a unix timestamp against tolerance, a missing timestamp fails as an invalid signature.
+func checkTimestamp(timestamp string, tolerance time.Duration) error {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	switch {
+	case tolerance < 0:
+		return nil
+	case tolerance == 0:
+		tolerance = defaultTolerance
+	}
+	if age := time.Since(time.Unix(seconds, 0)); age > tolerance || age < -tolerance {
+		return ErrSignatureExpired
+	}
+	return nil
+}
+
+// validSignature compares the hex encoded signature with the HMAC of payload in constant time.
+func validSignature(hashFn func() hash.Hash, secret []byte, payload []byte, signature string) bool {
+	expected, err := hex.DecodeString(strings.TrimSpace(signature))
+	if err != nil || len(expected) == 0 {
+		return false
+	}
+	mac := hmac.New(hashFn, secret)
+	mac.Write(payload)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
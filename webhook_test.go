@@ -0,0 +1,110 @@
+package inrequest
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sign(secret string, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func newWebhookRequest(body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestVerifyHMAC(t *testing.T) {
+	body := `{"action":"opened"}`
+
+	t.Run("should accept a matching signature and keep the body readable", func(t *testing.T) {
+		req := newWebhookRequest(body)
+		req.Header.Set("X-Hub-Signature-256", "sha256="+sign("secret", body))
+		if err := VerifyGitHub(req, []byte("secret")); err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := Json(req)
+		if err != nil || parsed.GetString("action") != "opened" {
+			t.Fatalf("expected the body parsed after verification, got %v (%v)", parsed.ToMap(), err)
+		}
+	})
+	t.Run("should reject a wrong or missing signature", func(t *testing.T) {
+		for _, signature := range []string{sign("other", body), "", "zz"} {
+			req := newWebhookRequest(body)
+			req.Header.Set("X-Signature", signature)
+			if err := VerifyHMAC(req, "X-Signature", []byte("secret"), sha256.New); !errors.Is(err, ErrInvalidSignature) {
+				t.Fatalf("expected ErrInvalidSignature for %q, got %v", signature, err)
+			}
+		}
+	})
+	t.Run("should verify stripe signatures with their timestamp", func(t *testing.T) {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req := newWebhookRequest(body)
+		req.Header.Set("Stripe-Signature", "t="+timestamp+",v1="+sign("other", timestamp+"."+body)+",v1="+sign("secret", timestamp+"."+body))
+		if err := VerifyStripe(req, []byte("secret"), 5*time.Minute); err != nil {
+			t.Fatal(err)
+		}
+
+		old := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+		req = newWebhookRequest(body)
+		req.Header.Set("Stripe-Signature", "t="+old+",v1="+sign("secret", old+"."+body))
+		if err := VerifyStripe(req, []byte("secret"), 5*time.Minute); !errors.Is(err, ErrSignatureExpired) {
+			t.Fatalf("expected ErrSignatureExpired, got %v", err)
+		}
+	})
+	t.Run("should check timestamps against five minutes unless the tolerance is negative", func(t *testing.T) {
+		old := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+		newRequest := func() *http.Request {
+			req := newWebhookRequest(body)
+			req.Header.Set("X-Slack-Request-Timestamp", old)
+			req.Header.Set("X-Slack-Signature", "v0="+sign("secret", "v0:"+old+":"+body))
+			return req
+		}
+		if err := VerifySlack(newRequest(), []byte("secret"), 0); !errors.Is(err, ErrSignatureExpired) {
+			t.Fatalf("expected ErrSignatureExpired, got %v", err)
+		}
+		if err := VerifySlack(newRequest(), []byte("secret"), -1); err != nil {
+			t.Fatal(err)
+		}
+	})
+	t.Run("should verify slack signatures", func(t *testing.T) {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req := newWebhookRequest(body)
+		req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+		req.Header.Set("X-Slack-Signature", "v0="+sign("secret", "v0:"+timestamp+":"+body))
+		if err := VerifySlack(req, []byte("secret"), 5*time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestRawBody(t *testing.T) {
+	t.Run("should return the same body on every call", func(t *testing.T) {
+		req := newWebhookRequest(`{"a":1}`)
+		first, _ := RawBody(req)
+		second, err := RawBody(req)
+		if err != nil || string(first) != `{"a":1}` || string(second) != string(first) {
+			t.Fatalf("expected the body twice, got %q and %q (%v)", first, second, err)
+		}
+	})
+	t.Run("should fail once the body exceeds the size limit", func(t *testing.T) {
+		req := newWebhookRequest(`{"action":"opened"}`)
+		req.ContentLength = -1
+		_, err := RawBody(req, WithMaxBodySize(8))
+		var tooLarge *RequestTooLargeError
+		if !IsParseError(err) || !errors.As(err, &tooLarge) || tooLarge.Max != 8 {
+			t.Fatalf("expected body too large error, got %v", err)
+		}
+	})
+}
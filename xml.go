@@ -0,0 +1,97 @@
+package inrequest
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const xmlTextKey = "#text"
+
+var xmlMediaTypes = []string{"application/xml", "text/xml"}
+
+/*
+Decoding the xml body into map, the root element is unwrapped
+e.g. <user id="7"><name>John</name><tag>a</tag><tag>b</tag></user>
+transform into :
+
+	["id"] : 7
+	["name"] : "John"
+	["tag"] : ["a", "b"]
+
+text of an element holding attributes or children is kept under "#text"
+*/
+func parseXml(r *http.Request) (RequestValue, error) {
+	decoder := xml.NewDecoder(r.Body)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return make(RequestValue), err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			value, err := xmlElementValue(decoder, start)
+			if err != nil {
+				return make(RequestValue), err
+			}
+			if result, ok := value.(RequestValue); ok {
+				return result, nil
+			}
+			return RequestValue{start.Name.Local: value}, nil
+		}
+	}
+}
+
+func xmlElementValue(decoder *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	result := make(RequestValue)
+	for _, attr := range start.Attr {
+		if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+			continue
+		}
+		result[attr.Name.Local] = actualTypeOf(attr.Value)
+	}
+
+	var text strings.Builder
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			child, err := xmlElementValue(decoder, t)
+			if err != nil {
+				return nil, err
+			}
+			appendXmlChild(result, t.Name.Local, child)
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			content := strings.TrimSpace(text.String())
+			if len(result) == 0 {
+				return actualTypeOf(content), nil
+			}
+			if content != "" {
+				result[xmlTextKey] = actualTypeOf(content)
+			}
+			return result, nil
+		}
+	}
+}
+
+// appendXmlChild stores a child element, turning repeated elements into a slice.
+func appendXmlChild(result RequestValue, name string, child interface{}) {
+	existing, ok := result[name]
+	if !ok {
+		result[name] = child
+		return
+	}
+	if values, ok := existing.([]interface{}); ok {
+		result[name] = append(values, child)
+		return
+	}
+	result[name] = []interface{}{existing, child}
+}
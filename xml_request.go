@@ -0,0 +1,37 @@
+package inrequest
+
+type XmlRequest struct {
+	requestValues
+	options *Options
+}
+
+func (r XmlRequest) ToMap() RequestValue {
+	r.invalidate()
+	return r.result
+}
+
+func (r XmlRequest) ToBind(model interface{}) error {
+	return bindHooked(r.options, r.result, model, binderOf(r.options).bind)
+}
+
+// ToBindValidated binds like ToBind, then runs the validator set with SetValidator.
+// Bind and validation failures are returned together as ValidationErrors.
+func (r XmlRequest) ToBindValidated(model interface{}) error {
+	return bindValidated(r, model)
+}
+
+func (r XmlRequest) ToJsonByte() ([]byte, error) {
+	jsonData, err := r.encode(r.result)
+	if err != nil {
+		return []byte{}, err
+	}
+	return append([]byte(nil), jsonData...), nil
+}
+
+func (r XmlRequest) ToJsonString() (string, error) {
+	jsonData, err := r.encode(r.result)
+	if err != nil {
+		return "", err
+	}
+	return string(jsonData), nil
+}
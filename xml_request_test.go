@@ -0,0 +1,69 @@
+package inrequest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestXml(t *testing.T) {
+	body := `<?xml version="1.0" encoding="UTF-8"?>
+<order id="42" xmlns="urn:orders">
+	<customer>John Doe</customer>
+	<item sku="A1"><price>10.5</price><qty>2</qty></item>
+	<item sku="B2"><price>3</price><qty>1</qty></item>
+	<note lang="en">Leave at the door</note>
+</order>`
+
+	t.Run("should parse elements and attributes into map", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/xml")
+		result, err := Xml(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		target := RequestValue{
+			"id":       42,
+			"customer": "John Doe",
+			"item": []interface{}{
+				RequestValue{"sku": "A1", "price": 10.5, "qty": 2},
+				RequestValue{"sku": "B2", "price": 3, "qty": 1},
+			},
+			"note": RequestValue{"lang": "en", "#text": "Leave at the door"},
+		}
+		if !reflect.DeepEqual(result.ToMap(), target) {
+			t.Fatalf("Failed parsing xml, expected %v, got %v", target, result.ToMap())
+		}
+	})
+	t.Run("should bind through Parse", func(t *testing.T) {
+		type Order struct {
+			ID       int    `json:"id"`
+			Customer string `json:"customer"`
+			Items    []struct {
+				Sku   string  `json:"sku"`
+				Price float64 `json:"price"`
+			} `json:"item"`
+		}
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set("Content-Type", "text/xml; charset=utf-8")
+		result, err := Parse(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		order := Order{}
+		if err = result.ToBind(&order); err != nil {
+			t.Fatal(err)
+		}
+		if order.ID != 42 || order.Customer != "John Doe" || len(order.Items) != 2 || order.Items[0].Price != 10.5 {
+			t.Fatalf("unexpected order %+v", order)
+		}
+	})
+	t.Run("should report malformed xml", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("<order><id>1</order>"))
+		if _, err := Xml(req); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}